@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,62 +12,156 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jairogloz/go-expense-tracker-back/config"
 	"github.com/jairogloz/go-expense-tracker-back/internal/app"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
 	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
 	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
 	"github.com/jairogloz/go-expense-tracker-back/internal/services"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal(slog.Default(), "failed to load configuration", err)
+	}
+
+	// Log JSON lines to stdout; request-scoped loggers derive from this one
+	level, err := logging.ParseLevel(cfg.Server.LogLevel)
+	if err != nil {
+		fatal(slog.Default(), "failed to load configuration", err)
+	}
+	logger := logging.New(os.Stdout, level)
+	slog.SetDefault(logger)
+
+	// Install the tracer provider before any instrumented client is created
+	shutdownTracing, err := infra.SetupTracing(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal(logger, "failed to set up tracing", err)
 	}
 
 	// Create context with timeout for db connection
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	// Initialize database connection
-	db, err := infra.NewDatabaseConnection(ctx, cfg)
+	// Initialize database connection and repositories
+	store, err := newStorage(ctx, cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal(logger, "failed to connect to database", err)
 	}
-	defer db.Close()
+	defer store.close()
 
-	// Initialize repository
-	transactionRepo := infra.NewPostgreSQLTransactionRepository(db)
+	// Run the migrate subcommand instead of the server if requested
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), store.migrator, os.Args[2:]); err != nil {
+			fatal(logger, "migration failed", err)
+		}
+		return
+	}
 
-	// Use background context for the rest of the operations
-	ctx = context.Background()
+	// Apply pending migrations
+	if cfg.Database.AutoMigrate {
+		applied, err := store.migrator.Up(context.Background())
+		if err != nil {
+			fatal(logger, "failed to migrate database", err)
+		}
+		logger.Info("applied migrations", "count", applied)
+	}
 
-	// Create tables if they don't exist
-	if err := transactionRepo.CreateTransactionsTable(ctx); err != nil {
-		log.Fatalf("Failed to create database tables: %v", err)
+	// Initialize metrics
+	metrics := infra.NewPrometheusMetrics()
+	if err := metrics.Register(store.dbStats); err != nil {
+		fatal(logger, "failed to register database metrics", err)
 	}
 
 	// Initialize services
-	aiService := infra.NewOpenAIService(cfg.OpenAI.APIKey)
-	transactionService := services.NewTransactionService(transactionRepo)
+	quotaService := services.NewAIQuotaService(store.aiQuota, cfg.OpenAI.MonthlyTokenQuota)
+	prices := make(map[string]domain.AIModelPrice, len(cfg.OpenAI.Prices))
+	for model, price := range cfg.OpenAI.Prices {
+		prices[model] = domain.AIModelPrice{Prompt: price.Prompt, Completion: price.Completion}
+	}
+	usageService := services.NewAIUsageService(store.aiUsage, quotaService, prices)
+	aiService := infra.NewOpenAIService(cfg.OpenAI.APIKey, services.AIUsageRecorders{quotaService, usageService, metrics})
+	ruleService := services.NewRuleService(store.rules, store.transactions)
+	transactionService := services.NewTransactionService(infra.NewTracingTransactionRepository(store.transactions), ruleService, services.DuplicateDetectionConfig{
+		DateWindow:          cfg.Duplicates.DateWindow,
+		SimilarityThreshold: cfg.Duplicates.SimilarityThreshold,
+	})
+
+	apiKeyService := services.NewAPIKeyService(store.apiKeys)
+	healthService := services.NewHealthService(cfg.Server.HealthCheckTimeout,
+		services.HealthCheck{Checker: store.health, Required: true},
+		services.HealthCheck{Checker: infra.NewMigrationHealthChecker(store.migrator), Required: true},
+		// /parse is the only endpoint needing OpenAI, so its outage degrades rather than fails readiness
+		services.HealthCheck{Checker: infra.NewCachedHealthChecker(infra.NewOpenAIHealthChecker(aiService), cfg.Server.AIHealthCacheTTL)},
+	)
+	adminService := services.NewAdminService(store.admin)
+	operationService := services.NewOperationService(infra.NewTracingTransactionRepository(store.transactions), cfg.Operations.UndoWindow)
 
 	// Initialize use cases
-	parseInputUseCase := app.NewParseInputUseCase(aiService, transactionService)
+	parseInputUseCase := app.NewParseInputUseCase(aiService, transactionService, quotaService, metrics)
+	createTransactionsUseCase := app.NewCreateTransactionsUseCase(transactionService, metrics)
+	bulkTransactionsUseCase := app.NewBulkTransactionsUseCase(transactionService, metrics)
 
 	// Initialize auth service
 	authService := infra.NewSupabaseAuthService(cfg)
+	keysCtx, stopKeyRefresh := context.WithCancel(context.Background())
+	defer stopKeyRefresh()
+	if err := authService.StartKeyRefresh(keysCtx); err != nil {
+		// Keys are fetched again on demand, so a transient failure is not fatal
+		logger.Warn("failed to fetch JWKS at startup", "error", err)
+	}
+
+	// Permanently delete transactions once their trash retention has passed
+	purgeCtx, stopTrashPurge := context.WithCancel(context.Background())
+	defer stopTrashPurge()
+	services.NewTrashPurger(transactionService, cfg.Trash.Retention, cfg.Trash.PurgeInterval).Start(purgeCtx)
 
 	// Initialize handlers
-	transactionHandler := handlers.NewTransactionHandler(parseInputUseCase, transactionService)
-	authMiddleware := handlers.NewAuthMiddleware(authService)
+	transactionHandler := handlers.NewTransactionHandler(parseInputUseCase, createTransactionsUseCase, bulkTransactionsUseCase, transactionService)
+	operationHandler := handlers.NewOperationHandler(operationService)
+	ruleHandler := handlers.NewRuleHandler(ruleService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	usageHandler := handlers.NewUsageHandler(usageService)
+	healthHandler := handlers.NewHealthHandler(healthService)
+	metricsHandler := handlers.NewMetricsHandler(metrics.Handler())
+	apiDoc := handlers.NewOpenAPIDocument(
+		healthHandler, metricsHandler, transactionHandler, operationHandler, ruleHandler, apiKeyHandler, adminHandler, usageHandler,
+	)
+	openAPIHandler := handlers.NewOpenAPIHandler(apiDoc)
+	authMiddleware := handlers.NewAuthMiddleware(authService, apiKeyService)
+	rateLimitMiddleware := handlers.NewRateLimitMiddleware(store.rateLimiter,
+		domain.RateLimit{PerMinute: cfg.RateLimit.PerMinute, Burst: cfg.RateLimit.Burst},
+		map[string]domain.RateLimit{
+			"/parse": {PerMinute: cfg.RateLimit.ParsePerMinute, Burst: cfg.RateLimit.ParseBurst},
+		},
+	)
+	idempotencyMiddleware := handlers.NewIdempotencyMiddleware(store.idempotency, cfg.Server.IdempotencyKeyTTL)
 
 	// Setup routes
-	r := gin.Default()
+	r := gin.New()
 
 	// Middleware
+	requestLogger := handlers.NewRequestLogger(logger)
+	r.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		// Don't trace scrapes and probes
+		switch r.URL.Path {
+		case "/metrics", "/health", "/livez", "/readyz":
+			return false
+		}
+		return true
+	})))
+	r.Use(requestLogger.Handle(), requestLogger.Recover())
+	r.Use(handlers.NewMetricsMiddleware(metrics).Handle())
+	errorMiddleware := handlers.NewErrorMiddleware()
+	r.Use(errorMiddleware.Handle())
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Idempotency-Key, If-Match, X-API-Key, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, Idempotent-Replayed, ETag, X-Request-ID, Operation-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -76,20 +170,32 @@ func main() {
 		c.Next()
 	})
 
-	// Health check endpoint (no auth required)
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "healthy",
-			"time":   time.Now().UTC(),
-		})
-	})
+	// Health check endpoints (no auth required)
+	healthHandler.SetupRoutes(r)
+
+	// Prometheus metrics endpoint (no auth required; restrict access at the network level)
+	metricsHandler.SetupRoutes(r)
+
+	// OpenAPI document and Swagger UI (no auth required)
+	openAPIHandler.SetupRoutes(r)
 
 	// Protected routes group
 	protected := r.Group("/")
 	protected.Use(authMiddleware.Authenticate())
+	protected.Use(rateLimitMiddleware.Handle())
+	protected.Use(idempotencyMiddleware.Handle())
+	// Render handler errors before the idempotency middleware stores the response
+	protected.Use(errorMiddleware.Handle())
+	// Reject requests that do not match the OpenAPI document before they reach handlers
+	protected.Use(handlers.NewValidationMiddleware(apiDoc).Handle())
 
 	// Setup routes with authentication
 	transactionHandler.SetupRoutes(protected)
+	operationHandler.SetupRoutes(protected)
+	ruleHandler.SetupRoutes(protected)
+	apiKeyHandler.SetupRoutes(protected)
+	adminHandler.SetupRoutes(protected)
+	usageHandler.SetupRoutes(protected)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -99,9 +205,9 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting server on port %s", cfg.Server.Port)
+		logger.Info("starting server", "port", cfg.Server.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal(logger, "failed to start server", err)
 		}
 	}()
 
@@ -109,15 +215,25 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	logger.Info("shutting down server")
 
 	// Give outstanding requests 30 seconds to complete
 	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal(logger, "server forced to shutdown", err)
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warn("failed to flush traces", "error", err)
 	}
 
-	log.Println("Server exiting")
+	logger.Info("server exited")
+}
+
+// fatal logs err and exits with a non-zero status
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
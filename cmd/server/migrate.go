@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+)
+
+// runMigrate handles the `migrate` subcommand:
+//
+//	migrate up            apply all pending migrations
+//	migrate down [steps]  revert the latest migrations (default 1)
+//	migrate status        print the current and latest schema versions
+func runMigrate(ctx context.Context, migrator infra.Migrator, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up | down [steps] | status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("applied migrations", "count", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("steps must be a positive integer")
+			}
+			steps = n
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		slog.Info("reverted migrations", "count", reverted)
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("schema version", "current", status.Current, "latest", status.Latest, "pending", len(status.Pending))
+		for _, migration := range status.Pending {
+			slog.Info("pending migration", "version", migration.Version, "name", migration.Name)
+		}
+
+	default:
+		return fmt.Errorf("unknown migrate command %q; usage: migrate up | down [steps] | status", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jairogloz/go-expense-tracker-back/config"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+	"github.com/jairogloz/go-expense-tracker-back/migrations"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// storage bundles the repositories and migrator of the configured database backend
+type storage struct {
+	transactions domain.TransactionRepository
+	rules        domain.RuleRepository
+	idempotency  domain.IdempotencyRepository
+	apiKeys      domain.APIKeyRepository
+	admin        domain.AdminRepository
+	aiQuota      domain.AIQuotaRepository
+	aiUsage      domain.AIUsageRepository
+	rateLimiter  domain.RateLimiter
+	migrator     infra.Migrator
+	// dbStats exports connection pool statistics to Prometheus
+	dbStats prometheus.Collector
+	// health checks that the database is reachable
+	health domain.HealthChecker
+	close  func()
+}
+
+// newStorage connects to the database selected by DB_DRIVER and initializes its repositories
+func newStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	switch cfg.Database.Driver {
+	case config.DriverSQLite:
+		db, err := infra.NewSQLiteConnection(ctx, cfg.Database.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+
+		migrator, err := infra.NewSQLiteMigrator(db, migrations.SQLiteFS)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to load migrations: %w", err)
+		}
+
+		return &storage{
+			transactions: infra.NewSQLiteTransactionRepository(db),
+			rules:        infra.NewSQLiteRuleRepository(db),
+			idempotency:  infra.NewSQLiteIdempotencyRepository(db),
+			apiKeys:      infra.NewSQLiteAPIKeyRepository(db),
+			admin:        infra.NewSQLiteAdminRepository(db),
+			aiQuota:      infra.NewSQLiteAIQuotaRepository(db),
+			aiUsage:      infra.NewSQLiteAIUsageRepository(db),
+			rateLimiter:  infra.NewMemoryRateLimiter(),
+			migrator:     migrator,
+			dbStats:      collectors.NewDBStatsCollector(db, "sqlite"),
+			health:       infra.NewSQLiteHealthChecker(db),
+			close:        func() { db.Close() },
+		}, nil
+
+	default:
+		db, err := infra.NewDatabaseConnection(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		migrator, err := infra.NewPostgreSQLMigrator(db, migrations.FS)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to load migrations: %w", err)
+		}
+
+		var rateLimiter domain.RateLimiter = infra.NewMemoryRateLimiter()
+		if cfg.RateLimit.Store == config.RateLimitStorePostgres {
+			rateLimiter = infra.NewPostgreSQLRateLimiter(db)
+		}
+
+		return &storage{
+			transactions: infra.NewPostgreSQLTransactionRepository(db),
+			rules:        infra.NewPostgreSQLRuleRepository(db),
+			idempotency:  infra.NewPostgreSQLIdempotencyRepository(db),
+			apiKeys:      infra.NewPostgreSQLAPIKeyRepository(db),
+			admin:        infra.NewPostgreSQLAdminRepository(db),
+			aiQuota:      infra.NewPostgreSQLAIQuotaRepository(db),
+			aiUsage:      infra.NewPostgreSQLAIUsageRepository(db),
+			rateLimiter:  rateLimiter,
+			migrator:     migrator,
+			dbStats:      infra.NewPgxPoolCollector(db),
+			health:       infra.NewPostgreSQLHealthChecker(db),
+			close:        db.Close,
+		}, nil
+	}
+}
@@ -1,44 +1,159 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration values
 type Config struct {
-	Database DatabaseConfig
-	OpenAI   OpenAIConfig
-	Supabase SupabaseConfig
-	Server   ServerConfig
+	Database   DatabaseConfig
+	OpenAI     OpenAIConfig
+	Supabase   SupabaseConfig
+	Server     ServerConfig
+	Duplicates DuplicatesConfig
+	RateLimit  RateLimitConfig
+	Tracing    TracingConfig
+	Trash      TrashConfig
+	Operations OperationsConfig
 }
 
+// Database drivers supported by DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
+	// Driver selects the storage backend: postgres (default) or sqlite
+	Driver string
+	// SQLitePath is the database file used by the sqlite driver
+	SQLitePath string
+
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Name     string
+	// AutoMigrate applies pending schema migrations when the server starts
+	AutoMigrate bool
 }
 
 // OpenAIConfig holds OpenAI configuration
 type OpenAIConfig struct {
 	APIKey string
+	// MonthlyTokenQuota is the number of OpenAI tokens each user may consume per calendar month; 0 disables the quota
+	MonthlyTokenQuota int
+	// Prices maps model names to their price, used to estimate the cost of AI usage
+	Prices map[string]ModelPrice
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// DefaultOpenAIPrices are the list prices used when OPENAI_PRICES is not set
+var DefaultOpenAIPrices = map[string]ModelPrice{
+	"gpt-3.5-turbo": {Prompt: 0.50, Completion: 1.50},
+	"gpt-4o":        {Prompt: 2.50, Completion: 10.00},
+	"gpt-4o-mini":   {Prompt: 0.15, Completion: 0.60},
+}
+
+// Rate limit stores supported by RATE_LIMIT_STORE
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
+
+// RateLimitConfig holds per-user rate limiting configuration
+type RateLimitConfig struct {
+	// Store selects where token buckets live: memory (per instance, default) or postgres (shared)
+	Store string
+	// ParsePerMinute and ParseBurst limit POST /parse, which calls OpenAI
+	ParsePerMinute int
+	ParseBurst     int
+	// PerMinute and Burst limit every other authenticated endpoint
+	PerMinute int
+	Burst     int
+}
+
+// Trace exporters supported by TRACING_EXPORTER
+const (
+	TracingExporterNone = "none"
+	TracingExporterOTLP = "otlp"
+)
+
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	// Exporter selects where spans are sent: none (default, spans are not recorded) or otlp
+	Exporter string
+	// OTLPEndpoint is the host:port of the OTLP/HTTP collector
+	OTLPEndpoint string
+	// OTLPInsecure sends spans over plain HTTP instead of HTTPS
+	OTLPInsecure bool
+	// ServiceName identifies this service in traces
+	ServiceName string
+	// SampleRatio is the fraction of new traces recorded, between 0 and 1
+	SampleRatio float64
 }
 
 // SupabaseConfig holds Supabase configuration
 type SupabaseConfig struct {
-	URL       string
-	AnonKey   string
+	URL     string
+	AnonKey string
+	// JWTSecret verifies HS256 tokens; leave empty to only accept JWKS-verified tokens
 	JWTSecret string
+	// JWKSURL is the JSON Web Key Set used to verify RS256/ES256 tokens
+	JWKSURL string
+	// JWKSRefreshInterval is how often the JSON Web Key Set is refetched
+	JWKSRefreshInterval time.Duration
+	// JWTAudience is the required aud claim; empty disables the check
+	JWTAudience string
+	// JWTIssuer is the required iss claim; empty disables the check
+	JWTIssuer string
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
 	Port string
+	// IdempotencyKeyTTL is how long responses stored for an Idempotency-Key are replayed
+	IdempotencyKeyTTL time.Duration
+	// LogLevel is the minimum level of log lines: debug, info, warn or error
+	LogLevel string
+	// HealthCheckTimeout bounds each dependency check of GET /readyz
+	HealthCheckTimeout time.Duration
+	// AIHealthCacheTTL is how long the result of the OpenAI reachability check is reused
+	AIHealthCacheTTL time.Duration
+}
+
+// TrashConfig holds the retention of deleted transactions
+type TrashConfig struct {
+	// Retention is how long deleted transactions can be restored before they are purged
+	Retention time.Duration
+	// PurgeInterval is how often transactions past their retention are purged
+	PurgeInterval time.Duration
+}
+
+// OperationsConfig holds how long the transaction changes of a request can be undone
+type OperationsConfig struct {
+	// UndoWindow is how long after an operation POST /operations/:id/undo accepts it
+	UndoWindow time.Duration
+}
+
+// DuplicatesConfig holds duplicate transaction detection configuration
+type DuplicatesConfig struct {
+	// DateWindow is the maximum distance between two transaction dates to be considered duplicates
+	DateWindow time.Duration
+	// SimilarityThreshold is the minimum description similarity (0-1) to be considered duplicates
+	SimilarityThreshold float64
 }
 
 // Load loads configuration from environment variables
@@ -51,40 +166,209 @@ func Load() (*Config, error) {
 
 	config := &Config{
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", ""),
-			Password: getEnv("DB_PASSWORD", ""),
-			Name:     getEnv("DB_NAME", ""),
+			Driver:     getEnv("DB_DRIVER", DriverPostgres),
+			SQLitePath: getEnv("SQLITE_PATH", "expense-tracker.db"),
+			Host:       getEnv("DB_HOST", "localhost"),
+			Port:       getEnv("DB_PORT", "5432"),
+			User:       getEnv("DB_USER", ""),
+			Password:   getEnv("DB_PASSWORD", ""),
+			Name:       getEnv("DB_NAME", ""),
 		},
 		OpenAI: OpenAIConfig{
 			APIKey: getEnv("OPENAI_API_KEY", ""),
 		},
 		Supabase: SupabaseConfig{
-			URL:       getEnv("SUPABASE_URL", ""),
-			AnonKey:   getEnv("SUPABASE_ANON_KEY", ""),
-			JWTSecret: getEnv("SUPABASE_JWT_SECRET", ""),
+			URL:         getEnv("SUPABASE_URL", ""),
+			AnonKey:     getEnv("SUPABASE_ANON_KEY", ""),
+			JWTSecret:   getEnv("SUPABASE_JWT_SECRET", ""),
+			JWKSURL:     getEnv("SUPABASE_JWKS_URL", ""),
+			JWTAudience: getEnv("SUPABASE_JWT_AUDIENCE", "authenticated"),
+			JWTIssuer:   getEnv("SUPABASE_JWT_ISSUER", ""),
 		},
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
+			Port:     getEnv("PORT", "8080"),
+			LogLevel: getEnv("LOG_LEVEL", "info"),
 		},
 	}
 
+	dateWindow, err := getEnvDuration("DUPLICATE_DATE_WINDOW", 48*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	similarityThreshold, err := getEnvFloat("DUPLICATE_SIMILARITY_THRESHOLD", 0.8)
+	if err != nil {
+		return nil, err
+	}
+	idempotencyKeyTTL, err := getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	config.Server.IdempotencyKeyTTL = idempotencyKeyTTL
+
+	healthCheckTimeout, err := getEnvDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	config.Server.HealthCheckTimeout = healthCheckTimeout
+	aiHealthCacheTTL, err := getEnvDuration("HEALTH_AI_CACHE_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	config.Server.AIHealthCacheTTL = aiHealthCacheTTL
+
+	jwksRefreshInterval, err := getEnvDuration("SUPABASE_JWKS_REFRESH_INTERVAL", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	config.Supabase.JWKSRefreshInterval = jwksRefreshInterval
+
+	trashRetention, err := getEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	trashPurgeInterval, err := getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	config.Trash = TrashConfig{
+		Retention:     trashRetention,
+		PurgeInterval: trashPurgeInterval,
+	}
+
+	undoWindow, err := getEnvDuration("OPERATION_UNDO_WINDOW", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	config.Operations.UndoWindow = undoWindow
+
+	config.Duplicates = DuplicatesConfig{
+		DateWindow:          dateWindow,
+		SimilarityThreshold: similarityThreshold,
+	}
+
+	monthlyTokenQuota, err := getEnvInt("OPENAI_MONTHLY_TOKEN_QUOTA", 0)
+	if err != nil {
+		return nil, err
+	}
+	config.OpenAI.MonthlyTokenQuota = monthlyTokenQuota
+
+	prices, err := getEnvPrices("OPENAI_PRICES", DefaultOpenAIPrices)
+	if err != nil {
+		return nil, err
+	}
+	config.OpenAI.Prices = prices
+
+	config.RateLimit.Store = getEnv("RATE_LIMIT_STORE", RateLimitStoreMemory)
+	for _, setting := range []struct {
+		key          string
+		defaultValue int
+		target       *int
+	}{
+		{"RATE_LIMIT_PARSE_PER_MINUTE", 10, &config.RateLimit.ParsePerMinute},
+		{"RATE_LIMIT_PARSE_BURST", 5, &config.RateLimit.ParseBurst},
+		{"RATE_LIMIT_PER_MINUTE", 120, &config.RateLimit.PerMinute},
+		{"RATE_LIMIT_BURST", 30, &config.RateLimit.Burst},
+	} {
+		value, err := getEnvInt(setting.key, setting.defaultValue)
+		if err != nil {
+			return nil, err
+		}
+		if value <= 0 {
+			return nil, fmt.Errorf("%s must be positive", setting.key)
+		}
+		*setting.target = value
+	}
+
+	autoMigrate, err := getEnvBool("DB_AUTO_MIGRATE", true)
+	if err != nil {
+		return nil, err
+	}
+	config.Database.AutoMigrate = autoMigrate
+
+	config.Tracing = TracingConfig{
+		Exporter:     getEnv("TRACING_EXPORTER", TracingExporterNone),
+		OTLPEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318"),
+		ServiceName:  getEnv("OTEL_SERVICE_NAME", "expense-tracker"),
+	}
+	otlpInsecure, err := getEnvBool("OTEL_EXPORTER_OTLP_INSECURE", false)
+	if err != nil {
+		return nil, err
+	}
+	config.Tracing.OTLPInsecure = otlpInsecure
+	sampleRatio, err := getEnvFloat("TRACING_SAMPLE_RATIO", 1)
+	if err != nil {
+		return nil, err
+	}
+	config.Tracing.SampleRatio = sampleRatio
+
 	// Validate required configurations
-	if config.Database.User == "" {
-		return nil, fmt.Errorf("DB_USER is required")
+	switch config.Database.Driver {
+	case DriverPostgres:
+		if config.Database.User == "" {
+			return nil, fmt.Errorf("DB_USER is required")
+		}
+		if config.Database.Password == "" {
+			return nil, fmt.Errorf("DB_PASSWORD is required")
+		}
+		if config.Database.Name == "" {
+			return nil, fmt.Errorf("DB_NAME is required")
+		}
+	case DriverSQLite:
+		if config.Database.SQLitePath == "" {
+			return nil, fmt.Errorf("SQLITE_PATH is required")
+		}
+	default:
+		return nil, fmt.Errorf("DB_DRIVER must be %q or %q", DriverPostgres, DriverSQLite)
+	}
+	switch config.RateLimit.Store {
+	case RateLimitStoreMemory:
+	case RateLimitStorePostgres:
+		if config.Database.Driver != DriverPostgres {
+			return nil, fmt.Errorf("RATE_LIMIT_STORE=%s requires DB_DRIVER=%s", RateLimitStorePostgres, DriverPostgres)
+		}
+	default:
+		return nil, fmt.Errorf("RATE_LIMIT_STORE must be %q or %q", RateLimitStoreMemory, RateLimitStorePostgres)
+	}
+	switch config.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterOTLP:
+	default:
+		return nil, fmt.Errorf("TRACING_EXPORTER must be %q or %q", TracingExporterNone, TracingExporterOTLP)
+	}
+	if config.Server.HealthCheckTimeout <= 0 {
+		return nil, fmt.Errorf("HEALTH_CHECK_TIMEOUT must be positive")
+	}
+	if config.Server.IdempotencyKeyTTL <= 0 {
+		return nil, fmt.Errorf("IDEMPOTENCY_KEY_TTL must be positive")
 	}
-	if config.Database.Password == "" {
-		return nil, fmt.Errorf("DB_PASSWORD is required")
+	if config.Trash.Retention <= 0 {
+		return nil, fmt.Errorf("TRASH_RETENTION must be positive")
 	}
-	if config.Database.Name == "" {
-		return nil, fmt.Errorf("DB_NAME is required")
+	if config.Trash.PurgeInterval <= 0 {
+		return nil, fmt.Errorf("TRASH_PURGE_INTERVAL must be positive")
+	}
+	if config.Operations.UndoWindow <= 0 {
+		return nil, fmt.Errorf("OPERATION_UNDO_WINDOW must be positive")
+	}
+	if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+		return nil, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	if config.Duplicates.DateWindow < 0 {
+		return nil, fmt.Errorf("DUPLICATE_DATE_WINDOW must not be negative")
+	}
+	if config.Duplicates.SimilarityThreshold < 0 || config.Duplicates.SimilarityThreshold > 1 {
+		return nil, fmt.Errorf("DUPLICATE_SIMILARITY_THRESHOLD must be between 0 and 1")
+	}
+	if config.OpenAI.MonthlyTokenQuota < 0 {
+		return nil, fmt.Errorf("OPENAI_MONTHLY_TOKEN_QUOTA must not be negative")
 	}
 	if config.OpenAI.APIKey == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY is required")
 	}
-	if config.Supabase.JWTSecret == "" {
-		return nil, fmt.Errorf("SUPABASE_JWT_SECRET is required")
+	if config.Supabase.JWTSecret == "" && config.Supabase.JWKSURL == "" {
+		return nil, fmt.Errorf("SUPABASE_JWT_SECRET or SUPABASE_JWKS_URL is required")
+	}
+	if config.Supabase.JWKSRefreshInterval <= 0 {
+		return nil, fmt.Errorf("SUPABASE_JWKS_REFRESH_INTERVAL must be positive")
 	}
 
 	return config, nil
@@ -108,3 +392,79 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration gets an environment variable parsed as a time.Duration with a default value
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration (e.g. 48h): %w", key, err)
+	}
+	return d, nil
+}
+
+// getEnvFloat gets an environment variable parsed as a float64 with a default value
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number: %w", key, err)
+	}
+	return f, nil
+}
+
+// getEnvInt gets an environment variable parsed as an int with a default value
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	return i, nil
+}
+
+// getEnvPrices gets an environment variable holding a JSON object of model prices, e.g.
+// {"gpt-3.5-turbo": {"prompt": 0.5, "completion": 1.5}}, with a default value
+func getEnvPrices(key string, defaultValue map[string]ModelPrice) (map[string]ModelPrice, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	var prices map[string]ModelPrice
+	if err := json.Unmarshal([]byte(value), &prices); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object of model prices: %w", key, err)
+	}
+	for model, price := range prices {
+		if price.Prompt < 0 || price.Completion < 0 {
+			return nil, fmt.Errorf("%s: price of %s must not be negative", key, model)
+		}
+	}
+	return prices, nil
+}
+
+// getEnvBool gets an environment variable parsed as a bool with a default value
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false: %w", key, err)
+	}
+	return b, nil
+}
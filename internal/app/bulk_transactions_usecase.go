@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// BulkTransactionsUseCase handles batches of creates, updates and deletes applied all or none
+type BulkTransactionsUseCase struct {
+	transactionService domain.TransactionService
+	metrics            domain.TransactionMetrics
+}
+
+// NewBulkTransactionsUseCase creates a new bulk transactions use case. metrics may be nil.
+func NewBulkTransactionsUseCase(transactionService domain.TransactionService, metrics domain.TransactionMetrics) *BulkTransactionsUseCase {
+	return &BulkTransactionsUseCase{
+		transactionService: transactionService,
+		metrics:            metrics,
+	}
+}
+
+// Execute applies the writes atomically, filling in the created and updated transactions
+func (uc *BulkTransactionsUseCase) Execute(ctx context.Context, writes []domain.TransactionWrite) (err error) {
+	ctx, span := tracer.Start(ctx, "BulkTransactionsUseCase.Execute")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if err := uc.transactionService.ApplyTransactionWrites(ctx, writes); err != nil {
+		logging.FromContext(ctx).Error("failed to apply bulk operations", "error", err, "count", len(writes))
+		return err
+	}
+
+	created := 0
+	for _, write := range writes {
+		if write.Action == domain.BulkActionCreate {
+			created++
+		}
+	}
+	if uc.metrics != nil && created > 0 {
+		uc.metrics.TransactionsSaved(domain.TransactionSourceFromContext(ctx), created)
+	}
+	span.SetAttributes(attribute.Int("transactions.written", len(writes)), attribute.Int("transactions.saved", created))
+	logging.FromContext(ctx).Info("applied bulk operations", "writes", len(writes), "saved", created)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// CreateTransactionsUseCase handles the manual creation of structured transactions,
+// for clients that already know their details and need no AI
+type CreateTransactionsUseCase struct {
+	transactionService domain.TransactionService
+	metrics            domain.TransactionMetrics
+}
+
+// NewCreateTransactionsUseCase creates a new create transactions use case. metrics may be nil.
+func NewCreateTransactionsUseCase(transactionService domain.TransactionService, metrics domain.TransactionMetrics) *CreateTransactionsUseCase {
+	return &CreateTransactionsUseCase{
+		transactionService: transactionService,
+		metrics:            metrics,
+	}
+}
+
+// Execute saves the requested transactions, all or none, and returns them with their IDs
+func (uc *CreateTransactionsUseCase) Execute(ctx context.Context, request domain.CreateTransactionsRequest) (response *domain.CreateTransactionsResponse, err error) {
+	ctx, span := tracer.Start(ctx, "CreateTransactionsUseCase.Execute")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	transactions := make([]domain.Transaction, len(request.Transactions))
+	for i, t := range request.Transactions {
+		transactions[i] = domain.Transaction{
+			Amount:      t.Amount,
+			Currency:    t.Currency,
+			Category:    t.Category,
+			Type:        t.Type,
+			Date:        t.Date,
+			Description: t.Description,
+			Account:     t.Account,
+			Tags:        t.Tags,
+		}
+	}
+
+	created, err := uc.transactionService.CreateTransactions(ctx, transactions)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to create transactions", "error", err, "count", len(transactions))
+		return nil, err
+	}
+
+	if uc.metrics != nil {
+		uc.metrics.TransactionsSaved(domain.TransactionSourceFromContext(ctx), len(created))
+	}
+	span.SetAttributes(attribute.Int("transactions.saved", len(created)))
+	logging.FromContext(ctx).Info("created transactions", "saved", len(created))
+
+	return &domain.CreateTransactionsResponse{
+		Transactions: created,
+	}, nil
+}
@@ -2,43 +2,98 @@ package app
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// tracer records the spans of the application use cases
+var tracer = otel.Tracer("github.com/jairogloz/go-expense-tracker-back/internal/app")
+
 // ParseInputUseCase handles the parsing of natural language input into transactions
 type ParseInputUseCase struct {
 	aiService          domain.AIService
 	transactionService domain.TransactionService
+	quotaService       domain.AIQuotaService
+	metrics            domain.TransactionMetrics
 }
 
-// NewParseInputUseCase creates a new parse input use case
-func NewParseInputUseCase(aiService domain.AIService, transactionService domain.TransactionService) *ParseInputUseCase {
+// NewParseInputUseCase creates a new parse input use case. AI token quotas are
+// not enforced when quotaService is nil, and metrics may be nil.
+func NewParseInputUseCase(aiService domain.AIService, transactionService domain.TransactionService, quotaService domain.AIQuotaService, metrics domain.TransactionMetrics) *ParseInputUseCase {
 	return &ParseInputUseCase{
 		aiService:          aiService,
 		transactionService: transactionService,
+		quotaService:       quotaService,
+		metrics:            metrics,
 	}
 }
 
-// Execute parses the input text and saves the resulting transactions
-func (uc *ParseInputUseCase) Execute(ctx context.Context, request domain.ParseInputRequest) (*domain.ParseInputResponse, error) {
+// Execute parses the input text and saves the resulting transactions.
+// It returns a *domain.QuotaExceededError without calling the AI when the user's quota is used up.
+func (uc *ParseInputUseCase) Execute(ctx context.Context, request domain.ParseInputRequest) (response *domain.ParseInputResponse, err error) {
+	ctx, span := tracer.Start(ctx, "ParseInputUseCase.Execute")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	logger := logging.FromContext(ctx)
+
+	if userID, ok := domain.UserIDFromContext(ctx); ok && uc.quotaService != nil {
+		if err := uc.quotaService.CheckQuota(ctx, userID); err != nil {
+			logger.Warn("AI quota check rejected parse", "error", err)
+			return nil, err
+		}
+	}
+
 	// Parse the text using AI service
 	transactions, err := uc.aiService.ParseTextToTransactions(ctx, request.Text)
 	if err != nil {
+		logger.Error("failed to parse input with AI", "error", err)
 		return nil, err
 	}
 
+	response = &domain.ParseInputResponse{
+		Transactions: transactions,
+		Message:      "Successfully parsed and saved transactions",
+	}
+
 	// Save the transactions using transaction service
 	if len(transactions) > 0 {
-		if err := uc.transactionService.SaveTransactions(ctx, transactions); err != nil {
+		result, err := uc.transactionService.SaveTransactions(domain.WithTransactionSource(ctx, domain.SourceParse), transactions, request.OnDuplicate)
+		if err != nil {
+			logger.Error("failed to save parsed transactions", "error", err, "count", len(transactions))
 			return nil, err
 		}
-	}
 
-	response := &domain.ParseInputResponse{
-		Transactions: transactions,
-		Message:      "Successfully parsed and saved transactions",
+		response.Transactions = result.Saved
+		if uc.metrics != nil {
+			uc.metrics.TransactionsSaved(domain.SourceParse, len(result.Saved))
+		}
+		response.Duplicates = result.Duplicates
+		if len(result.Duplicates) > 0 {
+			response.Message = fmt.Sprintf("Parsed transactions; %d suspected duplicate(s) found", len(result.Duplicates))
+		}
 	}
 
+	span.SetAttributes(
+		attribute.Int("transactions.parsed", len(transactions)),
+		attribute.Int("transactions.saved", len(response.Transactions)),
+		attribute.Int("transactions.duplicates", len(response.Duplicates)),
+	)
+	logger.Info("parsed input",
+		"parsed", len(transactions),
+		"saved", len(response.Transactions),
+		"duplicates", len(response.Duplicates),
+	)
+
 	return response, nil
 }
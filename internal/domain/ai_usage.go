@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// AIUsage records a single AI provider call and the tokens it consumed
+type AIUsage struct {
+	ID               int           `json:"id"`
+	UserID           string        `json:"-"`
+	Model            string        `json:"model"`
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	TotalTokens      int           `json:"total_tokens"`
+	Latency          time.Duration `json:"-"`
+	Success          bool          `json:"success"`
+	Error            string        `json:"error,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+}
+
+// AIUsageFilter selects the calls included in a usage report; an empty UserID means all users
+type AIUsageFilter struct {
+	UserID string
+	From   time.Time
+	To     time.Time
+}
+
+// AIUsageAggregate summarizes the calls made with one model on one UTC day; Day and Model are
+// empty in period totals
+type AIUsageAggregate struct {
+	Day              string  `json:"day,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	Calls            int     `json:"calls"`
+	Failures         int     `json:"failures"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	AvgLatencyMs     float64 `json:"avg_latency_ms"`
+	EstimatedCost    float64 `json:"estimated_cost"`
+}
+
+// AIUsageReport is the usage of a period, broken down by day and model
+type AIUsageReport struct {
+	From   time.Time          `json:"from"`
+	To     time.Time          `json:"to"`
+	Totals AIUsageAggregate   `json:"totals"`
+	Days   []AIUsageAggregate `json:"days"`
+	// Quota is the caller's monthly AI token quota; omitted in cross-user reports
+	Quota *AIQuotaStatus `json:"quota,omitempty"`
+}
+
+// AIModelPrice is the price of a model in USD per million tokens
+type AIModelPrice struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// Cost returns the estimated cost of the given token counts
+func (p AIModelPrice) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.Prompt + float64(completionTokens)*p.Completion) / 1_000_000
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope is a permission that can be granted to an API key
+type Scope string
+
+// Scopes that can be granted to API keys
+const (
+	ScopeTransactionsRead  Scope = "transactions:read"
+	ScopeTransactionsWrite Scope = "transactions:write"
+	ScopeRulesRead         Scope = "rules:read"
+	ScopeRulesWrite        Scope = "rules:write"
+	ScopeUsageRead         Scope = "usage:read"
+)
+
+// ScopeAPIKeysManage allows managing API keys. It is implied by session tokens and
+// can never be granted to an API key, so a leaked key cannot mint new ones.
+const ScopeAPIKeysManage Scope = "api_keys:manage"
+
+// APIKeyPrefix marks a credential as an API key rather than a JWT
+const APIKeyPrefix = "etk_"
+
+// ValidAPIKeyScopes are the scopes that can be granted to an API key
+var ValidAPIKeyScopes = []Scope{
+	ScopeTransactionsRead,
+	ScopeTransactionsWrite,
+	ScopeRulesRead,
+	ScopeRulesWrite,
+	ScopeUsageRead,
+}
+
+// APIKey represents a user-managed key for scripts and integrations.
+// Only a hash of the secret is stored; the full key is shown once on creation.
+type APIKey struct {
+	ID         int        `json:"id"`
+	UserID     string     `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []Scope    `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyRequest represents the request for creating an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []Scope    `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse returns a new API key together with its secret
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyListResponse lists a user's API keys
+type APIKeyListResponse struct {
+	APIKeys []APIKey `json:"api_keys"`
+}
+
+// Validate checks that the key has a name, valid scopes and an expiry in the future
+func (k *APIKey) Validate() error {
+	if strings.TrimSpace(k.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(k.Scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range k.Scopes {
+		if !isValidAPIKeyScope(scope) {
+			return fmt.Errorf("invalid scope %q", scope)
+		}
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	return nil
+}
+
+// Expired reports whether the key has passed its expiry time
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// isValidAPIKeyScope reports whether scope can be granted to an API key
+func isValidAPIKeyScope(scope Scope) bool {
+	for _, valid := range ValidAPIKeyScopes {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AuditAction is the kind of change recorded by a TransactionEvent
+type AuditAction string
+
+// Changes recorded in the audit log
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+)
+
+// TransactionEvent is an entry of the append-only audit log of transaction changes.
+// Before and After are the transaction as it was before and after the change; Before is
+// nil for creates. Deletes and restores record the transaction moving in and out of the trash.
+type TransactionEvent struct {
+	ID            int         `json:"id"`
+	TransactionID int         `json:"transaction_id"`
+	Action        AuditAction `json:"action"`
+	// UserID owns the transaction, so its history is purged with the rest of the user's data
+	UserID string `json:"-"`
+	// ActorID is the user who made the change; empty for changes made outside a request
+	ActorID   string            `json:"actor_id,omitempty"`
+	Source    TransactionSource `json:"source"`
+	RequestID string            `json:"request_id,omitempty"`
+	// OperationID groups the events of a single request; empty for changes made outside one
+	OperationID string `json:"operation_id,omitempty"`
+	// RevertsOperationID is the operation this event helped undo, if any
+	RevertsOperationID string       `json:"reverts_operation_id,omitempty"`
+	Before             *Transaction `json:"before"`
+	After              *Transaction `json:"after"`
+	CreatedAt          time.Time    `json:"created_at"`
+}
+
+// TransactionHistoryResponse lists the changes of a transaction, oldest first
+type TransactionHistoryResponse struct {
+	Events []TransactionEvent `json:"events"`
+}
+
+// NewTransactionEvent records a change to a transaction made in ctx, taking the actor,
+// source, request ID and operations from it
+func NewTransactionEvent(ctx context.Context, action AuditAction, before, after *Transaction) TransactionEvent {
+	event := TransactionEvent{
+		Action: action,
+		Source: TransactionSourceFromContext(ctx),
+		Before: before,
+		After:  after,
+	}
+	if after != nil {
+		event.TransactionID = after.ID
+	} else if before != nil {
+		event.TransactionID = before.ID
+	}
+	event.ActorID, _ = UserIDFromContext(ctx)
+	event.RequestID, _ = ctx.Value(RequestIDKey).(string)
+	event.OperationID, _ = OperationIDFromContext(ctx)
+	event.RevertsOperationID, _ = ctx.Value(RevertedOperationIDKey).(string)
+	return event
+}
+
+// WithTransactionSource returns a copy of ctx recording that the transactions changed
+// with it come from source
+func WithTransactionSource(ctx context.Context, source TransactionSource) context.Context {
+	return context.WithValue(ctx, TransactionSourceKey, source)
+}
+
+// TransactionSourceFromContext returns the source set with WithTransactionSource. Otherwise
+// changes are attributed to the API key the request authenticated with, if any, and are
+// manual by default.
+func TransactionSourceFromContext(ctx context.Context) TransactionSource {
+	if source, ok := ctx.Value(TransactionSourceKey).(TransactionSource); ok {
+		return source
+	}
+	if user, ok := ctx.Value(AuthUserKey).(*AuthUser); ok && user.APIKeyID != 0 {
+		return SourceAPIKey
+	}
+	return SourceManual
+}
@@ -0,0 +1,79 @@
+package domain
+
+import "time"
+
+// Permission is an action that requires a role beyond being an authenticated user
+type Permission string
+
+// Permissions checked by RequirePermission
+const (
+	PermissionViewUsage     Permission = "admin:usage:read"
+	PermissionPurgeUserData Permission = "admin:data:purge"
+)
+
+// Roles with elevated permissions
+const (
+	// RoleAdmin is granted through app_metadata.role or app_metadata.roles
+	RoleAdmin = "admin"
+	// RoleServiceRole is the Postgres role of tokens signed for Supabase's service_role
+	RoleServiceRole = "service_role"
+)
+
+// rolePermissions maps roles to the permissions they grant
+var rolePermissions = map[string][]Permission{
+	RoleAdmin:       {PermissionViewUsage, PermissionPurgeUserData},
+	RoleServiceRole: {PermissionViewUsage, PermissionPurgeUserData},
+}
+
+// HasRole reports whether the user holds role, either as its JWT role or an application role
+func (u *AuthUser) HasRole(role string) bool {
+	if u.Role == role {
+		return true
+	}
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether any of the user's roles grants permission.
+// API keys never carry roles, so they never hold permissions.
+func (u *AuthUser) HasPermission(permission Permission) bool {
+	for role, permissions := range rolePermissions {
+		if !u.HasRole(role) {
+			continue
+		}
+		for _, p := range permissions {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UserUsage summarizes the data a user has stored
+type UserUsage struct {
+	UserID           string     `json:"user_id"`
+	TransactionCount int        `json:"transaction_count"`
+	RuleCount        int        `json:"rule_count"`
+	APIKeyCount      int        `json:"api_key_count"`
+	LastActivityAt   *time.Time `json:"last_activity_at,omitempty"`
+}
+
+// UserUsageListResponse is a page of per-user usage summaries
+type UserUsageListResponse struct {
+	Users  []UserUsage `json:"users"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+}
+
+// PurgeResult reports how many records were deleted when purging a user's data
+type PurgeResult struct {
+	UserID       string `json:"user_id"`
+	Transactions int    `json:"transactions"`
+	Rules        int    `json:"rules"`
+	APIKeys      int    `json:"api_keys"`
+}
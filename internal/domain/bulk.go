@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxBulkOperations is the maximum number of transactions a bulk request may change
+const MaxBulkOperations = 1000
+
+// BulkAction represents the change a bulk operation makes to a transaction
+type BulkAction string
+
+const (
+	BulkActionCreate      BulkAction = "create"
+	BulkActionUpdate      BulkAction = "update"
+	BulkActionDelete      BulkAction = "delete"
+	BulkActionSetCategory BulkAction = "set_category"
+	// BulkActionRestore moves a transaction out of the trash. It is used to undo
+	// operations and cannot be requested in bulk.
+	BulkActionRestore BulkAction = "restore"
+)
+
+// BulkOperation creates, updates or deletes a single transaction. Updates and deletes
+// identify the transaction by ID and may be made conditional with the ETag it was read with.
+type BulkOperation struct {
+	Action      BulkAction                `json:"action" binding:"required,oneof=create update delete"`
+	ID          int                       `json:"id,omitempty"`
+	IfMatch     string                    `json:"if_match,omitempty"`
+	Transaction *UpdateTransactionRequest `json:"transaction,omitempty"`
+}
+
+// TransactionFilter selects transactions. Empty fields match any transaction, except
+// UserID; From and To bound the date, inclusive.
+type TransactionFilter struct {
+	Category            Category        `json:"category,omitempty" binding:"omitempty"`
+	Type                TransactionType `json:"type,omitempty" binding:"omitempty"`
+	Account             string          `json:"account,omitempty"`
+	DescriptionContains string          `json:"description_contains,omitempty"`
+	From                *time.Time      `json:"from,omitempty"`
+	To                  *time.Time      `json:"to,omitempty"`
+	// UserID restricts the filter to the transactions owned by the user; empty matches only
+	// transactions saved before ownership was recorded
+	UserID string `json:"-"`
+}
+
+// IsEmpty reports whether the filter sets no condition besides the owner
+func (f TransactionFilter) IsEmpty() bool {
+	return f == TransactionFilter{UserID: f.UserID}
+}
+
+// Matches reports whether a transaction is selected by the filter. Description matching
+// is case-insensitive.
+func (f TransactionFilter) Matches(transaction Transaction) bool {
+	switch {
+	case f.Category != "" && transaction.Category != f.Category,
+		f.Type != "" && transaction.Type != f.Type,
+		f.Account != "" && transaction.Account != f.Account,
+		f.DescriptionContains != "" && !strings.Contains(strings.ToLower(transaction.Description), strings.ToLower(f.DescriptionContains)),
+		f.From != nil && transaction.Date.Before(*f.From),
+		f.To != nil && transaction.Date.After(*f.To),
+		transaction.UserID != f.UserID:
+		return false
+	}
+	return true
+}
+
+// BulkRequest changes many transactions at once, either with a list of operations or by
+// applying an action (set_category or delete) to every transaction matching a filter
+type BulkRequest struct {
+	Operations []BulkOperation    `json:"operations" binding:"max=1000,dive"`
+	Filter     *TransactionFilter `json:"filter"`
+	Action     BulkAction         `json:"action" binding:"omitempty,oneof=set_category delete"`
+	Category   Category           `json:"category" binding:"omitempty"`
+}
+
+// BulkResult is the outcome of one operation of a bulk request. Transaction is the
+// transaction as saved, for creates and updates.
+type BulkResult struct {
+	Index       int          `json:"index"`
+	Action      BulkAction   `json:"action"`
+	ID          int          `json:"id"`
+	ETag        string       `json:"etag,omitempty"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
+
+// BulkResponse lists the outcome of every operation of an applied bulk request, in order
+type BulkResponse struct {
+	Results []BulkResult `json:"results"`
+	// OperationID identifies the changes for POST /operations/:id/undo
+	OperationID string `json:"operation_id,omitempty"`
+}
+
+// TransactionWrite is a single change of a batch applied atomically by the repository.
+// Creates and updates use the whole transaction; deletes and restores its ID and, when
+// set, UpdatedAt. Every write names the user it acts for in Transaction.UserID: creates
+// are owned by that user, and the other writes only apply to that user's transactions.
+// The repository sets the ID, CreatedAt and UpdatedAt of created and updated transactions.
+type TransactionWrite struct {
+	Action      BulkAction
+	Transaction Transaction
+}
+
+// BatchError reports the write of a batch that failed; none of the batch was applied
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("write %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error of the failed write
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DuplicatePolicy represents how suspected duplicate transactions are handled when saving
+type DuplicatePolicy string
+
+const (
+	// DuplicatePolicySkip does not save suspected duplicates (default)
+	DuplicatePolicySkip DuplicatePolicy = "skip"
+	// DuplicatePolicyMerge folds the incoming transaction into the existing one
+	DuplicatePolicyMerge DuplicatePolicy = "merge"
+	// DuplicatePolicyForce saves suspected duplicates anyway
+	DuplicatePolicyForce DuplicatePolicy = "force"
+)
+
+// DuplicateMatch pairs an incoming transaction with the existing transaction it appears to duplicate
+type DuplicateMatch struct {
+	Transaction Transaction     `json:"transaction"`
+	Existing    Transaction     `json:"existing"`
+	Similarity  float64         `json:"similarity"`
+	Resolution  DuplicatePolicy `json:"resolution"`
+}
+
+// DuplicateGroup is a set of existing transactions that appear to be duplicates of each other
+type DuplicateGroup struct {
+	Transactions []Transaction `json:"transactions"`
+}
+
+// DuplicateGroupsResponse lists the groups of suspected duplicate transactions
+type DuplicateGroupsResponse struct {
+	Groups []DuplicateGroup `json:"groups"`
+}
+
+// SaveTransactionsResult represents the outcome of saving a batch of transactions
+type SaveTransactionsResult struct {
+	Saved      []Transaction    `json:"saved"`
+	Duplicates []DuplicateMatch `json:"duplicates,omitempty"`
+}
+
+// AmountTolerance is the maximum difference for two amounts to be considered equal
+const AmountTolerance = 0.005
+
+// SameAmount reports whether two transactions have the same amount and currency
+func SameAmount(a, b Transaction) bool {
+	return strings.EqualFold(a.Currency, b.Currency) && math.Abs(a.Amount-b.Amount) < AmountTolerance
+}
+
+// DescriptionSimilarity returns a score between 0 and 1 describing how alike two
+// descriptions are, based on the edit distance of their normalized forms
+func DescriptionSimilarity(a, b string) float64 {
+	ra := []rune(normalizeDescription(a))
+	rb := []rune(normalizeDescription(b))
+
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// normalizeDescription lowercases the text, drops punctuation and collapses whitespace
+func normalizeDescription(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// levenshtein computes the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
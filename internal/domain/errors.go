@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds. Match them with errors.Is; the HTTP layer maps each kind to a status and a
+// stable error code.
+var (
+	ErrNotFound              = errors.New("not found")
+	ErrValidation            = errors.New("validation failed")
+	ErrConflict              = errors.New("conflict")
+	ErrPreconditionFailed    = errors.New("precondition failed")
+	ErrPreconditionRequired  = errors.New("precondition required")
+	ErrUnauthorized          = errors.New("unauthorized")
+	ErrForbidden             = errors.New("forbidden")
+	ErrRateLimited           = errors.New("rate limited")
+	ErrUpstreamAIUnavailable = errors.New("AI provider unavailable")
+)
+
+// Error is an error of a known kind. Message is safe to show to clients; Err, the
+// underlying cause, is only meant for logs.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+
+	// Fields lists the invalid fields of a validation error, when known
+	Fields []FieldError
+}
+
+// FieldError describes an invalid field of a request, e.g. the "category" of the body
+type FieldError struct {
+	In      string `json:"in"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Is reports whether target is the kind of the error
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewNotFoundError reports that the resource with id does not exist, e.g. "transaction with id 5 not found"
+func NewNotFoundError(resource string, id any) *Error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf("%s with id %v not found", resource, id)}
+}
+
+// NewValidationError reports invalid input
+func NewValidationError(message string) *Error {
+	return &Error{Kind: ErrValidation, Message: message}
+}
+
+// NewFieldValidationError reports a request whose fields failed validation
+func NewFieldValidationError(fields []FieldError) *Error {
+	return &Error{Kind: ErrValidation, Message: "request validation failed", Fields: fields}
+}
+
+// NewConflictError reports a request conflicting with the current state of a resource
+func NewConflictError(message string) *Error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+// NewPreconditionFailedError reports a conditional write whose resource changed since the
+// client last read it
+func NewPreconditionFailedError(message string) *Error {
+	return &Error{Kind: ErrPreconditionFailed, Message: message}
+}
+
+// NewPreconditionRequiredError reports a write that must be made conditional
+func NewPreconditionRequiredError(message string) *Error {
+	return &Error{Kind: ErrPreconditionRequired, Message: message}
+}
+
+// NewUnauthorizedError reports missing or invalid credentials; cause may be nil
+func NewUnauthorizedError(message string, cause error) *Error {
+	return &Error{Kind: ErrUnauthorized, Message: message, Err: cause}
+}
+
+// NewForbiddenError reports valid credentials lacking the required permission
+func NewForbiddenError(message string) *Error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+// NewRateLimitedError reports a request rejected by a rate limit
+func NewRateLimitedError(message string) *Error {
+	return &Error{Kind: ErrRateLimited, Message: message}
+}
+
+// NewUpstreamAIError reports that the AI provider failed or returned an unusable response
+func NewUpstreamAIError(cause error) *Error {
+	return &Error{Kind: ErrUpstreamAIUnavailable, Message: "the AI provider is unavailable; try again later", Err: cause}
+}
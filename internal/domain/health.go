@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Health statuses of a check or of the service as a whole
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDown     = "down"
+	HealthStatusDegraded = "degraded"
+)
+
+// HealthCheckResult is the outcome of a single dependency check
+type HealthCheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Required checks make the service unready when they fail
+	Required  bool    `json:"required"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReport aggregates the dependency checks. Status is down when a required check
+// fails and degraded when only optional ones do.
+type HealthReport struct {
+	Status string              `json:"status"`
+	Checks []HealthCheckResult `json:"checks"`
+	Time   time.Time           `json:"time"`
+}
+
+// LivenessReport reports that the process is serving requests
+type LivenessReport struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
+// Ready reports whether every required dependency is up
+func (r *HealthReport) Ready() bool {
+	return r.Status != HealthStatusDown
+}
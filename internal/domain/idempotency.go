@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord stores the outcome of a write request sent with an Idempotency-Key header,
+// so retries of the same request can be answered without executing it again
+type IdempotencyRecord struct {
+	UserID       string
+	Key          string
+	Method       string
+	Path         string
+	RequestHash  string
+	StatusCode   int
+	ResponseBody []byte
+	// ResponseHeaders holds the response headers replayed with the body, such as ETag
+	ResponseHeaders map[string]string
+	Completed       bool
+	CreatedAt       time.Time
+}
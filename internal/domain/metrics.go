@@ -0,0 +1,13 @@
+package domain
+
+// TransactionSource identifies how transactions entered or changed in the system, for
+// business metrics and the audit log
+type TransactionSource string
+
+// Sources of saved transactions
+const (
+	SourceParse  TransactionSource = "parse"
+	SourceManual TransactionSource = "manual"
+	// SourceAPIKey attributes changes to scripts and integrations using an API key
+	SourceAPIKey TransactionSource = "api_key"
+)
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Operation groups the transaction changes made by a single request, such as every
+// transaction saved by one parse, so they can be undone together
+type Operation struct {
+	ID string `json:"id"`
+	// UndoneBy is the operation that undid this one, if any
+	UndoneBy string `json:"undone_by,omitempty"`
+	// Events are the changes made by the operation, in order
+	Events []TransactionEvent `json:"events"`
+}
+
+// NewOperationID generates a random 128-bit operation ID
+func NewOperationID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithOperation returns a copy of ctx grouping the transaction changes made with it
+// into the operation with id
+func WithOperation(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, OperationIDKey, id)
+}
+
+// OperationIDFromContext returns the operation set with WithOperation, if any
+func OperationIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(OperationIDKey).(string)
+	return id, ok && id != ""
+}
+
+// WithRevertedOperation returns a copy of ctx recording that the transaction changes made
+// with it undo the operation with id
+func WithRevertedOperation(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RevertedOperationIDKey, id)
+}
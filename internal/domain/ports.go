@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // AIService defines the port for AI-related operations
 type AIService interface {
@@ -12,20 +15,186 @@ type AuthService interface {
 	ValidateToken(ctx context.Context, token string) (*AuthUser, error)
 }
 
-// TransactionRepository defines the port for transaction persistence
+// TransactionRepository defines the port for transaction persistence. Creating and
+// updating transactions sets their ID, CreatedAt and UpdatedAt as stored. Deleting
+// moves a transaction to the trash, hidden from every other read, until it is restored
+// or purged. Every change is recorded in the transaction's audit history, atomically
+// with the change itself. Updates, deletes and restores only apply to transactions of the
+// user they name, Transaction.UserID for writes; another user's transaction is reported
+// as not found.
 type TransactionRepository interface {
-	SaveTransactions(ctx context.Context, transactions []Transaction) error
-	GetTransactionByID(ctx context.Context, id int) (*Transaction, error)
-	GetTransactions(ctx context.Context, limit, offset int) ([]Transaction, error)
+	// GetTransactionByID returns the user's transaction, or nil if the user has none with id
+	GetTransactionByID(ctx context.Context, userID string, id int) (*Transaction, error)
+	GetTransactions(ctx context.Context, userID string, limit, offset int) ([]Transaction, error)
 	UpdateTransaction(ctx context.Context, transaction *Transaction) error
-	DeleteTransaction(ctx context.Context, id int) error
+	DeleteTransaction(ctx context.Context, userID string, id int, ifUpdatedAt time.Time) error
+	// FindTransactions returns the transactions of filter.UserID matching filter, by ID
+	FindTransactions(ctx context.Context, filter TransactionFilter) ([]Transaction, error)
+	// FindDuplicateCandidates returns the user's transactions that share their type, amount
+	// and currency with another of the user's transactions dated within dateWindow, by date
+	FindDuplicateCandidates(ctx context.Context, userID string, dateWindow time.Duration) ([]Transaction, error)
+	ApplyTransactionWrites(ctx context.Context, writes []TransactionWrite) error
+	GetDeletedTransactions(ctx context.Context, userID string, limit, offset int) ([]Transaction, error)
+	// RestoreTransaction moves the user's transaction out of the trash; another user's
+	// transaction is reported as not found
+	RestoreTransaction(ctx context.Context, userID string, id int) (*Transaction, error)
+	PurgeDeletedTransactions(ctx context.Context, deletedBefore time.Time) (int, error)
+	// GetTransactionHistory returns the audit events of the user's transaction, oldest first.
+	// It fails with not found when the user has neither the transaction nor its events.
+	GetTransactionHistory(ctx context.Context, userID string, id int) ([]TransactionEvent, error)
+	// GetOperation returns the audit events of an operation, or nil if it made no changes
+	GetOperation(ctx context.Context, id string) (*Operation, error)
 }
 
 // TransactionService defines the port for transaction business logic
 type TransactionService interface {
-	SaveTransactions(ctx context.Context, transactions []Transaction) error
-	GetTransactionByID(ctx context.Context, id int) (*Transaction, error)
-	GetTransactions(ctx context.Context, limit, offset int) ([]Transaction, error)
+	SaveTransactions(ctx context.Context, transactions []Transaction, policy DuplicatePolicy) (*SaveTransactionsResult, error)
+	GetTransactionByID(ctx context.Context, userID string, id int) (*Transaction, error)
+	GetTransactions(ctx context.Context, userID string, limit, offset int) ([]Transaction, error)
+	FindDuplicates(ctx context.Context) ([]DuplicateGroup, error)
+	// UpdateTransaction updates an existing transaction of transaction.UserID
 	UpdateTransaction(ctx context.Context, transaction *Transaction) error
-	DeleteTransaction(ctx context.Context, id int) error
+	// PatchTransaction updates a transaction of the user with the result of patch applied
+	// to it as stored, if it has not changed since ifUpdatedAt when that is set
+	PatchTransaction(ctx context.Context, userID string, id int, ifUpdatedAt time.Time, patch func(Transaction) (*Transaction, error)) (*Transaction, error)
+	DeleteTransaction(ctx context.Context, userID string, id int, ifUpdatedAt time.Time) error
+	CreateTransactions(ctx context.Context, transactions []Transaction) ([]Transaction, error)
+	ApplyTransactionWrites(ctx context.Context, writes []TransactionWrite) error
+	ApplyToMatching(ctx context.Context, filter TransactionFilter, action BulkAction, category Category) ([]TransactionWrite, error)
+	GetDeletedTransactions(ctx context.Context, limit, offset int) ([]Transaction, error)
+	RestoreTransaction(ctx context.Context, id int) (*Transaction, error)
+	PurgeDeletedTransactions(ctx context.Context, retention time.Duration) (int, error)
+	GetTransactionHistory(ctx context.Context, id int) ([]TransactionEvent, error)
+}
+
+// OperationService defines the port for undoing the changes of a request
+type OperationService interface {
+	// UndoOperation reverts every change of the operation atomically and returns the
+	// operation that did so
+	UndoOperation(ctx context.Context, id string) (*Operation, error)
+}
+
+// RuleRepository defines the port for auto-categorization rule persistence
+type RuleRepository interface {
+	CreateRule(ctx context.Context, rule *Rule) error
+	GetRuleByID(ctx context.Context, userID string, id int) (*Rule, error)
+	GetRules(ctx context.Context, userID string) ([]Rule, error)
+	UpdateRule(ctx context.Context, rule *Rule) error
+	DeleteRule(ctx context.Context, userID string, id int) error
+}
+
+// RuleService defines the port for auto-categorization rule business logic
+type RuleService interface {
+	CreateRule(ctx context.Context, rule *Rule) error
+	GetRuleByID(ctx context.Context, userID string, id int) (*Rule, error)
+	GetRules(ctx context.Context, userID string) ([]Rule, error)
+	UpdateRule(ctx context.Context, rule *Rule) error
+	DeleteRule(ctx context.Context, userID string, id int) error
+	ApplyRules(ctx context.Context, userID string, transactions []Transaction) error
+	TestRule(ctx context.Context, rule *Rule) (*RuleTestResult, error)
+}
+
+// IdempotencyRepository defines the port for idempotency key persistence
+type IdempotencyRepository interface {
+	// CreateIdempotencyRecord stores a pending record and reports false if the key already exists for the user
+	CreateIdempotencyRecord(ctx context.Context, record *IdempotencyRecord) (bool, error)
+	GetIdempotencyRecord(ctx context.Context, userID, key string) (*IdempotencyRecord, error)
+	CompleteIdempotencyRecord(ctx context.Context, record *IdempotencyRecord) error
+	DeleteIdempotencyRecord(ctx context.Context, userID, key string) error
+}
+
+// APIKeyRepository defines the port for API key persistence
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	// GetAPIKeyByPrefix looks up a key by its public prefix across all users
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	GetAPIKeys(ctx context.Context, userID string) ([]APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID string, id int) error
+	TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error
+}
+
+// APIKeyService defines the port for API key business logic
+type APIKeyService interface {
+	// CreateAPIKey generates a new key and returns it together with its secret, which is not stored
+	CreateAPIKey(ctx context.Context, key *APIKey) (string, error)
+	GetAPIKeys(ctx context.Context, userID string) ([]APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID string, id int) error
+	// Authenticate resolves an API key secret to the user and scopes it grants
+	Authenticate(ctx context.Context, secret string) (*AuthUser, error)
+}
+
+// AdminRepository defines the port for cross-user administrative queries
+type AdminRepository interface {
+	GetUsersUsage(ctx context.Context, limit, offset int) ([]UserUsage, error)
+	// PurgeUserData deletes every record owned by the user atomically
+	PurgeUserData(ctx context.Context, userID string) (*PurgeResult, error)
+}
+
+// AdminService defines the port for administrative business logic
+type AdminService interface {
+	GetUsersUsage(ctx context.Context, limit, offset int) ([]UserUsage, error)
+	PurgeUserData(ctx context.Context, userID string) (*PurgeResult, error)
+}
+
+// RateLimiter defines the port for token bucket rate limiting
+type RateLimiter interface {
+	// Allow takes a token from the bucket identified by key, creating it full if needed
+	Allow(ctx context.Context, key string, limit RateLimit) (*RateLimitResult, error)
+}
+
+// AIUsageRecorder receives the token usage of every AI provider call
+type AIUsageRecorder interface {
+	RecordAIUsage(ctx context.Context, usage AIUsage) error
+}
+
+// AIQuotaRepository defines the port for monthly AI token counters
+type AIQuotaRepository interface {
+	// AddAITokens adds tokens to the user's counter for period (YYYY-MM)
+	AddAITokens(ctx context.Context, userID, period string, tokens int) error
+	GetAITokens(ctx context.Context, userID, period string) (int, error)
+}
+
+// AIQuotaService defines the port for monthly AI token quota enforcement
+type AIQuotaService interface {
+	AIUsageRecorder
+	GetQuotaStatus(ctx context.Context, userID string) (*AIQuotaStatus, error)
+	// CheckQuota returns a *QuotaExceededError when the user has no tokens left this month
+	CheckQuota(ctx context.Context, userID string) error
+}
+
+// AIUsageRepository defines the port for per-call AI usage persistence
+type AIUsageRepository interface {
+	SaveAIUsage(ctx context.Context, usage *AIUsage) error
+	// GetAIUsageAggregates summarizes the matching calls by UTC day and model, ordered by day then model
+	GetAIUsageAggregates(ctx context.Context, filter AIUsageFilter) ([]AIUsageAggregate, error)
+}
+
+// AIUsageService defines the port for AI usage accounting
+type AIUsageService interface {
+	AIUsageRecorder
+	GetUsageReport(ctx context.Context, filter AIUsageFilter) (*AIUsageReport, error)
+}
+
+// TransactionMetrics defines the port for business metrics about saved transactions
+type TransactionMetrics interface {
+	TransactionsSaved(source TransactionSource, count int)
+}
+
+// RequestMetrics defines the port for HTTP request metrics
+type RequestMetrics interface {
+	// ObserveRequest records a completed request; route is the matched route pattern
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// HealthChecker defines the port for checking a dependency of the service
+type HealthChecker interface {
+	// Name identifies the dependency in health reports, e.g. "database"
+	Name() string
+	// Check returns an error when the dependency is unavailable
+	Check(ctx context.Context) error
+}
+
+// HealthService defines the port for readiness reporting
+type HealthService interface {
+	CheckReadiness(ctx context.Context) *HealthReport
 }
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimit configures a token bucket: Burst requests can be made at once and
+// the bucket refills at PerMinute requests per minute
+type RateLimit struct {
+	PerMinute int
+	Burst     int
+}
+
+// RatePerSecond returns how many tokens the bucket regains per second
+func (l RateLimit) RatePerSecond() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+// RateLimitResult reports the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until a token is available again when the request was not allowed
+	RetryAfter time.Duration
+}
+
+// AIQuotaStatus reports a user's AI token consumption for the current month
+type AIQuotaStatus struct {
+	Used int `json:"used"`
+	// Limit is the monthly token quota; 0 means unlimited
+	Limit    int       `json:"limit"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+// Exceeded reports whether the quota has been used up
+func (s *AIQuotaStatus) Exceeded() bool {
+	return s.Limit > 0 && s.Used >= s.Limit
+}
+
+// QuotaExceededError is returned when a user has used up their monthly AI token quota
+type QuotaExceededError struct {
+	Status AIQuotaStatus
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("monthly AI token quota of %d exceeded; resets at %s", e.Status.Limit, e.Status.ResetsAt.Format(time.RFC3339))
+}
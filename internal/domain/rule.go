@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RuleField represents the transaction attribute inspected by a rule condition
+type RuleField string
+
+const (
+	RuleFieldDescription RuleField = "description"
+	RuleFieldAmount      RuleField = "amount"
+	RuleFieldCurrency    RuleField = "currency"
+	RuleFieldCategory    RuleField = "category"
+	RuleFieldType        RuleField = "type"
+	RuleFieldAccount     RuleField = "account"
+)
+
+// RuleOperator represents the comparison performed by a rule condition
+type RuleOperator string
+
+const (
+	// Text operators (case-insensitive)
+	OperatorEquals     RuleOperator = "equals"
+	OperatorNotEquals  RuleOperator = "not_equals"
+	OperatorContains   RuleOperator = "contains"
+	OperatorStartsWith RuleOperator = "starts_with"
+
+	// Numeric operators (amount only)
+	OperatorGreaterThan    RuleOperator = "gt"
+	OperatorGreaterOrEqual RuleOperator = "gte"
+	OperatorLessThan       RuleOperator = "lt"
+	OperatorLessOrEqual    RuleOperator = "lte"
+)
+
+// RuleActionType represents the change a rule applies to a matching transaction
+type RuleActionType string
+
+const (
+	ActionSetCategory RuleActionType = "set_category"
+	ActionAddTag      RuleActionType = "add_tag"
+	ActionSetAccount  RuleActionType = "set_account"
+)
+
+// RuleCondition is a single predicate over a transaction field
+type RuleCondition struct {
+	Field    RuleField    `json:"field" binding:"required"`
+	Operator RuleOperator `json:"operator" binding:"required"`
+	Value    string       `json:"value"`
+}
+
+// RuleAction is a single change applied when all conditions of a rule match
+type RuleAction struct {
+	Type  RuleActionType `json:"type" binding:"required"`
+	Value string         `json:"value" binding:"required"`
+}
+
+// Rule represents a user-defined auto-categorization rule. A rule matches a
+// transaction when all of its conditions hold, and then applies its actions in order.
+type Rule struct {
+	ID         int             `json:"id"`
+	UserID     string          `json:"-"`
+	Name       string          `json:"name"`
+	Position   int             `json:"position"`
+	Enabled    bool            `json:"enabled"`
+	Conditions []RuleCondition `json:"conditions"`
+	Actions    []RuleAction    `json:"actions"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// RuleRequest represents the request for creating or updating a rule
+type RuleRequest struct {
+	Name       string          `json:"name" binding:"required"`
+	Position   int             `json:"position"`
+	Enabled    *bool           `json:"enabled"`
+	Conditions []RuleCondition `json:"conditions" binding:"required,min=1,dive"`
+	Actions    []RuleAction    `json:"actions" binding:"required,min=1,dive"`
+}
+
+// RuleListResponse lists a user's rules
+type RuleListResponse struct {
+	Rules []Rule `json:"rules"`
+}
+
+// RuleTestMatch describes how a rule would change an existing transaction
+type RuleTestMatch struct {
+	Before Transaction `json:"before"`
+	After  Transaction `json:"after"`
+}
+
+// RuleTestResult represents the outcome of dry-running a rule over existing transactions
+type RuleTestResult struct {
+	Scanned int             `json:"scanned"`
+	Matched int             `json:"matched"`
+	Matches []RuleTestMatch `json:"matches"`
+}
+
+// Validate checks that every condition and action of the rule is well formed
+func (r *Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("rule must have at least one condition")
+	}
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("rule must have at least one action")
+	}
+
+	for i, condition := range r.Conditions {
+		if err := condition.validate(); err != nil {
+			return fmt.Errorf("condition %d: %w", i, err)
+		}
+	}
+
+	for i, action := range r.Actions {
+		if err := action.validate(); err != nil {
+			return fmt.Errorf("action %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether all conditions of the rule hold for the transaction
+func (r *Rule) Matches(t Transaction) bool {
+	for _, condition := range r.Conditions {
+		if !condition.matches(t) {
+			return false
+		}
+	}
+	return len(r.Conditions) > 0
+}
+
+// Apply runs the rule actions against the transaction if the rule matches.
+// It reports whether the rule matched.
+func (r *Rule) Apply(t *Transaction) bool {
+	if !r.Matches(*t) {
+		return false
+	}
+
+	for _, action := range r.Actions {
+		action.apply(t)
+	}
+
+	return true
+}
+
+func (c RuleCondition) validate() error {
+	switch c.Field {
+	case RuleFieldAmount:
+		switch c.Operator {
+		case OperatorEquals, OperatorNotEquals, OperatorGreaterThan, OperatorGreaterOrEqual, OperatorLessThan, OperatorLessOrEqual:
+		default:
+			return fmt.Errorf("operator %q is not supported for field %q", c.Operator, c.Field)
+		}
+		if _, err := strconv.ParseFloat(c.Value, 64); err != nil {
+			return fmt.Errorf("value %q is not a valid amount", c.Value)
+		}
+	case RuleFieldDescription, RuleFieldCurrency, RuleFieldCategory, RuleFieldType, RuleFieldAccount:
+		switch c.Operator {
+		case OperatorEquals, OperatorNotEquals, OperatorContains, OperatorStartsWith:
+		default:
+			return fmt.Errorf("operator %q is not supported for field %q", c.Operator, c.Field)
+		}
+	default:
+		return fmt.Errorf("unknown field %q", c.Field)
+	}
+
+	return nil
+}
+
+func (c RuleCondition) matches(t Transaction) bool {
+	if c.Field == RuleFieldAmount {
+		value, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return false
+		}
+
+		switch c.Operator {
+		case OperatorEquals:
+			return t.Amount == value
+		case OperatorNotEquals:
+			return t.Amount != value
+		case OperatorGreaterThan:
+			return t.Amount > value
+		case OperatorGreaterOrEqual:
+			return t.Amount >= value
+		case OperatorLessThan:
+			return t.Amount < value
+		case OperatorLessOrEqual:
+			return t.Amount <= value
+		}
+		return false
+	}
+
+	var actual string
+	switch c.Field {
+	case RuleFieldDescription:
+		actual = t.Description
+	case RuleFieldCurrency:
+		actual = t.Currency
+	case RuleFieldCategory:
+		actual = string(t.Category)
+	case RuleFieldType:
+		actual = string(t.Type)
+	case RuleFieldAccount:
+		actual = t.Account
+	default:
+		return false
+	}
+
+	actual = strings.ToLower(actual)
+	expected := strings.ToLower(c.Value)
+
+	switch c.Operator {
+	case OperatorEquals:
+		return actual == expected
+	case OperatorNotEquals:
+		return actual != expected
+	case OperatorContains:
+		return strings.Contains(actual, expected)
+	case OperatorStartsWith:
+		return strings.HasPrefix(actual, expected)
+	}
+
+	return false
+}
+
+func (a RuleAction) validate() error {
+	switch a.Type {
+	case ActionSetCategory, ActionAddTag, ActionSetAccount:
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+
+	if strings.TrimSpace(a.Value) == "" {
+		return fmt.Errorf("action %q requires a value", a.Type)
+	}
+	if a.Type == ActionSetCategory && !Category(a.Value).IsValid() {
+		return fmt.Errorf("value %q is not a valid category", a.Value)
+	}
+
+	return nil
+}
+
+func (a RuleAction) apply(t *Transaction) {
+	switch a.Type {
+	case ActionSetCategory:
+		t.Category = Category(a.Value)
+	case ActionSetAccount:
+		t.Account = a.Value
+	case ActionAddTag:
+		for _, tag := range t.Tags {
+			if tag == a.Value {
+				return
+			}
+		}
+		// Copy before appending so callers holding the original slice are unaffected
+		t.Tags = append(append([]string(nil), t.Tags...), a.Value)
+	}
+}
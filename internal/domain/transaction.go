@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"context"
+	"slices"
 	"time"
 )
 
@@ -33,6 +35,18 @@ const (
 	CategoryBonus       Category = "bonus"
 )
 
+// ValidCategories are the categories a transaction can have
+var ValidCategories = []Category{
+	CategoryFood, CategoryTransport, CategoryUtilities, CategoryShopping,
+	CategoryHealth, CategoryEducation, CategoryEntertainment, CategoryOther,
+	CategorySalary, CategoryFreelance, CategoryInvestments, CategoryBonus,
+}
+
+// IsValid reports whether c is one of the predefined categories
+func (c Category) IsValid() bool {
+	return slices.Contains(ValidCategories, c)
+}
+
 // Transaction represents a financial transaction
 type Transaction struct {
 	ID          int             `json:"id"`
@@ -42,17 +56,34 @@ type Transaction struct {
 	Type        TransactionType `json:"type"`
 	Date        time.Time       `json:"date"`
 	Description string          `json:"description,omitempty"`
+	Account     string          `json:"account,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	// UserID records who created the transaction; empty for transactions saved before ownership was tracked
+	UserID string `json:"-"`
+	// CreatedAt is when the transaction was saved, set by the repository
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is when the transaction last changed, set by the repository. Updates and
+	// deletes given a non-zero UpdatedAt only apply if the stored transaction has not changed
+	// since, and fail with ErrPreconditionFailed otherwise.
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is when the transaction was moved to the trash; nil for live transactions.
+	// Trashed transactions are hidden from every read but the trash until restored or purged.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // ParseInputRequest represents the request for parsing natural language input
 type ParseInputRequest struct {
-	Text string `json:"text" binding:"required"`
+	Text        string          `json:"text" binding:"required"`
+	OnDuplicate DuplicatePolicy `json:"on_duplicate" binding:"omitempty,oneof=skip merge force"`
 }
 
 // ParseInputResponse represents the response after parsing input
 type ParseInputResponse struct {
-	Transactions []Transaction `json:"transactions"`
-	Message      string        `json:"message,omitempty"`
+	Transactions []Transaction    `json:"transactions"`
+	Duplicates   []DuplicateMatch `json:"duplicates,omitempty"`
+	Message      string           `json:"message,omitempty"`
+	// OperationID identifies the saved transactions for POST /operations/:id/undo
+	OperationID string `json:"operation_id,omitempty"`
 }
 
 // UpdateTransactionRequest represents the request for updating a transaction
@@ -63,12 +94,59 @@ type UpdateTransactionRequest struct {
 	Type        TransactionType `json:"type" binding:"required"`
 	Date        time.Time       `json:"date" binding:"required"`
 	Description string          `json:"description"`
+	Account     string          `json:"account"`
+	Tags        []string        `json:"tags"`
+}
+
+// CreateTransactionsRequest represents the request for creating transactions without the AI
+type CreateTransactionsRequest struct {
+	Transactions []UpdateTransactionRequest `json:"transactions" binding:"required,min=1,max=1000,dive"`
+}
+
+// CreateTransactionsResponse lists the created transactions, in request order
+type CreateTransactionsResponse struct {
+	Transactions []Transaction `json:"transactions"`
+	// OperationID identifies the created transactions for POST /operations/:id/undo
+	OperationID string `json:"operation_id,omitempty"`
+}
+
+// TransactionListResponse is a page of transactions
+type TransactionListResponse struct {
+	Transactions []Transaction `json:"transactions"`
+	Limit        int           `json:"limit"`
+	Offset       int           `json:"offset"`
+}
+
+// MessageResponse confirms an operation that returns no resource
+type MessageResponse struct {
+	Message string `json:"message"`
 }
 
 // AuthUser represents an authenticated user
 type AuthUser struct {
 	ID    string `json:"id"`
 	Email string `json:"email"`
+	// Role is the Postgres role from the JWT role claim, e.g. authenticated or service_role
+	Role string `json:"role,omitempty"`
+	// Roles are the application roles granted in the token's app_metadata, e.g. admin
+	Roles []string `json:"roles,omitempty"`
+	// Scopes limits what an API key may do; nil for session tokens, which may do anything
+	Scopes []Scope `json:"scopes,omitempty"`
+	// APIKeyID identifies the API key the user authenticated with; zero for session tokens
+	APIKeyID int `json:"api_key_id,omitempty"`
+}
+
+// HasScope reports whether the user's credential grants scope
+func (u *AuthUser) HasScope(scope Scope) bool {
+	if u.Scopes == nil {
+		return true
+	}
+	for _, granted := range u.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // ContextKey represents the type for context keys
@@ -77,4 +155,20 @@ type ContextKey string
 const (
 	// UserIDKey is the context key for storing user ID
 	UserIDKey ContextKey = "userID"
+	// AuthUserKey is the context key for storing the authenticated user
+	AuthUserKey ContextKey = "authUser"
+	// RequestIDKey is the context key for storing the ID of the current request
+	RequestIDKey ContextKey = "requestID"
+	// TransactionSourceKey is the context key for storing where transaction changes come from
+	TransactionSourceKey ContextKey = "transactionSource"
+	// OperationIDKey is the context key for storing the operation transaction changes belong to
+	OperationIDKey ContextKey = "operationID"
+	// RevertedOperationIDKey is the context key for storing the operation being undone
+	RevertedOperationIDKey ContextKey = "revertedOperationID"
 )
+
+// UserIDFromContext returns the authenticated user ID stored in the context, if any
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(UserIDKey).(string)
+	return userID, ok && userID != ""
+}
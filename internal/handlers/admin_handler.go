@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// AdminHandler handles administrative HTTP requests spanning all users
+type AdminHandler struct {
+	adminService domain.AdminService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService domain.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// GetUsersUsage handles GET /admin/users/usage
+func (h *AdminHandler) GetUsersUsage(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	usage, err := h.adminService.GetUsersUsage(c.Request.Context(), limit, offset)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to get usage: %w", err))
+		return
+	}
+
+	if usage == nil {
+		usage = []domain.UserUsage{}
+	}
+
+	c.JSON(http.StatusOK, domain.UserUsageListResponse{
+		Users:  usage,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// PurgeUserData handles DELETE /admin/users/:userID/data
+func (h *AdminHandler) PurgeUserData(c *gin.Context) {
+	result, err := h.adminService.PurgeUserData(c.Request.Context(), c.Param("userID"))
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to purge user data: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SetupRoutes sets up the HTTP routes
+func (h *AdminHandler) SetupRoutes(router gin.IRouter) {
+	admin := router.Group("/admin")
+
+	admin.GET("/users/usage", RequirePermission(domain.PermissionViewUsage), h.GetUsersUsage)
+	admin.DELETE("/users/:userID/data", RequirePermission(domain.PermissionPurgeUserData), h.PurgeUserData)
+}
+
+// DescribeRoutes adds the routes of SetupRoutes to the OpenAPI document
+func (h *AdminHandler) DescribeRoutes(doc *openapi.Document) {
+	adminRoute(doc, http.MethodGet, "/admin/users/usage", domain.PermissionViewUsage).
+		Summary("List stored data per user").
+		Query("limit", openapi.Integer().WithMinimum(1).WithDefault(50), "Page size").
+		Query("offset", openapi.Integer().WithMinimum(0).WithDefault(0), "Number of users to skip").
+		Response(http.StatusOK, "A page of per-user usage", domain.UserUsageListResponse{}).
+		ResponseRefs(http.StatusBadRequest)
+
+	adminRoute(doc, http.MethodDelete, "/admin/users/:userID/data", domain.PermissionPurgeUserData).
+		Summary("Purge a user's data").
+		PathParam("userID", openapi.String(), "ID of the user whose data is deleted").
+		Response(http.StatusOK, "Counts of the deleted records", domain.PurgeResult{}).
+		ResponseRefs(http.StatusBadRequest)
+}
@@ -0,0 +1,57 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+func TestAdminEndpoints(t *testing.T) {
+	s := newTestServer(t)
+	s.ai.OnText("coffee 45", testutil.AIResponse{Transactions: []domain.Transaction{coffee()}})
+	expectStatus(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"}), http.StatusOK)
+
+	admin := []string{"Authorization", "Bearer " + testutil.MintJWT(t, "admin-1", testutil.TokenOptions{AppRoles: []string{domain.RoleAdmin}})}
+	serviceRole := []string{"Authorization", "Bearer " + testutil.MintJWT(t, "service", testutil.TokenOptions{Role: domain.RoleServiceRole})}
+
+	t.Run("requires an admin role", func(t *testing.T) {
+		expectStatus(t, s.do(t, http.MethodGet, "/admin/users/usage", nil), http.StatusForbidden)
+		expectStatus(t, s.do(t, http.MethodDelete, "/admin/users/"+testUserID+"/data", nil), http.StatusForbidden)
+
+		key := s.createAPIKey(t, domain.CreateAPIKeyRequest{Name: "cron", Scopes: []domain.Scope{domain.ScopeTransactionsRead}})
+		expectStatus(t, s.do(t, http.MethodGet, "/admin/users/usage", nil, "Authorization", "Bearer "+key.Key), http.StatusForbidden)
+	})
+
+	t.Run("lists usage per user", func(t *testing.T) {
+		for _, auth := range [][]string{admin, serviceRole} {
+			rec := s.do(t, http.MethodGet, "/admin/users/usage", nil, auth...)
+			expectStatus(t, rec, http.StatusOK)
+
+			response := decode[struct {
+				Users []domain.UserUsage `json:"users"`
+			}](t, rec)
+			if len(response.Users) != 1 || response.Users[0].UserID != testUserID ||
+				response.Users[0].TransactionCount != 1 || response.Users[0].APIKeyCount != 1 {
+				t.Fatalf("unexpected usage: %+v", response.Users)
+			}
+		}
+	})
+
+	t.Run("purges a user's data", func(t *testing.T) {
+		rec := s.do(t, http.MethodDelete, "/admin/users/"+testUserID+"/data", nil, admin...)
+		expectStatus(t, rec, http.StatusOK)
+
+		result := decode[domain.PurgeResult](t, rec)
+		if result.Transactions != 1 || result.APIKeys != 1 {
+			t.Fatalf("unexpected purge result: %+v", result)
+		}
+
+		stored, _ := s.repo.GetTransactions(context.Background(), testUserID, 10, 0)
+		if len(stored) != 0 {
+			t.Errorf("expected transactions to be purged, got %d", len(stored))
+		}
+	})
+}
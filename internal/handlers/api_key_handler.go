@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// APIKeyHandler handles HTTP requests related to personal API keys
+type APIKeyHandler struct {
+	apiKeyService domain.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService domain.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKey handles POST /api-keys. The secret is only returned in this response.
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var request domain.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, invalidBodyError(err))
+		return
+	}
+
+	key := &domain.APIKey{
+		UserID: userID,
+		Name:   request.Name,
+		Scopes: request.Scopes,
+	}
+	if request.ExpiresAt != nil {
+		expiresAt := request.ExpiresAt.UTC()
+		key.ExpiresAt = &expiresAt
+	}
+
+	secret, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), key)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to create API key: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain.CreateAPIKeyResponse{
+		APIKey: *key,
+		Key:    secret,
+	})
+}
+
+// GetAPIKeys handles GET /api-keys
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	keys, err := h.apiKeyService.GetAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to get API keys: %w", err))
+		return
+	}
+
+	if keys == nil {
+		keys = []domain.APIKey{}
+	}
+
+	c.JSON(http.StatusOK, domain.APIKeyListResponse{
+		APIKeys: keys,
+	})
+}
+
+// DeleteAPIKey handles DELETE /api-keys/:id, revoking the key immediately
+func (h *APIKeyHandler) DeleteAPIKey(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, domain.NewValidationError("invalid API key ID"))
+		return
+	}
+
+	if err := h.apiKeyService.DeleteAPIKey(c.Request.Context(), userID, id); err != nil {
+		abortWithError(c, fmt.Errorf("failed to delete API key: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.MessageResponse{
+		Message: "API key deleted successfully",
+	})
+}
+
+// SetupRoutes sets up the HTTP routes. API keys cannot manage API keys.
+func (h *APIKeyHandler) SetupRoutes(router gin.IRouter) {
+	manage := RequireScope(domain.ScopeAPIKeysManage)
+
+	router.GET("/api-keys", manage, h.GetAPIKeys)
+	router.POST("/api-keys", manage, h.CreateAPIKey)
+	router.DELETE("/api-keys/:id", manage, h.DeleteAPIKey)
+}
+
+// DescribeRoutes adds the routes of SetupRoutes to the OpenAPI document
+func (h *APIKeyHandler) DescribeRoutes(doc *openapi.Document) {
+	manage := domain.ScopeAPIKeysManage
+
+	protectedRoute(doc, http.MethodGet, "/api-keys", manage).
+		Summary("List API keys").
+		Tags("api-keys").
+		Response(http.StatusOK, "The caller's API keys, without their secrets", domain.APIKeyListResponse{})
+
+	protectedRoute(doc, http.MethodPost, "/api-keys", manage).
+		Summary("Create an API key").
+		Description("The secret is only returned in this response.").
+		Tags("api-keys").
+		Body(domain.CreateAPIKeyRequest{}).
+		Response(http.StatusCreated, "The created API key and its secret", domain.CreateAPIKeyResponse{}).
+		ResponseRefs(http.StatusBadRequest)
+
+	protectedRoute(doc, http.MethodDelete, "/api-keys/:id", manage).
+		Summary("Revoke an API key").
+		Tags("api-keys").
+		PathParam("id", idParam(), "API key ID").
+		Response(http.StatusOK, "The API key was revoked", domain.MessageResponse{}).
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+}
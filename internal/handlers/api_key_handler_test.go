@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
+)
+
+// createAPIKey creates a key through the API using the session token
+func (s *testServer) createAPIKey(t *testing.T, request domain.CreateAPIKeyRequest) domain.CreateAPIKeyResponse {
+	t.Helper()
+	rec := s.do(t, http.MethodPost, "/api-keys", request)
+	expectStatus(t, rec, http.StatusCreated)
+	return decode[domain.CreateAPIKeyResponse](t, rec)
+}
+
+func TestAPIKeys(t *testing.T) {
+	t.Run("authenticates as the key owner", func(t *testing.T) {
+		s := newTestServer(t)
+		stored := s.seed(t, coffee())
+		created := s.createAPIKey(t, domain.CreateAPIKeyRequest{
+			Name:   "cron",
+			Scopes: []domain.Scope{domain.ScopeTransactionsRead},
+		})
+		if created.Key == "" || created.Prefix == "" || created.Key[:len(created.Prefix)] != created.Prefix {
+			t.Fatalf("unexpected key: %+v", created)
+		}
+
+		path := fmt.Sprintf("/transactions/%d", stored[0].ID)
+		expectStatus(t, s.do(t, http.MethodGet, path, nil, "Authorization", "Bearer "+created.Key), http.StatusOK)
+		expectStatus(t, s.do(t, http.MethodGet, path, nil, "Authorization", "", handlers.APIKeyHeader, created.Key), http.StatusOK)
+
+		rec := s.do(t, http.MethodGet, "/api-keys", nil)
+		expectStatus(t, rec, http.StatusOK)
+		list := decode[struct {
+			APIKeys []map[string]any `json:"api_keys"`
+		}](t, rec)
+		if len(list.APIKeys) != 1 || list.APIKeys[0]["last_used_at"] == nil {
+			t.Fatalf("expected one key with last-used tracking, got %+v", list.APIKeys)
+		}
+		if _, leaked := list.APIKeys[0]["key"]; leaked {
+			t.Error("listing must not return the key secret")
+		}
+	})
+
+	t.Run("enforces scopes", func(t *testing.T) {
+		s := newTestServer(t)
+		stored := s.seed(t, coffee())
+		readOnly := s.createAPIKey(t, domain.CreateAPIKeyRequest{
+			Name:   "spreadsheet",
+			Scopes: []domain.Scope{domain.ScopeTransactionsRead},
+		})
+		auth := []string{"Authorization", "Bearer " + readOnly.Key}
+
+		expectStatus(t, s.do(t, http.MethodGet, "/transactions", nil, auth...), http.StatusOK)
+		expectStatus(t, s.do(t, http.MethodDelete, fmt.Sprintf("/transactions/%d", stored[0].ID), nil, auth...), http.StatusForbidden)
+		expectStatus(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee"}, auth...), http.StatusForbidden)
+
+		// API keys can never manage API keys, whatever their scopes
+		expectStatus(t, s.do(t, http.MethodGet, "/api-keys", nil, auth...), http.StatusForbidden)
+	})
+
+	t.Run("rejects invalid, expired and revoked keys", func(t *testing.T) {
+		s := newTestServer(t)
+		key := s.createAPIKey(t, domain.CreateAPIKeyRequest{
+			Name:   "shortcut",
+			Scopes: []domain.Scope{domain.ScopeTransactionsRead},
+		})
+
+		expectStatus(t, s.do(t, http.MethodGet, "/transactions", nil, "Authorization", "Bearer "+key.Key+"x"), http.StatusUnauthorized)
+		expectStatus(t, s.do(t, http.MethodGet, "/transactions", nil, "Authorization", "Bearer etk_unknown_secret"), http.StatusUnauthorized)
+
+		expectStatus(t, s.do(t, http.MethodDelete, fmt.Sprintf("/api-keys/%d", key.ID), nil), http.StatusOK)
+		expectStatus(t, s.do(t, http.MethodGet, "/transactions", nil, "Authorization", "Bearer "+key.Key), http.StatusUnauthorized)
+		expectStatus(t, s.do(t, http.MethodDelete, fmt.Sprintf("/api-keys/%d", key.ID), nil), http.StatusNotFound)
+	})
+
+	t.Run("validates new keys", func(t *testing.T) {
+		s := newTestServer(t)
+		past := time.Now().Add(-time.Hour)
+
+		expectStatus(t, s.do(t, http.MethodPost, "/api-keys", `{"name":"x"}`), http.StatusBadRequest)
+		expectStatus(t, s.do(t, http.MethodPost, "/api-keys", domain.CreateAPIKeyRequest{
+			Name:   "admin",
+			Scopes: []domain.Scope{domain.ScopeAPIKeysManage},
+		}), http.StatusBadRequest)
+		expectStatus(t, s.do(t, http.MethodPost, "/api-keys", domain.CreateAPIKeyRequest{
+			Name:      "expired",
+			Scopes:    []domain.Scope{domain.ScopeTransactionsRead},
+			ExpiresAt: &past,
+		}), http.StatusBadRequest)
+	})
+}
@@ -1,72 +1,148 @@
 package handlers
 
 import (
-	"net/http"
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
 )
 
+// APIKeyHeader carries an API key for clients that cannot send it as a Bearer token
+const APIKeyHeader = "X-API-Key"
+
 // AuthMiddleware creates a middleware that validates authentication tokens
 type AuthMiddleware struct {
-	authService domain.AuthService
+	authService   domain.AuthService
+	apiKeyService domain.APIKeyService
 }
 
-// NewAuthMiddleware creates a new authentication middleware
-func NewAuthMiddleware(authService domain.AuthService) *AuthMiddleware {
+// NewAuthMiddleware creates a new authentication middleware.
+// API keys are rejected when apiKeyService is nil.
+func NewAuthMiddleware(authService domain.AuthService, apiKeyService domain.APIKeyService) *AuthMiddleware {
 	return &AuthMiddleware{
-		authService: authService,
+		authService:   authService,
+		apiKeyService: apiKeyService,
 	}
 }
 
-// Authenticate is the middleware function that validates JWT tokens
+// Authenticate is the middleware function that validates JWT tokens and API keys.
+// API keys are accepted as a Bearer token or in the X-API-Key header.
 func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+			m.authenticateAPIKey(c, apiKey)
+			return
+		}
+
 		// Get the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header is required",
-			})
-			c.Abort()
+			abortWithError(c, domain.NewUnauthorizedError("authorization header is required", nil))
 			return
 		}
 
 		// Check if it's a Bearer token
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header must be a Bearer token",
-			})
-			c.Abort()
+			abortWithError(c, domain.NewUnauthorizedError("authorization header must be a Bearer token", nil))
 			return
 		}
 
 		// Extract the token
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Token is required",
-			})
-			c.Abort()
+			abortWithError(c, domain.NewUnauthorizedError("token is required", nil))
+			return
+		}
+
+		if strings.HasPrefix(token, domain.APIKeyPrefix) {
+			m.authenticateAPIKey(c, token)
 			return
 		}
 
 		// Validate the token
 		user, err := m.authService.ValidateToken(c.Request.Context(), token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid or expired token",
-				"details": err.Error(),
-			})
-			c.Abort()
+			abortWithError(c, domain.NewUnauthorizedError("invalid or expired token", err))
 			return
 		}
 
-		// Store user ID in context for handlers to use
-		c.Set(string(domain.UserIDKey), user.ID)
+		setAuthUser(c, user)
 
 		// Continue to the next handler
 		c.Next()
 	}
 }
+
+// authenticateAPIKey validates an API key and continues the chain as its owner
+func (m *AuthMiddleware) authenticateAPIKey(c *gin.Context, apiKey string) {
+	if m.apiKeyService == nil {
+		abortWithError(c, domain.NewUnauthorizedError("API keys are not supported", nil))
+		return
+	}
+
+	user, err := m.apiKeyService.Authenticate(c.Request.Context(), apiKey)
+	if err != nil {
+		if !errors.Is(err, domain.ErrUnauthorized) {
+			err = fmt.Errorf("failed to authenticate API key: %w", err)
+		}
+		abortWithError(c, err)
+		return
+	}
+
+	setAuthUser(c, user)
+	c.Next()
+}
+
+// RequireScope rejects requests whose credential does not grant scope.
+// Session tokens grant every scope; API keys only the scopes they were created with.
+func RequireScope(scope domain.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get(string(domain.AuthUserKey))
+		user, ok := value.(*domain.AuthUser)
+		if !exists || !ok {
+			abortWithError(c, domain.NewUnauthorizedError("user authentication required", nil))
+			return
+		}
+
+		if !user.HasScope(scope) {
+			abortWithError(c, domain.NewForbiddenError("credential is missing scope "+string(scope)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission rejects requests from users whose roles do not grant permission
+func RequirePermission(permission domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get(string(domain.AuthUserKey))
+		user, ok := value.(*domain.AuthUser)
+		if !exists || !ok {
+			abortWithError(c, domain.NewUnauthorizedError("user authentication required", nil))
+			return
+		}
+
+		if !user.HasPermission(permission) {
+			abortWithError(c, domain.NewForbiddenError("requires permission "+string(permission)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setAuthUser stores the authenticated user in the Gin and request contexts for handlers and services to use
+func setAuthUser(c *gin.Context, user *domain.AuthUser) {
+	c.Set(string(domain.UserIDKey), user.ID)
+	c.Set(string(domain.AuthUserKey), user)
+
+	// Carry the user to services, and tag every later log line of the request with it
+	ctx := context.WithValue(c.Request.Context(), domain.UserIDKey, user.ID)
+	ctx = context.WithValue(ctx, domain.AuthUserKey, user)
+	c.Request = c.Request.WithContext(logging.With(ctx, "user_id", user.ID))
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// Headers of conditional requests
+const (
+	ETagHeader    = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+// transactionETag is the strong entity tag of a transaction's current version, derived
+// from its UpdatedAt
+func transactionETag(transaction *domain.Transaction) string {
+	return `"` + strconv.FormatInt(transaction.UpdatedAt.UnixMicro(), 10) + `"`
+}
+
+// ifMatchVersion returns the UpdatedAt a write is conditional on, from the If-Match
+// header. "*" matches any current version (RFC 9110) and yields the zero time, which
+// PUT, PATCH and DELETE treat alike: the write applies to the transaction as it is when
+// written, and only fails if it doesn't exist. A missing header fails with
+// ErrPreconditionRequired; a tag that cannot match, such as a weak one, with
+// ErrPreconditionFailed.
+func ifMatchVersion(c *gin.Context) (time.Time, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader(IfMatchHeader))
+	if ifMatch == "" {
+		return time.Time{}, domain.NewPreconditionRequiredError("the If-Match header is required; send the ETag of the transaction as last read")
+	}
+	return etagVersion(ifMatch)
+}
+
+// etagVersion returns the UpdatedAt identified by an If-Match value: a transaction ETag,
+// or "*" for any version, which yields the zero time
+func etagVersion(ifMatch string) (time.Time, error) {
+	if ifMatch == "*" {
+		return time.Time{}, nil
+	}
+
+	micros, err := strconv.ParseInt(strings.Trim(ifMatch, `"`), 10, 64)
+	if err != nil || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) {
+		return time.Time{}, domain.NewPreconditionFailedError("If-Match does not match the current ETag of the transaction")
+	}
+	return time.UnixMicro(micros).UTC(), nil
+}
+
+// conditionalWrite describes a write that requires If-Match
+func conditionalWrite(route *openapi.Route) *openapi.Route {
+	return route.
+		RequiredHeader(IfMatchHeader, openapi.String(), "ETag of the resource as last read, or * to match any current version").
+		ResponseRefs(http.StatusPreconditionFailed, http.StatusPreconditionRequired)
+}
+
+// applyMergePatch applies a JSON Merge Patch (RFC 7396) to the JSON encoding of target
+// and returns the patched document
+func applyMergePatch(target any, patch any) ([]byte, error) {
+	data, err := json.Marshal(target)
+	if err != nil {
+		return nil, err
+	}
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(document, patch))
+}
+
+// mergePatch implements the MergePatch function of RFC 7396 on decoded JSON values
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatch(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
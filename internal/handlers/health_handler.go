@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	healthService domain.HealthService
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(healthService domain.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// Health handles GET /health, kept for existing monitors; it does not check dependencies
+func (h *HealthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, domain.LivenessReport{
+		Status: "healthy",
+		Time:   time.Now().UTC(),
+	})
+}
+
+// Livez handles GET /livez. It only reports that the process is serving requests, so an
+// outage of a dependency does not get the instance restarted.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, domain.LivenessReport{
+		Status: domain.HealthStatusUp,
+		Time:   time.Now().UTC(),
+	})
+}
+
+// Readyz handles GET /readyz, responding 503 when a required dependency is down
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.healthService.CheckReadiness(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
+
+// SetupRoutes sets up the HTTP routes; they must not require authentication
+func (h *HealthHandler) SetupRoutes(router gin.IRouter) {
+	router.GET("/health", h.Health)
+	router.GET("/livez", h.Livez)
+	router.GET("/readyz", h.Readyz)
+}
+
+// DescribeRoutes adds the routes of SetupRoutes to the OpenAPI document
+func (h *HealthHandler) DescribeRoutes(doc *openapi.Document) {
+	doc.Route(http.MethodGet, "/health").
+		Summary("Health check").
+		Description("Kept for existing monitors; does not check dependencies.").
+		Tags("health").
+		Response(http.StatusOK, "The server is running", domain.LivenessReport{})
+
+	doc.Route(http.MethodGet, "/livez").
+		Summary("Liveness probe").
+		Tags("health").
+		Response(http.StatusOK, "The process is serving requests", domain.LivenessReport{})
+
+	doc.Route(http.MethodGet, "/readyz").
+		Summary("Readiness probe").
+		Description("Checks the database, the schema version and the AI provider.").
+		Tags("health").
+		Response(http.StatusOK, "Every required dependency is up", domain.HealthReport{}).
+		Response(http.StatusServiceUnavailable, "A required dependency is down", domain.HealthReport{})
+}
@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
+	"github.com/jairogloz/go-expense-tracker-back/internal/services"
+)
+
+// stubChecker is a health checker with a fixed outcome
+type stubChecker struct {
+	name string
+	err  error
+}
+
+func (c stubChecker) Name() string { return c.name }
+
+func (c stubChecker) Check(ctx context.Context) error { return c.err }
+
+func TestHealthEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	request := func(path string, checks ...services.HealthCheck) *httptest.ResponseRecorder {
+		router := gin.New()
+		handlers.NewHealthHandler(services.NewHealthService(time.Second, checks...)).SetupRoutes(router)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	down := errors.New("connection refused")
+	database := func(err error) services.HealthCheck {
+		return services.HealthCheck{Checker: stubChecker{name: "database", err: err}, Required: true}
+	}
+	openai := func(err error) services.HealthCheck {
+		return services.HealthCheck{Checker: stubChecker{name: "openai", err: err}}
+	}
+
+	t.Run("liveness ignores dependencies", func(t *testing.T) {
+		expectStatus(t, request("/livez", database(down)), http.StatusOK)
+		expectStatus(t, request("/health", database(down)), http.StatusOK)
+	})
+
+	t.Run("ready when every check passes", func(t *testing.T) {
+		rec := request("/readyz", database(nil), openai(nil))
+		expectStatus(t, rec, http.StatusOK)
+
+		report := decode[domain.HealthReport](t, rec)
+		if report.Status != domain.HealthStatusUp || len(report.Checks) != 2 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+		if report.Checks[0].Name != "database" || report.Checks[0].Status != domain.HealthStatusUp || !report.Checks[0].Required {
+			t.Errorf("unexpected database check: %+v", report.Checks[0])
+		}
+	})
+
+	t.Run("degraded when an optional dependency is down", func(t *testing.T) {
+		rec := request("/readyz", database(nil), openai(down))
+		expectStatus(t, rec, http.StatusOK)
+
+		report := decode[domain.HealthReport](t, rec)
+		if report.Status != domain.HealthStatusDegraded || report.Checks[1].Error != down.Error() {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("unavailable when a required dependency is down", func(t *testing.T) {
+		rec := request("/readyz", database(down), openai(nil))
+		expectStatus(t, rec, http.StatusServiceUnavailable)
+
+		report := decode[domain.HealthReport](t, rec)
+		if report.Status != domain.HealthStatusDown || report.Checks[0].Status != domain.HealthStatusDown {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client-chosen idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a stored idempotency record
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders are the response headers stored with an idempotent response and replayed with it
+var replayedHeaders = []string{ETagHeader, "Location", OperationIDHeader}
+
+// IdempotencyMiddleware replays the stored response of write requests retried with the same Idempotency-Key
+type IdempotencyMiddleware struct {
+	repo domain.IdempotencyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyMiddleware creates a new idempotency middleware. Keys older than ttl are forgotten.
+func NewIdempotencyMiddleware(repo domain.IdempotencyRepository, ttl time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+// Handle is the middleware function. It must run after Authenticate, since keys are scoped per user.
+func (m *IdempotencyMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isIdempotentMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, domain.NewValidationError("Idempotency-Key must be at most 255 characters"))
+			return
+		}
+
+		userID, err := getUserID(c)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, domain.NewValidationError("failed to read request body: "+err.Error()))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record := &domain.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.RequestURI(),
+			RequestHash: hashRequest(c.Request.Method, c.Request.URL.RequestURI(), body),
+		}
+
+		ctx := c.Request.Context()
+		created, err := m.reserve(ctx, record)
+		if err != nil {
+			abortWithError(c, fmt.Errorf("failed to process Idempotency-Key: %w", err))
+			return
+		}
+
+		if !created {
+			m.replay(c, record)
+			return
+		}
+
+		// The request context may be cancelled by the time the handler returns, but the key must
+		// still be released or completed
+		finalize := context.WithoutCancel(ctx)
+
+		// A panicking handler releases the key so the client can retry with it
+		finished := false
+		defer func() {
+			if !finished {
+				m.release(finalize, userID, key)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		finished = true
+
+		// Server errors are not stored so the client can retry with the same key
+		if recorder.Status() >= http.StatusInternalServerError {
+			m.release(finalize, userID, key)
+			return
+		}
+
+		record.StatusCode = recorder.Status()
+		record.ResponseBody = recorder.body.Bytes()
+		record.ResponseHeaders = map[string]string{}
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				record.ResponseHeaders[name] = value
+			}
+		}
+		if err := m.repo.CompleteIdempotencyRecord(finalize, record); err != nil {
+			logging.FromContext(ctx).Warn("failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// release forgets the user's idempotency key without storing a response
+func (m *IdempotencyMiddleware) release(ctx context.Context, userID, key string) {
+	if err := m.repo.DeleteIdempotencyRecord(ctx, userID, key); err != nil {
+		logging.FromContext(ctx).Warn("failed to release idempotency key", "error", err)
+	}
+}
+
+// reserve claims the idempotency key for this request, releasing it first if it has expired.
+// It reports false if the key is held by another request.
+func (m *IdempotencyMiddleware) reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	created, err := m.repo.CreateIdempotencyRecord(ctx, record)
+	if err != nil || created {
+		return created, err
+	}
+
+	existing, err := m.repo.GetIdempotencyRecord(ctx, record.UserID, record.Key)
+	if err != nil {
+		return false, err
+	}
+
+	if existing != nil && time.Since(existing.CreatedAt) <= m.ttl {
+		return false, nil
+	}
+
+	if err := m.repo.DeleteIdempotencyRecord(ctx, record.UserID, record.Key); err != nil {
+		return false, err
+	}
+
+	return m.repo.CreateIdempotencyRecord(ctx, record)
+}
+
+// replay answers a retried request with the stored response of the original one
+func (m *IdempotencyMiddleware) replay(c *gin.Context, record *domain.IdempotencyRecord) {
+	existing, err := m.repo.GetIdempotencyRecord(c.Request.Context(), record.UserID, record.Key)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to process Idempotency-Key: %w", err))
+		return
+	}
+
+	switch {
+	case existing == nil:
+		// Released by the original request after a server error; let the client retry
+		_ = c.Error(domain.NewConflictError("a request with this Idempotency-Key failed; please retry"))
+	case existing.RequestHash != record.RequestHash:
+		_ = c.Error(&domain.Error{
+			Kind:    errIdempotencyKeyReused,
+			Message: "Idempotency-Key was already used with a different request",
+		})
+	case !existing.Completed:
+		_ = c.Error(domain.NewConflictError("a request with this Idempotency-Key is still being processed"))
+	default:
+		for name, value := range existing.ResponseHeaders {
+			c.Header(name, value)
+		}
+		c.Header(IdempotentReplayedHeader, "true")
+		contentType := "application/json; charset=utf-8"
+		if existing.StatusCode >= http.StatusBadRequest {
+			contentType = ProblemContentType
+		}
+		c.Data(existing.StatusCode, contentType, existing.ResponseBody)
+	}
+
+	c.Abort()
+}
+
+// isIdempotentMethod reports whether requests with the method honor the Idempotency-Key header
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// hashRequest fingerprints a request so key reuse with a different request can be detected
+func hashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder captures the response body while still writing it to the client. Its
+// headers are those of the underlying writer.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
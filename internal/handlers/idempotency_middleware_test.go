@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
+	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+)
+
+// contextCheckingIdempotencyRepository fails like a database would when called with a cancelled context
+type contextCheckingIdempotencyRepository struct {
+	*infra.MemoryIdempotencyRepository
+}
+
+func (r contextCheckingIdempotencyRepository) CompleteIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.MemoryIdempotencyRepository.CompleteIdempotencyRecord(ctx, record)
+}
+
+func (r contextCheckingIdempotencyRepository) DeleteIdempotencyRecord(ctx context.Context, userID, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.MemoryIdempotencyRepository.DeleteIdempotencyRecord(ctx, userID, key)
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := contextCheckingIdempotencyRepository{infra.NewMemoryIdempotencyRepository()}
+	cancels := map[string]context.CancelFunc{}
+
+	router := gin.New()
+	requestLogger := handlers.NewRequestLogger(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	router.Use(requestLogger.Handle(), requestLogger.Recover())
+	router.Use(func(c *gin.Context) {
+		c.Set(string(domain.UserIDKey), "user-1")
+	})
+	router.Use(handlers.NewErrorMiddleware().Handle())
+	router.Use(handlers.NewIdempotencyMiddleware(repo, time.Hour).Handle())
+	// Each route cancels the request context before answering, as a disconnecting client would
+	router.POST("/created", func(c *gin.Context) {
+		cancels[c.GetHeader(handlers.IdempotencyKeyHeader)]()
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+	router.POST("/failed", func(c *gin.Context) {
+		cancels[c.GetHeader(handlers.IdempotencyKeyHeader)]()
+		c.Status(http.StatusServiceUnavailable)
+	})
+	router.POST("/panics", func(c *gin.Context) {
+		cancels[c.GetHeader(handlers.IdempotencyKeyHeader)]()
+		panic("boom")
+	})
+
+	request := func(path, key string) *httptest.ResponseRecorder {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cancels[key] = cancel
+
+		req := httptest.NewRequest(http.MethodPost, path, nil).WithContext(ctx)
+		req.Header.Set(handlers.IdempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	stored := func(key string) *domain.IdempotencyRecord {
+		record, err := repo.GetIdempotencyRecord(context.Background(), "user-1", key)
+		if err != nil {
+			t.Fatalf("GetIdempotencyRecord: %v", err)
+		}
+		return record
+	}
+
+	t.Run("stores the response of a cancelled request", func(t *testing.T) {
+		expectStatus(t, request("/created", "created"), http.StatusCreated)
+		if record := stored("created"); record == nil || !record.Completed || record.StatusCode != http.StatusCreated {
+			t.Fatalf("expected the response to be stored, got %+v", record)
+		}
+	})
+
+	t.Run("releases the key of a cancelled request failing", func(t *testing.T) {
+		expectStatus(t, request("/failed", "failed"), http.StatusServiceUnavailable)
+		if record := stored("failed"); record != nil {
+			t.Errorf("expected the key to be released, got %+v", record)
+		}
+	})
+
+	t.Run("releases the key of a panicking request", func(t *testing.T) {
+		expectStatus(t, request("/panics", "panics"), http.StatusInternalServerError)
+		if record := stored("panics"); record != nil {
+			t.Errorf("expected the key to be released, got %+v", record)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// unmatchedRoute labels requests that matched no route, keeping metric cardinality bounded
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records the duration and status of every request by route
+type MetricsMiddleware struct {
+	metrics domain.RequestMetrics
+}
+
+// NewMetricsMiddleware creates a new request metrics middleware
+func NewMetricsMiddleware(metrics domain.RequestMetrics) *MetricsMiddleware {
+	return &MetricsMiddleware{
+		metrics: metrics,
+	}
+}
+
+// Handle is the middleware function recording the metrics
+func (m *MetricsMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.metrics.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsHandler serves the Prometheus scrape endpoint
+type MetricsHandler struct {
+	handler http.Handler
+}
+
+// NewMetricsHandler creates a new metrics handler serving handler, e.g. PrometheusMetrics.Handler()
+func NewMetricsHandler(handler http.Handler) *MetricsHandler {
+	return &MetricsHandler{
+		handler: handler,
+	}
+}
+
+// SetupRoutes sets up the HTTP routes; restrict access to them at the network level
+func (h *MetricsHandler) SetupRoutes(router gin.IRouter) {
+	router.GET("/metrics", gin.WrapH(h.handler))
+}
+
+// DescribeRoutes adds the routes of SetupRoutes to the OpenAPI document
+func (h *MetricsHandler) DescribeRoutes(doc *openapi.Document) {
+	doc.Route(http.MethodGet, "/metrics").
+		Summary("Prometheus metrics").
+		Description("Unauthenticated; restrict access at the network level.").
+		Tags("meta").
+		ResponseContent(http.StatusOK, "Metrics in the Prometheus text format", "text/plain", openapi.String())
+}
@@ -0,0 +1,60 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	s := newTestServer(t)
+	s.ai.OnText("coffee 45", testutil.AIResponse{
+		Transactions:     []domain.Transaction{coffee()},
+		PromptTokens:     30,
+		CompletionTokens: 12,
+		TotalTokens:      42,
+	})
+	s.ai.OnText("garbled", testutil.AIResponse{Err: errors.New("upstream unavailable")})
+
+	expectStatus(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"}), http.StatusOK)
+	expectStatus(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "garbled"}), http.StatusInternalServerError)
+	expectStatus(t, s.do(t, http.MethodPost, "/transactions", domain.CreateTransactionsRequest{
+		Transactions: []domain.UpdateTransactionRequest{{Amount: 45, Currency: "MXN", Category: domain.CategoryFood, Type: domain.Expense, Date: testDate}},
+	}), http.StatusCreated)
+	expectStatus(t, s.do(t, http.MethodPost, "/transactions/bulk", `{"operations":[
+		{"action":"create","transaction":{"amount":12,"currency":"MXN","category":"transport","type":"expense","date":"2024-01-15T12:00:00Z"}},
+		{"action":"create","transaction":{"amount":15,"currency":"MXN","category":"transport","type":"expense","date":"2024-01-15T12:00:00Z"}}
+	]}`), http.StatusOK)
+	key := s.createAPIKey(t, domain.CreateAPIKeyRequest{Name: "sync", Scopes: []domain.Scope{domain.ScopeTransactionsWrite}})
+	expectStatus(t, s.do(t, http.MethodPost, "/transactions", domain.CreateTransactionsRequest{
+		Transactions: []domain.UpdateTransactionRequest{{Amount: 45, Currency: "MXN", Category: domain.CategoryFood, Type: domain.Expense, Date: testDate}},
+	}, "Authorization", "Bearer "+key.Key), http.StatusCreated)
+	expectStatus(t, s.do(t, http.MethodGet, "/transactions/999", nil), http.StatusNotFound)
+	expectStatus(t, s.do(t, http.MethodGet, "/no-such-route", nil), http.StatusNotFound)
+
+	rec := s.do(t, http.MethodGet, "/metrics", nil)
+	expectStatus(t, rec, http.StatusOK)
+	body := rec.Body.String()
+
+	for _, series := range []string{
+		`expense_tracker_http_request_duration_seconds_count{method="POST",route="/parse",status="200"} 1`,
+		`expense_tracker_http_request_duration_seconds_count{method="POST",route="/parse",status="500"} 1`,
+		`expense_tracker_http_request_duration_seconds_count{method="GET",route="/transactions/:id",status="404"} 1`,
+		`expense_tracker_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+		`expense_tracker_openai_calls_total{model="fake",outcome="success"} 1`,
+		`expense_tracker_openai_calls_total{model="fake",outcome="error"} 1`,
+		`expense_tracker_openai_tokens_total{kind="prompt",model="fake"} 30`,
+		`expense_tracker_openai_tokens_total{kind="completion",model="fake"} 12`,
+		`expense_tracker_transactions_saved_total{source="parse"} 1`,
+		`expense_tracker_transactions_saved_total{source="manual"} 3`,
+		`expense_tracker_transactions_saved_total{source="api_key"} 1`,
+	} {
+		if !strings.Contains(body, series+"\n") {
+			t.Errorf("expected series %s in:\n%s", series, body)
+		}
+	}
+}
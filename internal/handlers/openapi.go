@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// APIVersion is the version of the HTTP API reported in the OpenAPI document
+const APIVersion = "1.0.0"
+
+// Security schemes of the OpenAPI document
+const (
+	bearerAuthScheme = "bearerAuth"
+	apiKeyAuthScheme = "apiKeyAuth"
+)
+
+// problemStatuses are the error statuses described by shared problem responses
+var problemStatuses = []int{
+	http.StatusBadRequest,
+	http.StatusUnauthorized,
+	http.StatusForbidden,
+	http.StatusNotFound,
+	http.StatusConflict,
+	http.StatusPreconditionFailed,
+	http.StatusUnprocessableEntity,
+	http.StatusPreconditionRequired,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusServiceUnavailable,
+}
+
+// Routes is implemented by handlers that register routes and describe them in the
+// OpenAPI document
+type Routes interface {
+	SetupRoutes(router gin.IRouter)
+	DescribeRoutes(doc *openapi.Document)
+}
+
+// NewOpenAPIDocument describes the routes of the given handlers, deriving request and
+// response schemas from the domain types
+func NewOpenAPIDocument(routes ...Routes) *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "Expense Tracker API",
+		Version:     APIVersion,
+		Description: "Parses natural language expense descriptions into structured transactions.",
+	})
+
+	categories := make([]any, len(domain.ValidCategories))
+	for i, category := range domain.ValidCategories {
+		categories[i] = category
+	}
+	doc.Enum(categories...)
+	doc.Enum(domain.Income, domain.Expense)
+	doc.Enum(domain.DuplicatePolicySkip, domain.DuplicatePolicyMerge, domain.DuplicatePolicyForce)
+	doc.Enum(
+		domain.RuleFieldDescription, domain.RuleFieldAmount, domain.RuleFieldCurrency,
+		domain.RuleFieldCategory, domain.RuleFieldType, domain.RuleFieldAccount,
+	)
+	doc.Enum(
+		domain.OperatorEquals, domain.OperatorNotEquals, domain.OperatorContains, domain.OperatorStartsWith,
+		domain.OperatorGreaterThan, domain.OperatorGreaterOrEqual, domain.OperatorLessThan, domain.OperatorLessOrEqual,
+	)
+	doc.Enum(domain.ActionSetCategory, domain.ActionAddTag, domain.ActionSetAccount)
+	scopes := make([]any, 0, len(domain.ValidAPIKeyScopes)+1)
+	for _, scope := range domain.ValidAPIKeyScopes {
+		scopes = append(scopes, scope)
+	}
+	doc.Enum(append(scopes, domain.ScopeAPIKeysManage)...)
+
+	doc.Components.SecuritySchemes[bearerAuthScheme] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "Supabase access token, or an API key (etk_...) sent as a Bearer token",
+	}
+	doc.Components.SecuritySchemes[apiKeyAuthScheme] = &openapi.SecurityScheme{
+		Type:        "apiKey",
+		In:          "header",
+		Name:        APIKeyHeader,
+		Description: "API key created with POST /api-keys",
+	}
+
+	problem := doc.Schema(Problem{})
+	for _, status := range problemStatuses {
+		response := &openapi.Response{
+			Description: http.StatusText(status),
+			Content:     map[string]*openapi.MediaType{ProblemContentType: {Schema: problem}},
+		}
+		if status == http.StatusTooManyRequests {
+			response.Headers = map[string]*openapi.Header{
+				"Retry-After": {Description: "Seconds to wait before retrying", Schema: openapi.Integer()},
+			}
+		}
+		doc.Components.Responses[openapi.StatusName(status)] = response
+	}
+
+	doc.Route(http.MethodGet, "/openapi.json").
+		Summary("Get this OpenAPI document").
+		Tags("meta").
+		Response(http.StatusOK, "OpenAPI 3.1 document", map[string]any{})
+
+	for _, r := range routes {
+		r.DescribeRoutes(doc)
+	}
+
+	return doc
+}
+
+// protectedRoute describes a route behind Authenticate that requires scope. Write routes
+// accept an Idempotency-Key.
+func protectedRoute(doc *openapi.Document, method, path string, scope domain.Scope) *openapi.Route {
+	route := doc.Route(method, path).
+		Security(bearerAuthScheme, apiKeyAuthScheme).
+		Description("Requires the `"+string(scope)+"` scope.").
+		ResponseRefs(http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError)
+	return withIdempotencyKey(route, method)
+}
+
+// adminRoute describes a route behind Authenticate that requires permission
+func adminRoute(doc *openapi.Document, method, path string, permission domain.Permission) *openapi.Route {
+	route := doc.Route(method, path).
+		Tags("admin").
+		Security(bearerAuthScheme, apiKeyAuthScheme).
+		Description("Requires the `"+string(permission)+"` permission.").
+		ResponseRefs(http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError)
+	return withIdempotencyKey(route, method)
+}
+
+func withIdempotencyKey(route *openapi.Route, method string) *openapi.Route {
+	if !isIdempotentMethod(method) {
+		return route
+	}
+	maxLength := maxIdempotencyKeyLength
+	return route.
+		Header(IdempotencyKeyHeader, &openapi.Schema{Type: openapi.Types{"string"}, MaxLength: &maxLength},
+			"Replays the stored response when the request is retried with the same key").
+		ResponseRefs(http.StatusConflict, http.StatusUnprocessableEntity)
+}
+
+// idParam is the schema of integer IDs in paths
+func idParam() *openapi.Schema {
+	return openapi.Integer().WithFormat("int64")
+}
+
+// swaggerInitializer points the bundled Swagger UI at the served document
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// OpenAPIHandler serves the OpenAPI document and a bundled Swagger UI
+type OpenAPIHandler struct {
+	doc    *openapi.Document
+	assets http.Handler
+}
+
+// NewOpenAPIHandler creates a new OpenAPI handler serving doc
+func NewOpenAPIHandler(doc *openapi.Document) *OpenAPIHandler {
+	return &OpenAPIHandler{
+		doc:    doc,
+		assets: http.StripPrefix("/docs", http.FileServer(http.FS(swaggerFiles.FS))),
+	}
+}
+
+// Document returns the served OpenAPI document
+func (h *OpenAPIHandler) Document() *openapi.Document {
+	return h.doc
+}
+
+// GetDocument handles GET /openapi.json
+func (h *OpenAPIHandler) GetDocument(c *gin.Context) {
+	c.JSON(http.StatusOK, h.doc)
+}
+
+// GetDocs handles GET /docs/*filepath, serving the Swagger UI
+func (h *OpenAPIHandler) GetDocs(c *gin.Context) {
+	if c.Param("filepath") == "/swagger-initializer.js" {
+		c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(swaggerInitializer))
+		return
+	}
+	h.assets.ServeHTTP(c.Writer, c.Request)
+}
+
+// SetupRoutes sets up the HTTP routes; they must not require authentication
+func (h *OpenAPIHandler) SetupRoutes(router gin.IRouter) {
+	router.GET("/openapi.json", h.GetDocument)
+	router.GET("/docs/*filepath", h.GetDocs)
+}
@@ -0,0 +1,172 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// undocumentedRoutes are registered routes deliberately left out of the OpenAPI document
+var undocumentedRoutes = map[string]bool{
+	"GET /docs/*filepath": true,
+}
+
+// newDocumentedRouter registers every handler the server registers, without dependencies,
+// since registering and describing routes does not use them
+func newDocumentedRouter() (*gin.Engine, *openapi.Document) {
+	gin.SetMode(gin.TestMode)
+
+	routes := []handlers.Routes{
+		handlers.NewHealthHandler(nil),
+		handlers.NewMetricsHandler(http.NotFoundHandler()),
+		handlers.NewTransactionHandler(nil, nil, nil, nil),
+		handlers.NewOperationHandler(nil),
+		handlers.NewRuleHandler(nil),
+		handlers.NewAPIKeyHandler(nil),
+		handlers.NewAdminHandler(nil),
+		handlers.NewUsageHandler(nil),
+	}
+	doc := handlers.NewOpenAPIDocument(routes...)
+
+	router := gin.New()
+	handlers.NewOpenAPIHandler(doc).SetupRoutes(router)
+	for _, r := range routes {
+		r.SetupRoutes(router)
+	}
+	return router, doc
+}
+
+func TestOpenAPIDocumentCoversRoutes(t *testing.T) {
+	router, doc := newDocumentedRouter()
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if undocumentedRoutes[key] {
+			continue
+		}
+		if doc.Operation(route.Method, route.Path) == nil {
+			t.Errorf("route %s is missing from the OpenAPI document", key)
+		}
+	}
+
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			found := false
+			for _, route := range router.Routes() {
+				if strings.EqualFold(route.Method, method) && openapi.PathFromGin(route.Path) == path {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("documented operation %s %s is not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+func TestOpenAPIDocumentCategoryEnum(t *testing.T) {
+	_, doc := newDocumentedRouter()
+
+	category, ok := doc.Components.Schemas["Category"]
+	if !ok {
+		t.Fatal("expected a Category schema")
+	}
+	if len(category.Enum) != len(domain.ValidCategories) {
+		t.Fatalf("expected the enum to list every valid category, got %v", category.Enum)
+	}
+	for i, value := range category.Enum {
+		if value != domain.ValidCategories[i] {
+			t.Errorf("expected category %d to be %q, got %v", i, domain.ValidCategories[i], value)
+		}
+	}
+}
+
+func TestOpenAPIEndpoints(t *testing.T) {
+	router, _ := newDocumentedRouter()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("serves the document with resolvable references", func(t *testing.T) {
+		rec := get("/openapi.json")
+		expectStatus(t, rec, http.StatusOK)
+
+		var document map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &document); err != nil {
+			t.Fatalf("decode document: %v", err)
+		}
+		if document["openapi"] != openapi.Version {
+			t.Errorf("expected openapi %s, got %v", openapi.Version, document["openapi"])
+		}
+
+		var refs []string
+		collectRefs(document, &refs)
+		if len(refs) == 0 {
+			t.Fatal("expected the document to reference components")
+		}
+		for _, ref := range refs {
+			if !resolveRef(document, ref) {
+				t.Errorf("unresolved reference %s", ref)
+			}
+		}
+	})
+
+	t.Run("serves the Swagger UI", func(t *testing.T) {
+		rec := get("/docs/")
+		expectStatus(t, rec, http.StatusOK)
+		if !strings.Contains(rec.Body.String(), "swagger-ui") {
+			t.Errorf("expected the Swagger UI page, got %q", rec.Body.String())
+		}
+
+		rec = get("/docs/swagger-initializer.js")
+		expectStatus(t, rec, http.StatusOK)
+		if !strings.Contains(rec.Body.String(), `"/openapi.json"`) {
+			t.Errorf("expected the UI to load /openapi.json, got %q", rec.Body.String())
+		}
+	})
+}
+
+// collectRefs appends every $ref found in a decoded JSON value
+func collectRefs(value any, refs *[]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				*refs = append(*refs, ref)
+			}
+			collectRefs(child, refs)
+		}
+	case []any:
+		for _, child := range v {
+			collectRefs(child, refs)
+		}
+	}
+}
+
+// resolveRef reports whether a local reference such as #/components/schemas/Rule exists
+func resolveRef(document map[string]any, ref string) bool {
+	var current any = document
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return false
+		}
+		if current, ok = object[part]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// OperationIDHeader is set on responses to requests that change transactions, carrying
+// the ID to undo those changes with
+const OperationIDHeader = "Operation-ID"
+
+// startOperation groups the transaction changes made while handling c into a new
+// operation and returns its ID
+func startOperation(c *gin.Context) string {
+	id := domain.NewOperationID()
+	c.Request = c.Request.WithContext(domain.WithOperation(c.Request.Context(), id))
+	return id
+}
+
+// OperationHandler handles HTTP requests related to operations
+type OperationHandler struct {
+	operationService domain.OperationService
+}
+
+// NewOperationHandler creates a new operation handler
+func NewOperationHandler(operationService domain.OperationService) *OperationHandler {
+	return &OperationHandler{
+		operationService: operationService,
+	}
+}
+
+// UndoOperation handles POST /operations/:id/undo, reverting every transaction change
+// of the operation
+func (h *OperationHandler) UndoOperation(c *gin.Context) {
+	operation, err := h.operationService.UndoOperation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to undo operation: %w", err))
+		return
+	}
+
+	c.Header(OperationIDHeader, operation.ID)
+	c.JSON(http.StatusOK, operation)
+}
+
+// SetupRoutes sets up the HTTP routes
+func (h *OperationHandler) SetupRoutes(router gin.IRouter) {
+	router.POST("/operations/:id/undo", RequireScope(domain.ScopeTransactionsWrite), h.UndoOperation)
+}
+
+// DescribeRoutes adds the routes of SetupRoutes to the OpenAPI document
+func (h *OperationHandler) DescribeRoutes(doc *openapi.Document) {
+	protectedRoute(doc, http.MethodPost, "/operations/:id/undo", domain.ScopeTransactionsWrite).
+		Summary("Undo the transaction changes of a request").
+		Description("Reverts every change of the operation named by the `Operation-ID` of a previous response, all or nothing: created transactions are moved to the trash, and updated or deleted ones are restored as they were. "+
+			"Operations can only be undone once, within `OPERATION_UNDO_WINDOW` (default 15 minutes), and not after one of their transactions changed again. The undo is itself an operation.").
+		Tags("operations").
+		PathParam("id", openapi.String(), "Operation ID").
+		Response(http.StatusOK, "The changes made by the undo", domain.Operation{}).
+		ResponseHeader(http.StatusOK, OperationIDHeader, openapi.String(), "ID of the undo operation").
+		ResponseRefs(http.StatusNotFound, http.StatusConflict)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// Stable error codes clients can match on
+const (
+	CodeNotFound             = "not_found"
+	CodeValidation           = "validation_failed"
+	CodeConflict             = "conflict"
+	CodePreconditionFailed   = "precondition_failed"
+	CodePreconditionRequired = "precondition_required"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeRateLimited          = "rate_limited"
+	CodeQuotaExceeded        = "quota_exceeded"
+	CodeAIUnavailable        = "ai_unavailable"
+	CodeKeyReused            = "idempotency_key_reused"
+	CodeInternal             = "internal_error"
+)
+
+// errIdempotencyKeyReused is the kind of error for an Idempotency-Key sent with a different request
+var errIdempotencyKeyReused = errors.New("idempotency key reused")
+
+// Problem is an RFC 7807 problem details response, extended with a stable error code
+// and the request ID to quote when reporting the failure
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+
+	// Errors lists the invalid fields of a validation failure
+	Errors []domain.FieldError `json:"errors,omitempty"`
+}
+
+// problemKinds maps domain error kinds to their HTTP status and error code
+var problemKinds = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{domain.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{domain.ErrValidation, http.StatusBadRequest, CodeValidation},
+	{domain.ErrConflict, http.StatusConflict, CodeConflict},
+	{domain.ErrPreconditionFailed, http.StatusPreconditionFailed, CodePreconditionFailed},
+	{domain.ErrPreconditionRequired, http.StatusPreconditionRequired, CodePreconditionRequired},
+	{domain.ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized},
+	{domain.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{domain.ErrRateLimited, http.StatusTooManyRequests, CodeRateLimited},
+	{domain.ErrUpstreamAIUnavailable, http.StatusServiceUnavailable, CodeAIUnavailable},
+	{errIdempotencyKeyReused, http.StatusUnprocessableEntity, CodeKeyReused},
+}
+
+// ErrorMiddleware renders the errors handlers attach with c.Error as problem responses
+type ErrorMiddleware struct{}
+
+// NewErrorMiddleware creates a new error rendering middleware
+func NewErrorMiddleware() *ErrorMiddleware {
+	return &ErrorMiddleware{}
+}
+
+// Handle renders the last error of the request unless a response was already written.
+// Register it globally, to cover middleware failures, and again after any middleware that
+// records the response (such as idempotency), so those see the rendered problem.
+func (m *ErrorMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+		writeProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// abortWithError records err for the error middleware and stops the handler chain
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// writeProblem responds with the problem details of err. Errors of unknown kinds become
+// 500s whose detail does not leak the underlying error; it is logged instead.
+func writeProblem(c *gin.Context, err error) {
+	problem := Problem{
+		Type:      "about:blank",
+		Status:    http.StatusInternalServerError,
+		Code:      CodeInternal,
+		Detail:    "an unexpected error occurred",
+		Instance:  c.Request.URL.Path,
+		RequestID: c.Writer.Header().Get(RequestIDHeader),
+	}
+
+	var quotaErr *domain.QuotaExceededError
+	var domainErr *domain.Error
+	switch {
+	case errors.As(err, &quotaErr):
+		problem.Status = http.StatusTooManyRequests
+		problem.Code = CodeQuotaExceeded
+		problem.Detail = quotaErr.Error()
+		c.Header("Retry-After", retryAfterSeconds(time.Until(quotaErr.Status.ResetsAt)))
+	case errors.As(err, &domainErr):
+		for _, kind := range problemKinds {
+			if errors.Is(domainErr, kind.kind) {
+				problem.Status = kind.status
+				problem.Code = kind.code
+				problem.Detail = domainErr.Message
+				problem.Errors = domainErr.Fields
+				break
+			}
+		}
+	}
+	problem.Title = http.StatusText(problem.Status)
+
+	c.Render(problem.Status, problemRender{problem})
+}
+
+// problemRender writes a problem with the problem+json content type
+type problemRender struct {
+	problem Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	data, err := json.Marshal(r.problem)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ProblemContentType)
+}
+
+// invalidBodyError reports a request body that could not be bound
+func invalidBodyError(err error) error {
+	return domain.NewValidationError("invalid request body: " + err.Error())
+}
@@ -0,0 +1,128 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+func expectProblem(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) handlers.Problem {
+	t.Helper()
+	expectStatus(t, rec, status)
+	if contentType := rec.Header().Get("Content-Type"); contentType != handlers.ProblemContentType {
+		t.Errorf("expected content type %q, got %q", handlers.ProblemContentType, contentType)
+	}
+	problem := decode[handlers.Problem](t, rec)
+	if problem.Status != status || problem.Code != code || problem.Title != http.StatusText(status) {
+		t.Errorf("expected %d %s problem, got %+v", status, code, problem)
+	}
+	return problem
+}
+
+// expectFieldErrors checks for a validation problem listing exactly the given fields
+func expectFieldErrors(t *testing.T, rec *httptest.ResponseRecorder, in string, fields ...string) {
+	t.Helper()
+	problem := expectProblem(t, rec, http.StatusBadRequest, handlers.CodeValidation)
+
+	got := make(map[string]bool)
+	for _, e := range problem.Errors {
+		if e.In != in || e.Message == "" {
+			t.Errorf("unexpected field error %+v", e)
+		}
+		got[e.Field] = true
+	}
+	for _, field := range fields {
+		if !got[field] {
+			t.Errorf("expected an error for %s %s, got %+v", in, field, problem.Errors)
+		}
+	}
+	if len(got) != len(fields) {
+		t.Errorf("expected errors for %v, got %+v", fields, problem.Errors)
+	}
+}
+
+func TestProblemResponses(t *testing.T) {
+	t.Run("unauthenticated", func(t *testing.T) {
+		s := newTestServer(t)
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/transactions", nil))
+		expectProblem(t, rec, http.StatusUnauthorized, handlers.CodeUnauthorized)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		s := newTestServer(t)
+		problem := expectProblem(t, s.do(t, http.MethodDelete, "/transactions/999", nil, handlers.IfMatchHeader, "*"), http.StatusNotFound, handlers.CodeNotFound)
+		if problem.Detail != "transaction with id 999 not found" || problem.Instance != "/transactions/999" {
+			t.Errorf("unexpected problem: %+v", problem)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		s := newTestServer(t)
+		expectProblem(t, s.do(t, http.MethodGet, "/transactions/abc", nil), http.StatusBadRequest, handlers.CodeValidation)
+		expectProblem(t, s.do(t, http.MethodPost, "/parse", "{"), http.StatusBadRequest, handlers.CodeValidation)
+	})
+
+	t.Run("request validation lists invalid fields", func(t *testing.T) {
+		s := newTestServer(t)
+		stored := s.seed(t, coffee())
+		path := fmt.Sprintf("/transactions/%d", stored[0].ID)
+
+		body := `{"amount":10,"currency":"MXN","category":"groceries","type":"transfer","date":"2024-08-14T15:30:00Z"}`
+		expectFieldErrors(t, s.do(t, http.MethodPut, path, body), "body", "category", "type")
+		expectFieldErrors(t, s.do(t, http.MethodPut, path, `{"amount":"10","date":"yesterday"}`),
+			"body", "amount", "currency", "category", "type", "date")
+		expectFieldErrors(t, s.do(t, http.MethodGet, "/transactions/abc", nil), "path", "id")
+		expectFieldErrors(t, s.do(t, http.MethodGet, "/usage?from=yesterday", nil), "query", "from")
+
+		got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID)
+		if got.Category != domain.CategoryFood {
+			t.Errorf("expected the invalid update to be rejected, got %+v", got)
+		}
+	})
+
+	t.Run("AI unavailable hides the cause", func(t *testing.T) {
+		s := newTestServer(t)
+		s.ai.OnText("coffee 45", testutil.AIResponse{Err: domain.NewUpstreamAIError(errors.New("dial tcp: timeout"))})
+
+		problem := expectProblem(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"}),
+			http.StatusServiceUnavailable, handlers.CodeAIUnavailable)
+		if strings.Contains(problem.Detail, "timeout") {
+			t.Errorf("expected the cause to stay out of the response, got %q", problem.Detail)
+		}
+	})
+
+	t.Run("unknown errors are internal", func(t *testing.T) {
+		s := newTestServer(t)
+		s.ai.OnText("coffee 45", testutil.AIResponse{Err: errors.New("secret connection string")})
+
+		problem := expectProblem(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"}),
+			http.StatusInternalServerError, handlers.CodeInternal)
+		if strings.Contains(problem.Detail, "secret") {
+			t.Errorf("expected the error to stay out of the response, got %q", problem.Detail)
+		}
+	})
+
+	t.Run("replayed problems keep their content type", func(t *testing.T) {
+		s := newTestServer(t)
+		first := s.do(t, http.MethodDelete, "/transactions/999", nil, handlers.IdempotencyKeyHeader, "key-1", handlers.IfMatchHeader, "*")
+		expectProblem(t, first, http.StatusNotFound, handlers.CodeNotFound)
+
+		retry := s.do(t, http.MethodDelete, "/transactions/999", nil, handlers.IdempotencyKeyHeader, "key-1", handlers.IfMatchHeader, "*")
+		expectProblem(t, retry, http.StatusNotFound, handlers.CodeNotFound)
+		if retry.Header().Get(handlers.IdempotentReplayedHeader) != "true" {
+			t.Error("expected replayed response")
+		}
+
+		reused := s.do(t, http.MethodDelete, "/transactions/998", nil, handlers.IdempotencyKeyHeader, "key-1", handlers.IfMatchHeader, "*")
+		expectProblem(t, reused, http.StatusUnprocessableEntity, handlers.CodeKeyReused)
+	})
+}
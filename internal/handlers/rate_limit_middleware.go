@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+)
+
+// RateLimitMiddleware throttles requests per authenticated user with token buckets.
+// Routes listed in routeLimits get their own bucket; every other route shares the default one.
+type RateLimitMiddleware struct {
+	limiter      domain.RateLimiter
+	defaultLimit domain.RateLimit
+	routeLimits  map[string]domain.RateLimit
+}
+
+// NewRateLimitMiddleware creates a new rate limiting middleware. routeLimits is keyed by
+// route pattern as registered with Gin, e.g. "/parse".
+func NewRateLimitMiddleware(limiter domain.RateLimiter, defaultLimit domain.RateLimit, routeLimits map[string]domain.RateLimit) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		limiter:      limiter,
+		defaultLimit: defaultLimit,
+		routeLimits:  routeLimits,
+	}
+}
+
+// Handle is the middleware function enforcing the limits. It must run after authentication.
+func (m *RateLimitMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		bucket := "default"
+		limit := m.defaultLimit
+		if routeLimit, ok := m.routeLimits[c.FullPath()]; ok {
+			bucket = c.FullPath()
+			limit = routeLimit
+		}
+
+		result, err := m.limiter.Allow(c.Request.Context(), userID+":"+bucket, limit)
+		if err != nil {
+			// Fail open: an unavailable limiter store should not take the API down
+			logging.FromContext(c.Request.Context()).Warn("rate limiter unavailable; allowing request", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			c.Header("Retry-After", retryAfterSeconds(result.RetryAfter))
+			abortWithError(c, domain.NewRateLimitedError("rate limit exceeded; retry after "+result.RetryAfter.Round(time.Second).String()))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// retryAfterSeconds formats a wait as a Retry-After header value, rounding up to whole seconds
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(d.Seconds()))))
+}
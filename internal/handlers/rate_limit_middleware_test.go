@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
+	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	middleware := handlers.NewRateLimitMiddleware(infra.NewMemoryRateLimiter(),
+		domain.RateLimit{PerMinute: 60, Burst: 3},
+		map[string]domain.RateLimit{"/parse": {PerMinute: 1, Burst: 1}},
+	)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(domain.UserIDKey), c.GetHeader("X-User"))
+	})
+	router.Use(handlers.NewErrorMiddleware().Handle())
+	router.Use(middleware.Handle())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/parse", ok)
+	router.GET("/transactions", ok)
+
+	request := func(method, path, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("limits parse separately", func(t *testing.T) {
+		expectStatus(t, request(http.MethodPost, "/parse", "alice"), http.StatusOK)
+
+		rec := request(http.MethodPost, "/parse", "alice")
+		expectStatus(t, rec, http.StatusTooManyRequests)
+		if seconds, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || seconds < 1 || seconds > 60 {
+			t.Errorf("expected Retry-After within a minute, got %q", rec.Header().Get("Retry-After"))
+		}
+
+		// CRUD routes use their own bucket
+		expectStatus(t, request(http.MethodGet, "/transactions", "alice"), http.StatusOK)
+	})
+
+	t.Run("limits CRUD per user", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			rec := request(http.MethodGet, "/transactions", "bob")
+			expectStatus(t, rec, http.StatusOK)
+			if got, want := rec.Header().Get("X-RateLimit-Remaining"), strconv.Itoa(2-i); got != want {
+				t.Errorf("expected %s remaining, got %s", want, got)
+			}
+		}
+		expectStatus(t, request(http.MethodGet, "/transactions", "bob"), http.StatusTooManyRequests)
+		expectStatus(t, request(http.MethodGet, "/transactions", "carol"), http.StatusOK)
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the ID correlating a request with its log lines
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds incoming request IDs so clients cannot bloat log lines
+const maxRequestIDLength = 128
+
+// RequestLogger assigns each request an ID, attaches a request-scoped logger to its
+// context and writes one access log line when it completes
+type RequestLogger struct {
+	logger *slog.Logger
+}
+
+// NewRequestLogger creates a new request logging middleware
+func NewRequestLogger(logger *slog.Logger) *RequestLogger {
+	return &RequestLogger{
+		logger: logger,
+	}
+}
+
+// Handle is the middleware function. It should be registered right after tracing, so that
+// every other middleware and handler logs with the request and trace IDs.
+func (m *RequestLogger) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		logger := m.logger.With(
+			"request_id", requestID,
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+		)
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsValid() {
+			logger = logger.With("trace_id", spanContext.TraceID().String())
+		}
+		ctx := context.WithValue(c.Request.Context(), domain.RequestIDKey, requestID)
+		c.Request = c.Request.WithContext(logging.WithLogger(ctx, logger))
+
+		c.Next()
+
+		// Read the logger back: authentication adds the user ID to it
+		logger = logging.FromContext(c.Request.Context())
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "request completed", attrs...)
+	}
+}
+
+// Recover returns a middleware turning panics into 500 responses, logged with the
+// request's logger instead of gin's plain text recovery output
+func (m *RequestLogger) Recover() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logging.FromContext(c.Request.Context()).Error("panic recovered",
+			"panic", recovered,
+			"stack", string(debug.Stack()),
+		)
+		// Render here: the unwinding panic skips the error middleware
+		err := fmt.Errorf("panic: %v", recovered)
+		abortWithError(c, err)
+		writeProblem(c, err)
+	})
+}
+
+// validRequestID reports whether an incoming request ID is safe to echo and log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random 128-bit request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
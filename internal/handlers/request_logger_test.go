@@ -0,0 +1,103 @@
+package handlers_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
+	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	requestLogger := handlers.NewRequestLogger(logging.New(&logs, slog.LevelDebug))
+	authMiddleware := handlers.NewAuthMiddleware(infra.NewSupabaseAuthService(testutil.AuthConfig()), nil)
+
+	router := gin.New()
+	router.Use(requestLogger.Handle(), requestLogger.Recover())
+	router.Use(handlers.NewErrorMiddleware().Handle())
+	protected := router.Group("/")
+	protected.Use(authMiddleware.Authenticate())
+	protected.GET("/transactions/:id", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context()).Info("loading transaction")
+		c.Status(http.StatusOK)
+	})
+	protected.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	token := testutil.MintJWT(t, testUserID, testutil.TokenOptions{})
+	request := func(path, requestID string) *httptest.ResponseRecorder {
+		logs.Reset()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if requestID != "" {
+			req.Header.Set(handlers.RequestIDHeader, requestID)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	lines := func(t *testing.T) []map[string]any {
+		t.Helper()
+		var lines []map[string]any
+		scanner := bufio.NewScanner(&logs)
+		for scanner.Scan() {
+			var line map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Fatalf("log line is not JSON: %q", scanner.Text())
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	t.Run("tags every line with request, user and route", func(t *testing.T) {
+		rec := request("/transactions/7", "req-abc.123")
+		expectStatus(t, rec, http.StatusOK)
+		if got := rec.Header().Get(handlers.RequestIDHeader); got != "req-abc.123" {
+			t.Errorf("expected incoming request ID to be echoed, got %q", got)
+		}
+
+		logged := lines(t)
+		if len(logged) != 2 {
+			t.Fatalf("expected a handler line and an access line, got %v", logged)
+		}
+		for _, line := range logged {
+			if line["request_id"] != "req-abc.123" || line["user_id"] != testUserID || line["route"] != "/transactions/:id" {
+				t.Errorf("missing correlation fields: %v", line)
+			}
+		}
+		if access := logged[1]; access["msg"] != "request completed" || access["status"] != float64(http.StatusOK) {
+			t.Errorf("unexpected access line: %v", access)
+		}
+	})
+
+	t.Run("generates an ID when none or an unsafe one is sent", func(t *testing.T) {
+		for _, incoming := range []string{"", "bad id\nwith newline"} {
+			rec := request("/transactions/7", incoming)
+			got := rec.Header().Get(handlers.RequestIDHeader)
+			if len(got) != 32 {
+				t.Errorf("expected a generated request ID for %q, got %q", incoming, got)
+			}
+		}
+	})
+
+	t.Run("logs panics as errors", func(t *testing.T) {
+		expectStatus(t, request("/panic", ""), http.StatusInternalServerError)
+
+		logged := lines(t)
+		if len(logged) != 2 || logged[0]["msg"] != "panic recovered" || logged[1]["level"] != "ERROR" {
+			t.Errorf("unexpected panic logs: %v", logged)
+		}
+	})
+}
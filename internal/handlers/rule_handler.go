@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// RuleHandler handles HTTP requests related to auto-categorization rules
+type RuleHandler struct {
+	ruleService domain.RuleService
+}
+
+// NewRuleHandler creates a new rule handler
+func NewRuleHandler(ruleService domain.RuleService) *RuleHandler {
+	return &RuleHandler{
+		ruleService: ruleService,
+	}
+}
+
+// CreateRule handles POST /rules
+func (h *RuleHandler) CreateRule(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var request domain.RuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, invalidBodyError(err))
+		return
+	}
+
+	rule := newRuleFromRequest(userID, request)
+	if err := h.ruleService.CreateRule(c.Request.Context(), rule); err != nil {
+		abortWithError(c, fmt.Errorf("failed to create rule: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// GetRules handles GET /rules
+func (h *RuleHandler) GetRules(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	rules, err := h.ruleService.GetRules(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to get rules: %w", err))
+		return
+	}
+
+	if rules == nil {
+		rules = []domain.Rule{}
+	}
+
+	c.JSON(http.StatusOK, domain.RuleListResponse{
+		Rules: rules,
+	})
+}
+
+// GetRule handles GET /rules/:id
+func (h *RuleHandler) GetRule(c *gin.Context) {
+	rule, ok := h.loadRule(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpdateRule handles PUT /rules/:id
+func (h *RuleHandler) UpdateRule(c *gin.Context) {
+	existing, ok := h.loadRule(c)
+	if !ok {
+		return
+	}
+
+	var request domain.RuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, invalidBodyError(err))
+		return
+	}
+
+	rule := newRuleFromRequest(existing.UserID, request)
+	rule.ID = existing.ID
+	if err := h.ruleService.UpdateRule(c.Request.Context(), rule); err != nil {
+		abortWithError(c, fmt.Errorf("failed to update rule: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule handles DELETE /rules/:id
+func (h *RuleHandler) DeleteRule(c *gin.Context) {
+	rule, ok := h.loadRule(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ruleService.DeleteRule(c.Request.Context(), rule.UserID, rule.ID); err != nil {
+		abortWithError(c, fmt.Errorf("failed to delete rule: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.MessageResponse{
+		Message: "Rule deleted successfully",
+	})
+}
+
+// TestRule handles POST /rules/:id/test, dry-running the rule over existing transactions
+func (h *RuleHandler) TestRule(c *gin.Context) {
+	rule, ok := h.loadRule(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.ruleService.TestRule(c.Request.Context(), rule)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to test rule: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// loadRule resolves the :id path parameter to a rule owned by the authenticated user.
+// It records the error and returns false when the rule cannot be loaded.
+func (h *RuleHandler) loadRule(c *gin.Context) (*domain.Rule, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return nil, false
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, domain.NewValidationError("invalid rule ID"))
+		return nil, false
+	}
+
+	rule, err := h.ruleService.GetRuleByID(c.Request.Context(), userID, id)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to get rule: %w", err))
+		return nil, false
+	}
+
+	if rule == nil {
+		abortWithError(c, domain.NewNotFoundError("rule", id))
+		return nil, false
+	}
+
+	return rule, true
+}
+
+// newRuleFromRequest builds a rule owned by userID from the request body
+func newRuleFromRequest(userID string, request domain.RuleRequest) *domain.Rule {
+	enabled := true
+	if request.Enabled != nil {
+		enabled = *request.Enabled
+	}
+
+	return &domain.Rule{
+		UserID:     userID,
+		Name:       request.Name,
+		Position:   request.Position,
+		Enabled:    enabled,
+		Conditions: request.Conditions,
+		Actions:    request.Actions,
+	}
+}
+
+// SetupRoutes sets up the HTTP routes
+func (h *RuleHandler) SetupRoutes(router gin.IRouter) {
+	read := RequireScope(domain.ScopeRulesRead)
+	write := RequireScope(domain.ScopeRulesWrite)
+
+	router.GET("/rules", read, h.GetRules)
+	router.POST("/rules", write, h.CreateRule)
+	router.GET("/rules/:id", read, h.GetRule)
+	router.PUT("/rules/:id", write, h.UpdateRule)
+	router.DELETE("/rules/:id", write, h.DeleteRule)
+	router.POST("/rules/:id/test", read, h.TestRule)
+}
+
+// DescribeRoutes adds the routes of SetupRoutes to the OpenAPI document
+func (h *RuleHandler) DescribeRoutes(doc *openapi.Document) {
+	read, write := domain.ScopeRulesRead, domain.ScopeRulesWrite
+
+	protectedRoute(doc, http.MethodGet, "/rules", read).
+		Summary("List rules").
+		Tags("rules").
+		Response(http.StatusOK, "The caller's rules in evaluation order", domain.RuleListResponse{})
+
+	protectedRoute(doc, http.MethodPost, "/rules", write).
+		Summary("Create a rule").
+		Tags("rules").
+		Body(domain.RuleRequest{}).
+		Response(http.StatusCreated, "The created rule", domain.Rule{}).
+		ResponseRefs(http.StatusBadRequest)
+
+	protectedRoute(doc, http.MethodGet, "/rules/:id", read).
+		Summary("Get a rule").
+		Tags("rules").
+		PathParam("id", idParam(), "Rule ID").
+		Response(http.StatusOK, "The rule", domain.Rule{}).
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+
+	protectedRoute(doc, http.MethodPut, "/rules/:id", write).
+		Summary("Replace a rule").
+		Tags("rules").
+		PathParam("id", idParam(), "Rule ID").
+		Body(domain.RuleRequest{}).
+		Response(http.StatusOK, "The updated rule", domain.Rule{}).
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+
+	protectedRoute(doc, http.MethodDelete, "/rules/:id", write).
+		Summary("Delete a rule").
+		Tags("rules").
+		PathParam("id", idParam(), "Rule ID").
+		Response(http.StatusOK, "The rule was deleted", domain.MessageResponse{}).
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+
+	protectedRoute(doc, http.MethodPost, "/rules/:id/test", read).
+		Summary("Dry-run a rule").
+		Description("Reports how the rule would change the caller's transactions without saving anything.").
+		Tags("rules").
+		PathParam("id", idParam(), "Rule ID").
+		Response(http.StatusOK, "The transactions the rule would change", domain.RuleTestResult{}).
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+}
@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+// starbucksRule categorizes Starbucks purchases as entertainment
+func starbucksRule() domain.RuleRequest {
+	return domain.RuleRequest{
+		Name: "Starbucks is a treat",
+		Conditions: []domain.RuleCondition{
+			{Field: domain.RuleFieldDescription, Operator: domain.OperatorContains, Value: "starbucks"},
+		},
+		Actions: []domain.RuleAction{
+			{Type: domain.ActionSetCategory, Value: string(domain.CategoryEntertainment)},
+		},
+	}
+}
+
+func (s *testServer) createRule(t *testing.T, request domain.RuleRequest) domain.Rule {
+	t.Helper()
+	rec := s.do(t, http.MethodPost, "/rules", request)
+	expectStatus(t, rec, http.StatusCreated)
+	return decode[domain.Rule](t, rec)
+}
+
+func TestRules(t *testing.T) {
+	s := newTestServer(t)
+
+	created := s.createRule(t, starbucksRule())
+	if created.ID == 0 || !created.Enabled || created.Name != "Starbucks is a treat" {
+		t.Fatalf("unexpected rule: %+v", created)
+	}
+	path := fmt.Sprintf("/rules/%d", created.ID)
+
+	t.Run("gets and lists rules", func(t *testing.T) {
+		rec := s.do(t, http.MethodGet, path, nil)
+		expectStatus(t, rec, http.StatusOK)
+		if got := decode[domain.Rule](t, rec); got.ID != created.ID || len(got.Conditions) != 1 || len(got.Actions) != 1 {
+			t.Errorf("unexpected rule: %+v", got)
+		}
+
+		rec = s.do(t, http.MethodGet, "/rules", nil)
+		expectStatus(t, rec, http.StatusOK)
+		if rules := decode[domain.RuleListResponse](t, rec).Rules; len(rules) != 1 || rules[0].ID != created.ID {
+			t.Errorf("unexpected rules: %+v", rules)
+		}
+	})
+
+	t.Run("applies rules to saved transactions", func(t *testing.T) {
+		s.ai.OnText("coffee 45", testutil.AIResponse{Transactions: []domain.Transaction{coffee()}})
+		rec := s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"})
+		expectStatus(t, rec, http.StatusOK)
+		if saved := decode[domain.ParseInputResponse](t, rec).Transactions; len(saved) != 1 || saved[0].Category != domain.CategoryEntertainment {
+			t.Errorf("expected the rule to categorize the parsed transaction, got %+v", saved)
+		}
+	})
+
+	t.Run("dry-runs a rule over the caller's transactions", func(t *testing.T) {
+		theirs := coffee()
+		theirs.UserID = "other-user"
+		s.seed(t, coffee(), theirs)
+
+		rec := s.do(t, http.MethodPost, path+"/test", nil)
+		expectStatus(t, rec, http.StatusOK)
+		result := decode[domain.RuleTestResult](t, rec)
+		// The parsed transaction already is entertainment, but still matches
+		if result.Scanned != 2 || result.Matched != 2 {
+			t.Fatalf("expected the caller's 2 transactions to match, got %+v", result)
+		}
+		for _, match := range result.Matches {
+			if match.After.Category != domain.CategoryEntertainment {
+				t.Errorf("unexpected match: %+v", match)
+			}
+		}
+	})
+
+	t.Run("applies rules to manual creates", func(t *testing.T) {
+		rec := s.do(t, http.MethodPost, "/transactions", domain.CreateTransactionsRequest{Transactions: []domain.UpdateTransactionRequest{
+			{Amount: 45, Currency: "MXN", Category: domain.CategoryFood, Type: domain.Expense, Date: testDate, Description: "Starbucks latte"},
+		}})
+		expectStatus(t, rec, http.StatusCreated)
+		created := decode[domain.CreateTransactionsResponse](t, rec).Transactions
+		if len(created) != 1 || created[0].Category != domain.CategoryEntertainment {
+			t.Fatalf("expected the rule to categorize the created transaction, got %+v", created)
+		}
+		if stored, _ := s.repo.GetTransactionByID(context.Background(), testUserID, created[0].ID); stored == nil || stored.Category != domain.CategoryEntertainment {
+			t.Errorf("expected the rule's category to be stored, got %+v", stored)
+		}
+	})
+
+	t.Run("applies rules to bulk creates", func(t *testing.T) {
+		rec := s.do(t, http.MethodPost, "/transactions/bulk", `{"operations":[
+			{"action":"create","transaction":{"amount":45,"currency":"MXN","category":"food","type":"expense","date":"2024-01-15T12:00:00Z","description":"Starbucks latte"}}
+		]}`)
+		expectStatus(t, rec, http.StatusOK)
+		if results := decode[domain.BulkResponse](t, rec).Results; len(results) != 1 || results[0].Transaction == nil ||
+			results[0].Transaction.Category != domain.CategoryEntertainment {
+			t.Errorf("expected the rule to categorize the created transaction, got %+v", results)
+		}
+	})
+
+	t.Run("replaces a rule", func(t *testing.T) {
+		request := starbucksRule()
+		disabled := false
+		request.Enabled = &disabled
+		request.Position = 3
+		rec := s.do(t, http.MethodPut, path, request)
+		expectStatus(t, rec, http.StatusOK)
+		if got := decode[domain.Rule](t, rec); got.ID != created.ID || got.Enabled || got.Position != 3 {
+			t.Errorf("unexpected rule: %+v", got)
+		}
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		request := starbucksRule()
+		request.Actions[0].Value = "coffee"
+		expectProblem(t, s.do(t, http.MethodPost, "/rules", request), http.StatusBadRequest, handlers.CodeValidation)
+		expectProblem(t, s.do(t, http.MethodPut, path, request), http.StatusBadRequest, handlers.CodeValidation)
+
+		request = starbucksRule()
+		request.Conditions[0].Operator = domain.OperatorGreaterThan
+		expectProblem(t, s.do(t, http.MethodPost, "/rules", request), http.StatusBadRequest, handlers.CodeValidation)
+	})
+
+	t.Run("hides other users' rules", func(t *testing.T) {
+		other := "Bearer " + testutil.MintJWT(t, "other-user", testutil.TokenOptions{Email: "other@example.com"})
+		expectProblem(t, s.do(t, http.MethodGet, path, nil, "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+		expectProblem(t, s.do(t, http.MethodPost, path+"/test", nil, "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+		expectProblem(t, s.do(t, http.MethodDelete, path, nil, "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+	})
+
+	t.Run("deletes a rule", func(t *testing.T) {
+		expectStatus(t, s.do(t, http.MethodDelete, path, nil), http.StatusOK)
+		expectProblem(t, s.do(t, http.MethodGet, path, nil), http.StatusNotFound, handlers.CodeNotFound)
+		expectProblem(t, s.do(t, http.MethodGet, "/rules/abc", nil), http.StatusBadRequest, handlers.CodeValidation)
+	})
+}
@@ -5,22 +5,26 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/jairogloz/go-expense-tracker-back/internal/app"
 	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
 )
 
 // getUserID extracts the user ID from the Gin context
 func getUserID(c *gin.Context) (string, error) {
 	userID, exists := c.Get(string(domain.UserIDKey))
 	if !exists {
-		return "", fmt.Errorf("user ID not found in context")
+		return "", domain.NewUnauthorizedError("user authentication required", nil)
 	}
 
 	id, ok := userID.(string)
 	if !ok {
-		return "", fmt.Errorf("user ID is not a string")
+		return "", domain.NewUnauthorizedError("user authentication required", fmt.Errorf("user ID is not a string"))
 	}
 
 	return id, nil
@@ -28,15 +32,19 @@ func getUserID(c *gin.Context) (string, error) {
 
 // TransactionHandler handles HTTP requests related to transactions
 type TransactionHandler struct {
-	parseInputUseCase  *app.ParseInputUseCase
-	transactionService domain.TransactionService
+	parseInputUseCase         *app.ParseInputUseCase
+	createTransactionsUseCase *app.CreateTransactionsUseCase
+	bulkTransactionsUseCase   *app.BulkTransactionsUseCase
+	transactionService        domain.TransactionService
 }
 
 // NewTransactionHandler creates a new transaction handler
-func NewTransactionHandler(parseInputUseCase *app.ParseInputUseCase, transactionService domain.TransactionService) *TransactionHandler {
+func NewTransactionHandler(parseInputUseCase *app.ParseInputUseCase, createTransactionsUseCase *app.CreateTransactionsUseCase, bulkTransactionsUseCase *app.BulkTransactionsUseCase, transactionService domain.TransactionService) *TransactionHandler {
 	return &TransactionHandler{
-		parseInputUseCase:  parseInputUseCase,
-		transactionService: transactionService,
+		parseInputUseCase:         parseInputUseCase,
+		createTransactionsUseCase: createTransactionsUseCase,
+		bulkTransactionsUseCase:   bulkTransactionsUseCase,
+		transactionService:        transactionService,
 	}
 }
 
@@ -45,191 +53,628 @@ func (h *TransactionHandler) ParseInput(c *gin.Context) {
 	// Get user ID from context
 	userID, err := getUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "User authentication required",
-			"details": err.Error(),
-		})
+		abortWithError(c, err)
 		return
 	}
 
 	var request domain.ParseInputRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		abortWithError(c, invalidBodyError(err))
 		return
 	}
 
+	operationID := startOperation(c)
 	// Add user ID to context for the use case
 	ctx := context.WithValue(c.Request.Context(), domain.UserIDKey, userID)
 	response, err := h.parseInputUseCase.Execute(ctx, request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to parse input",
-			"details": err.Error(),
-		})
+		abortWithError(c, fmt.Errorf("failed to parse input: %w", err))
 		return
 	}
 
+	response.OperationID = operationID
+	c.Header(OperationIDHeader, operationID)
 	c.JSON(http.StatusOK, response)
 }
 
+// CreateTransactions handles POST /transactions, saving structured transactions without
+// the AI. Either all of them are created or none.
+func (h *TransactionHandler) CreateTransactions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var request domain.CreateTransactionsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, invalidBodyError(err))
+		return
+	}
+
+	operationID := startOperation(c)
+	// Add user ID to context for the use case
+	ctx := context.WithValue(c.Request.Context(), domain.UserIDKey, userID)
+	response, err := h.createTransactionsUseCase.Execute(ctx, request)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to create transactions: %w", err))
+		return
+	}
+
+	response.OperationID = operationID
+	c.Header(OperationIDHeader, operationID)
+	c.JSON(http.StatusCreated, response)
+}
+
 // GetTransaction handles GET /transactions/:id
 func (h *TransactionHandler) GetTransaction(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid transaction ID",
-		})
+		abortWithError(c, domain.NewValidationError("invalid transaction ID"))
 		return
 	}
 
-	transaction, err := h.transactionService.GetTransactionByID(c.Request.Context(), id)
+	transaction, err := h.transactionService.GetTransactionByID(c.Request.Context(), userID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get transaction",
-			"details": err.Error(),
-		})
+		abortWithError(c, fmt.Errorf("failed to get transaction: %w", err))
 		return
 	}
 
 	if transaction == nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Transaction not found",
-		})
+		abortWithError(c, domain.NewNotFoundError("transaction", id))
 		return
 	}
 
+	c.Header(ETagHeader, transactionETag(transaction))
 	c.JSON(http.StatusOK, transaction)
 }
 
 // GetTransactions handles GET /transactions
 func (h *TransactionHandler) GetTransactions(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	limit, offset := pagination(c)
+
+	transactions, err := h.transactionService.GetTransactions(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to get transactions: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.TransactionListResponse{
+		Transactions: transactions,
+		Limit:        limit,
+		Offset:       offset,
+	})
+}
+
+// GetDeletedTransactions handles GET /transactions/trash
+func (h *TransactionHandler) GetDeletedTransactions(c *gin.Context) {
+	limit, offset := pagination(c)
+
+	transactions, err := h.transactionService.GetDeletedTransactions(c.Request.Context(), limit, offset)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to get deleted transactions: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.TransactionListResponse{
+		Transactions: transactions,
+		Limit:        limit,
+		Offset:       offset,
+	})
+}
+
+// RestoreTransaction handles POST /transactions/:id/restore, moving a transaction out of
+// the trash
+func (h *TransactionHandler) RestoreTransaction(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		abortWithError(c, domain.NewValidationError("invalid transaction ID"))
+		return
+	}
+
+	operationID := startOperation(c)
+	transaction, err := h.transactionService.RestoreTransaction(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to restore transaction: %w", err))
+		return
+	}
+
+	c.Header(OperationIDHeader, operationID)
+	c.Header(ETagHeader, transactionETag(transaction))
+	c.JSON(http.StatusOK, transaction)
+}
+
+// GetTransactionHistory handles GET /transactions/:id/history
+func (h *TransactionHandler) GetTransactionHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		abortWithError(c, domain.NewValidationError("invalid transaction ID"))
+		return
+	}
+
+	events, err := h.transactionService.GetTransactionHistory(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to get transaction history: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.TransactionHistoryResponse{Events: events})
+}
 
-	limit, err := strconv.Atoi(limitStr)
+// pagination returns the limit and offset query parameters, defaulting to the first
+// 10 transactions
+func pagination(c *gin.Context) (limit, offset int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	if err != nil || limit <= 0 {
 		limit = 10
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	offset, err = strconv.Atoi(c.DefaultQuery("offset", "0"))
 	if err != nil || offset < 0 {
 		offset = 0
 	}
 
-	transactions, err := h.transactionService.GetTransactions(c.Request.Context(), limit, offset)
+	return limit, offset
+}
+
+// GetDuplicateTransactions handles GET /transactions/duplicates
+func (h *TransactionHandler) GetDuplicateTransactions(c *gin.Context) {
+	groups, err := h.transactionService.FindDuplicates(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get transactions",
-			"details": err.Error(),
-		})
+		abortWithError(c, fmt.Errorf("failed to find duplicate transactions: %w", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"transactions": transactions,
-		"limit":        limit,
-		"offset":       offset,
+	c.JSON(http.StatusOK, domain.DuplicateGroupsResponse{
+		Groups: groups,
 	})
 }
 
-// UpdateTransaction handles PUT /transactions/:id
+// UpdateTransaction handles PUT /transactions/:id. It requires If-Match.
 func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid transaction ID",
-		})
+		abortWithError(c, domain.NewValidationError("invalid transaction ID"))
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
 	var request domain.UpdateTransactionRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		abortWithError(c, invalidBodyError(err))
 		return
 	}
 
 	// Check if transaction exists
-	existing, err := h.transactionService.GetTransactionByID(c.Request.Context(), id)
+	existing, err := h.transactionService.GetTransactionByID(c.Request.Context(), userID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get transaction",
-			"details": err.Error(),
-		})
+		abortWithError(c, fmt.Errorf("failed to get transaction: %w", err))
 		return
 	}
 
 	if existing == nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Transaction not found",
-		})
+		abortWithError(c, domain.NewNotFoundError("transaction", id))
 		return
 	}
 
-	// Create updated transaction
-	transaction := &domain.Transaction{
-		ID:          id,
-		Amount:      request.Amount,
-		Currency:    request.Currency,
-		Category:    request.Category,
-		Type:        request.Type,
-		Date:        request.Date,
-		Description: request.Description,
+	operationID := startOperation(c)
+	transaction := updatedTransaction(id, request, version)
+	transaction.UserID = userID
+	if err := h.transactionService.UpdateTransaction(c.Request.Context(), transaction); err != nil {
+		abortWithError(c, fmt.Errorf("failed to update transaction: %w", err))
+		return
 	}
 
-	if err := h.transactionService.UpdateTransaction(c.Request.Context(), transaction); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to update transaction",
-			"details": err.Error(),
-		})
+	c.Header(OperationIDHeader, operationID)
+	c.Header(ETagHeader, transactionETag(transaction))
+	c.JSON(http.StatusOK, transaction)
+}
+
+// PatchTransaction handles PATCH /transactions/:id, applying a JSON Merge Patch (RFC 7396)
+// to the fields accepted by PUT. It requires If-Match.
+func (h *TransactionHandler) PatchTransaction(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		abortWithError(c, domain.NewValidationError("invalid transaction ID"))
 		return
 	}
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var patch map[string]any
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		abortWithError(c, invalidBodyError(err))
+		return
+	}
+
+	// With If-Match: *, the patch applies to whatever version is current, as for PUT and DELETE
+	operationID := startOperation(c)
+	transaction, err := h.transactionService.PatchTransaction(c.Request.Context(), userID, id, version, func(existing domain.Transaction) (*domain.Transaction, error) {
+		patched, err := applyMergePatch(domain.UpdateTransactionRequest{
+			Amount:      existing.Amount,
+			Currency:    existing.Currency,
+			Category:    existing.Category,
+			Type:        existing.Type,
+			Date:        existing.Date,
+			Description: existing.Description,
+			Account:     existing.Account,
+			Tags:        existing.Tags,
+		}, patch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply patch: %w", err)
+		}
+
+		var request domain.UpdateTransactionRequest
+		if err := binding.JSON.BindBody(patched, &request); err != nil {
+			return nil, invalidBodyError(err)
+		}
+		return updatedTransaction(id, request, time.Time{}), nil
+	})
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to update transaction: %w", err))
+		return
+	}
+
+	c.Header(OperationIDHeader, operationID)
+	c.Header(ETagHeader, transactionETag(transaction))
 	c.JSON(http.StatusOK, transaction)
 }
 
-// DeleteTransaction handles DELETE /transactions/:id
+// DeleteTransaction handles DELETE /transactions/:id. It requires If-Match.
 func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid transaction ID",
-		})
+		abortWithError(c, domain.NewValidationError("invalid transaction ID"))
 		return
 	}
 
-	if err := h.transactionService.DeleteTransaction(c.Request.Context(), id); err != nil {
-		if err.Error() == fmt.Sprintf("transaction with id %d not found", id) {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Transaction not found",
-			})
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	operationID := startOperation(c)
+	if err := h.transactionService.DeleteTransaction(c.Request.Context(), userID, id, version); err != nil {
+		abortWithError(c, fmt.Errorf("failed to delete transaction: %w", err))
+		return
+	}
+
+	c.Header(OperationIDHeader, operationID)
+	c.JSON(http.StatusOK, domain.MessageResponse{
+		Message: "Transaction deleted successfully",
+	})
+}
+
+// BulkTransactions handles POST /transactions/bulk. It applies either a list of create,
+// update and delete operations or an action on every transaction matching a filter, all
+// or nothing.
+func (h *TransactionHandler) BulkTransactions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var request domain.BulkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, invalidBodyError(err))
+		return
+	}
+
+	operationID := startOperation(c)
+	// Add user ID to context so created transactions are owned by the user
+	ctx := context.WithValue(c.Request.Context(), domain.UserIDKey, userID)
+
+	if request.Filter != nil {
+		if fields := bulkFilterErrors(request); len(fields) > 0 {
+			abortWithError(c, domain.NewFieldValidationError(fields))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete transaction",
-			"details": err.Error(),
-		})
+
+		writes, err := h.transactionService.ApplyToMatching(ctx, *request.Filter, request.Action, request.Category)
+		if err != nil {
+			abortWithError(c, fmt.Errorf("failed to apply bulk action: %w", err))
+			return
+		}
+
+		results := make([]domain.BulkResult, len(writes))
+		for i, write := range writes {
+			results[i] = bulkResult(i, request.Action, write)
+		}
+		c.Header(OperationIDHeader, operationID)
+		c.JSON(http.StatusOK, domain.BulkResponse{Results: results, OperationID: operationID})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Transaction deleted successfully",
-	})
+	writes, fields := bulkWrites(request)
+	if len(fields) > 0 {
+		abortWithError(c, domain.NewFieldValidationError(fields))
+		return
+	}
+
+	if err := h.bulkTransactionsUseCase.Execute(ctx, writes); err != nil {
+		abortWithError(c, fmt.Errorf("failed to apply bulk operations: %w", err))
+		return
+	}
+
+	results := make([]domain.BulkResult, len(writes))
+	for i, write := range writes {
+		results[i] = bulkResult(i, write.Action, write)
+	}
+	c.Header(OperationIDHeader, operationID)
+	c.JSON(http.StatusOK, domain.BulkResponse{Results: results, OperationID: operationID})
+}
+
+// bulkFilterErrors validates the filter mode of a bulk request
+func bulkFilterErrors(request domain.BulkRequest) []domain.FieldError {
+	var fields []domain.FieldError
+	if len(request.Operations) > 0 {
+		fields = append(fields, domain.FieldError{In: "body", Field: "operations", Message: "cannot be combined with filter"})
+	}
+	if request.Filter.IsEmpty() {
+		fields = append(fields, domain.FieldError{In: "body", Field: "filter", Message: "must set at least one condition"})
+	}
+	switch request.Action {
+	case "":
+		fields = append(fields, domain.FieldError{In: "body", Field: "action", Message: "is required with filter"})
+	case domain.BulkActionSetCategory:
+		if request.Category == "" {
+			fields = append(fields, domain.FieldError{In: "body", Field: "category", Message: "is required to set the category"})
+		}
+	}
+	return fields
+}
+
+// bulkWrites validates the operations of a bulk request and converts them to writes
+func bulkWrites(request domain.BulkRequest) ([]domain.TransactionWrite, []domain.FieldError) {
+	var fields []domain.FieldError
+	if len(request.Operations) == 0 {
+		fields = append(fields, domain.FieldError{In: "body", Field: "operations", Message: "is required unless filter is set"})
+	}
+	if request.Action != "" {
+		fields = append(fields, domain.FieldError{In: "body", Field: "action", Message: "is only allowed with filter"})
+	}
+
+	writes := make([]domain.TransactionWrite, len(request.Operations))
+	for i, operation := range request.Operations {
+		field := fmt.Sprintf("operations[%d]", i)
+		write := domain.TransactionWrite{Action: operation.Action}
+
+		if operation.Action == domain.BulkActionCreate {
+			if operation.ID != 0 {
+				fields = append(fields, domain.FieldError{In: "body", Field: field + ".id", Message: "is not allowed for create"})
+			}
+		} else if operation.ID <= 0 {
+			fields = append(fields, domain.FieldError{In: "body", Field: field + ".id", Message: "is required"})
+		}
+
+		if operation.Action == domain.BulkActionDelete {
+			if operation.Transaction != nil {
+				fields = append(fields, domain.FieldError{In: "body", Field: field + ".transaction", Message: "is not allowed for delete"})
+			}
+		} else if operation.Transaction == nil {
+			fields = append(fields, domain.FieldError{In: "body", Field: field + ".transaction", Message: "is required"})
+		}
+
+		var version time.Time
+		if ifMatch := strings.TrimSpace(operation.IfMatch); ifMatch != "" {
+			if operation.Action == domain.BulkActionCreate {
+				fields = append(fields, domain.FieldError{In: "body", Field: field + ".if_match", Message: "is not allowed for create"})
+			} else if v, err := etagVersion(ifMatch); err != nil {
+				fields = append(fields, domain.FieldError{In: "body", Field: field + ".if_match", Message: "must be an ETag or *"})
+			} else {
+				version = v
+			}
+		}
+
+		if operation.Transaction != nil {
+			write.Transaction = *updatedTransaction(operation.ID, *operation.Transaction, version)
+		} else {
+			write.Transaction = domain.Transaction{ID: operation.ID, UpdatedAt: version}
+		}
+		writes[i] = write
+	}
+
+	return writes, fields
+}
+
+// bulkResult reports the outcome of the write at index, with the new ETag of created and
+// updated transactions
+func bulkResult(index int, action domain.BulkAction, write domain.TransactionWrite) domain.BulkResult {
+	result := domain.BulkResult{
+		Index:  index,
+		Action: action,
+		ID:     write.Transaction.ID,
+	}
+	if write.Action != domain.BulkActionDelete {
+		transaction := write.Transaction
+		result.ETag = transactionETag(&transaction)
+		result.Transaction = &transaction
+	}
+	return result
+}
+
+// updatedTransaction builds the transaction replacing transaction id, conditional on version
+func updatedTransaction(id int, request domain.UpdateTransactionRequest, version time.Time) *domain.Transaction {
+	return &domain.Transaction{
+		ID:          id,
+		Amount:      request.Amount,
+		Currency:    request.Currency,
+		Category:    request.Category,
+		Type:        request.Type,
+		Date:        request.Date,
+		Description: request.Description,
+		Account:     request.Account,
+		Tags:        request.Tags,
+		UpdatedAt:   version,
+	}
 }
 
 // SetupRoutes sets up the HTTP routes
 func (h *TransactionHandler) SetupRoutes(router gin.IRouter) {
-	router.POST("/parse", h.ParseInput)
-	router.GET("/transactions/:id", h.GetTransaction)
-	router.GET("/transactions", h.GetTransactions)
-	router.PUT("/transactions/:id", h.UpdateTransaction)
-	router.DELETE("/transactions/:id", h.DeleteTransaction)
+	read := RequireScope(domain.ScopeTransactionsRead)
+	write := RequireScope(domain.ScopeTransactionsWrite)
+
+	router.POST("/parse", write, h.ParseInput)
+	router.GET("/transactions/duplicates", read, h.GetDuplicateTransactions)
+	router.GET("/transactions/trash", read, h.GetDeletedTransactions)
+	router.GET("/transactions/:id", read, h.GetTransaction)
+	router.GET("/transactions/:id/history", read, h.GetTransactionHistory)
+	router.GET("/transactions", read, h.GetTransactions)
+	router.POST("/transactions", write, h.CreateTransactions)
+	router.PUT("/transactions/:id", write, h.UpdateTransaction)
+	router.PATCH("/transactions/:id", write, h.PatchTransaction)
+	router.DELETE("/transactions/:id", write, h.DeleteTransaction)
+	router.POST("/transactions/:id/restore", write, h.RestoreTransaction)
+	router.POST("/transactions/bulk", write, h.BulkTransactions)
+}
+
+// DescribeRoutes adds the routes of SetupRoutes to the OpenAPI document
+func (h *TransactionHandler) DescribeRoutes(doc *openapi.Document) {
+	read, write := domain.ScopeTransactionsRead, domain.ScopeTransactionsWrite
+
+	protectedRoute(doc, http.MethodPost, "/parse", write).
+		Summary("Parse text into transactions").
+		Description("Extracts transactions from natural language with the AI and saves them. Suspected duplicates are handled according to `on_duplicate`.").
+		Tags("transactions").
+		Body(domain.ParseInputRequest{}).
+		Response(http.StatusOK, "Parsed and saved transactions", domain.ParseInputResponse{}).
+		ResponseRefs(http.StatusBadRequest, http.StatusServiceUnavailable)
+
+	protectedRoute(doc, http.MethodGet, "/transactions/duplicates", read).
+		Summary("List suspected duplicate transactions").
+		Tags("transactions").
+		Response(http.StatusOK, "Groups of suspected duplicates", domain.DuplicateGroupsResponse{})
+
+	protectedRoute(doc, http.MethodGet, "/transactions/trash", read).
+		Summary("List deleted transactions").
+		Description("Deleted transactions stay in the trash, where they can be restored, until they are purged after the retention period.").
+		Tags("transactions").
+		Query("limit", openapi.Integer().WithMinimum(1).WithDefault(10), "Page size").
+		Query("offset", openapi.Integer().WithMinimum(0).WithDefault(0), "Number of transactions to skip").
+		Response(http.StatusOK, "A page of deleted transactions, most recently deleted first", domain.TransactionListResponse{}).
+		ResponseRefs(http.StatusBadRequest)
+
+	protectedRoute(doc, http.MethodGet, "/transactions/:id", read).
+		Summary("Get a transaction").
+		Tags("transactions").
+		PathParam("id", idParam(), "Transaction ID").
+		Response(http.StatusOK, "The transaction", domain.Transaction{}).
+		ResponseHeader(http.StatusOK, ETagHeader, openapi.String(), "Version of the transaction, to send as If-Match when changing it").
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+
+	protectedRoute(doc, http.MethodGet, "/transactions", read).
+		Summary("List transactions").
+		Tags("transactions").
+		Query("limit", openapi.Integer().WithMinimum(1).WithDefault(10), "Page size").
+		Query("offset", openapi.Integer().WithMinimum(0).WithDefault(0), "Number of transactions to skip").
+		Response(http.StatusOK, "A page of transactions, newest first", domain.TransactionListResponse{}).
+		ResponseRefs(http.StatusBadRequest)
+
+	protectedRoute(doc, http.MethodPost, "/transactions", write).
+		Summary("Create transactions").
+		Description("Saves up to 1000 structured transactions without the AI or duplicate detection, applying the caller's auto-categorization rules. Either all of them are created or none.").
+		Tags("transactions").
+		Body(domain.CreateTransactionsRequest{}).
+		Response(http.StatusCreated, "The created transactions with their IDs, in request order", domain.CreateTransactionsResponse{}).
+		ResponseRefs(http.StatusBadRequest)
+
+	conditionalWrite(protectedRoute(doc, http.MethodPut, "/transactions/:id", write)).
+		Summary("Replace a transaction").
+		Tags("transactions").
+		PathParam("id", idParam(), "Transaction ID").
+		Body(domain.UpdateTransactionRequest{}).
+		Response(http.StatusOK, "The updated transaction", domain.Transaction{}).
+		ResponseHeader(http.StatusOK, ETagHeader, openapi.String(), "New version of the transaction").
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+
+	conditionalWrite(protectedRoute(doc, http.MethodPatch, "/transactions/:id", write)).
+		Summary("Update fields of a transaction").
+		Description("Applies a JSON Merge Patch (RFC 7396): fields left out are unchanged, and `null` clears optional fields.").
+		Tags("transactions").
+		PathParam("id", idParam(), "Transaction ID").
+		MergePatchBody(domain.UpdateTransactionRequest{}).
+		Response(http.StatusOK, "The updated transaction", domain.Transaction{}).
+		ResponseHeader(http.StatusOK, ETagHeader, openapi.String(), "New version of the transaction").
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+
+	conditionalWrite(protectedRoute(doc, http.MethodDelete, "/transactions/:id", write)).
+		Summary("Delete a transaction").
+		Description("Moves the transaction to the trash, from which it can be restored until it is purged.").
+		Tags("transactions").
+		PathParam("id", idParam(), "Transaction ID").
+		Response(http.StatusOK, "The transaction was deleted", domain.MessageResponse{}).
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+
+	protectedRoute(doc, http.MethodGet, "/transactions/:id/history", read).
+		Summary("Get the change history of a transaction").
+		Description("Lists every create, update, delete and restore of the transaction, oldest first, with who made it, from where, and the transaction before and after. "+
+			"The history outlives the transaction when it is purged from the trash.").
+		Tags("transactions").
+		PathParam("id", idParam(), "Transaction ID").
+		Response(http.StatusOK, "The audit events of the transaction", domain.TransactionHistoryResponse{}).
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+
+	protectedRoute(doc, http.MethodPost, "/transactions/:id/restore", write).
+		Summary("Restore a deleted transaction").
+		Tags("transactions").
+		PathParam("id", idParam(), "Transaction ID").
+		Response(http.StatusOK, "The restored transaction", domain.Transaction{}).
+		ResponseHeader(http.StatusOK, ETagHeader, openapi.String(), "New version of the transaction").
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound)
+
+	protectedRoute(doc, http.MethodPost, "/transactions/bulk", write).
+		Summary("Change many transactions at once").
+		Description("Applies either a list of `operations` (create, update or delete, with an optional `if_match` ETag each) or an `action` (`set_category` or `delete`) on every transaction matching `filter`. "+
+			"Created transactions go through the caller's auto-categorization rules. Changes are atomic: when one fails, none are applied and the error names the failing operation. At most 1000 transactions can be changed per request.").
+		Tags("transactions").
+		Body(domain.BulkRequest{}).
+		Response(http.StatusOK, "The outcome of every operation, in order", domain.BulkResponse{}).
+		ResponseRefs(http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed)
 }
@@ -0,0 +1,931 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/app"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/handlers"
+	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+	"github.com/jairogloz/go-expense-tracker-back/internal/services"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+const (
+	testUserID            = "user-123"
+	testMonthlyTokenQuota = 1000
+)
+
+// testServer wires the real handlers, services and auth middleware to in-memory
+// storage and a scripted AI, mirroring the router built in cmd/server
+type testServer struct {
+	router  *gin.Engine
+	ai      *testutil.FakeAIService
+	repo    *infra.MemoryTransactionRepository
+	metrics *infra.PrometheusMetrics
+	token   string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo := infra.NewMemoryTransactionRepository()
+	ai := testutil.NewFakeAIService()
+
+	ruleRepo := infra.NewMemoryRuleRepository()
+	apiKeyRepo := infra.NewMemoryAPIKeyRepository()
+	idempotencyRepo := infra.NewMemoryIdempotencyRepository()
+
+	ruleService := services.NewRuleService(ruleRepo, repo)
+	transactionService := services.NewTransactionService(repo, ruleService, services.DuplicateDetectionConfig{
+		DateWindow:          48 * time.Hour,
+		SimilarityThreshold: 0.8,
+	})
+	quotaRepo := infra.NewMemoryAIQuotaRepository()
+	quotaService := services.NewAIQuotaService(quotaRepo, testMonthlyTokenQuota)
+	usageRepo := infra.NewMemoryAIUsageRepository()
+	usageService := services.NewAIUsageService(usageRepo, quotaService, map[string]domain.AIModelPrice{
+		testutil.FakeAIModel: {Prompt: 1, Completion: 2},
+	})
+	metrics := infra.NewPrometheusMetrics()
+	ai.SetUsageRecorder(services.AIUsageRecorders{quotaService, usageService, metrics})
+	parseInputUseCase := app.NewParseInputUseCase(ai, transactionService, quotaService, metrics)
+	createTransactionsUseCase := app.NewCreateTransactionsUseCase(transactionService, metrics)
+	bulkTransactionsUseCase := app.NewBulkTransactionsUseCase(transactionService, metrics)
+
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	adminService := services.NewAdminService(infra.NewMemoryAdminRepository(repo, ruleRepo, apiKeyRepo, idempotencyRepo, quotaRepo, usageRepo))
+
+	authMiddleware := handlers.NewAuthMiddleware(infra.NewSupabaseAuthService(testutil.AuthConfig()), apiKeyService)
+	idempotencyMiddleware := handlers.NewIdempotencyMiddleware(idempotencyRepo, time.Hour)
+
+	routes := []handlers.Routes{
+		handlers.NewTransactionHandler(parseInputUseCase, createTransactionsUseCase, bulkTransactionsUseCase, transactionService),
+		handlers.NewOperationHandler(services.NewOperationService(repo, 15*time.Minute)),
+		handlers.NewRuleHandler(ruleService),
+		handlers.NewAPIKeyHandler(apiKeyService),
+		handlers.NewAdminHandler(adminService),
+		handlers.NewUsageHandler(usageService),
+	}
+
+	router := gin.New()
+	router.Use(handlers.NewRequestLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))).Handle())
+	router.Use(handlers.NewMetricsMiddleware(metrics).Handle())
+	router.Use(handlers.NewErrorMiddleware().Handle())
+	handlers.NewMetricsHandler(metrics.Handler()).SetupRoutes(router)
+	protected := router.Group("/")
+	protected.Use(authMiddleware.Authenticate())
+	protected.Use(idempotencyMiddleware.Handle())
+	protected.Use(handlers.NewErrorMiddleware().Handle())
+	protected.Use(handlers.NewValidationMiddleware(handlers.NewOpenAPIDocument(routes...)).Handle())
+	for _, r := range routes {
+		r.SetupRoutes(protected)
+	}
+
+	return &testServer{
+		router:  router,
+		ai:      ai,
+		repo:    repo,
+		metrics: metrics,
+		token:   testutil.MintJWT(t, testUserID, testutil.TokenOptions{Email: "user@example.com"}),
+	}
+}
+
+// do sends an authenticated request with an optional JSON body
+func (s *testServer) do(t *testing.T, method, path string, body any, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// seed stores transactions directly in the repository and returns them as stored, newest first
+func (s *testServer) seed(t *testing.T, transactions ...domain.Transaction) []domain.Transaction {
+	t.Helper()
+	ctx := context.Background()
+	if err := testutil.SaveTransactions(ctx, s.repo, transactions); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	stored, err := s.repo.GetTransactions(ctx, testUserID, 100, 0)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return stored
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("expected status %d, got %d: %s", status, rec.Code, rec.Body.String())
+	}
+}
+
+var testDate = time.Date(2024, 8, 14, 15, 30, 0, 0, time.UTC)
+
+// coffee returns a transaction of the test user
+func coffee() domain.Transaction {
+	return domain.Transaction{
+		UserID:      testUserID,
+		Amount:      45,
+		Currency:    "MXN",
+		Category:    domain.CategoryFood,
+		Type:        domain.Expense,
+		Date:        testDate,
+		Description: "Coffee at Starbucks",
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"missing header", ""},
+		{"not a bearer token", "Basic abc"},
+		{"invalid token", "Bearer not-a-jwt"},
+		{"expired token", "Bearer " + testutil.MintJWT(t, testUserID, testutil.TokenOptions{ExpiresIn: -time.Hour})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/transactions", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			s.router.ServeHTTP(rec, req)
+			expectStatus(t, rec, http.StatusUnauthorized)
+		})
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	t.Run("parses and saves transactions", func(t *testing.T) {
+		s := newTestServer(t)
+		s.ai.OnText("coffee 45", testutil.AIResponse{Transactions: []domain.Transaction{coffee()}})
+
+		rec := s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"})
+		expectStatus(t, rec, http.StatusOK)
+
+		response := decode[domain.ParseInputResponse](t, rec)
+		if len(response.Transactions) != 1 || response.Transactions[0].Amount != 45 {
+			t.Fatalf("unexpected response: %+v", response)
+		}
+
+		stored, _ := s.repo.GetTransactions(context.Background(), testUserID, 10, 0)
+		if len(stored) != 1 {
+			t.Fatalf("expected 1 stored transaction, got %d", len(stored))
+		}
+		if saved := response.Transactions[0]; saved.ID != stored[0].ID || !saved.CreatedAt.Equal(stored[0].CreatedAt) || !saved.UpdatedAt.Equal(stored[0].UpdatedAt) {
+			t.Errorf("expected the response to carry the persisted ID and timestamps %+v, got %+v", stored[0], saved)
+		}
+	})
+
+	t.Run("rejects an invalid body", func(t *testing.T) {
+		s := newTestServer(t)
+		expectStatus(t, s.do(t, http.MethodPost, "/parse", `{}`), http.StatusBadRequest)
+		expectStatus(t, s.do(t, http.MethodPost, "/parse", `{"text":"x","on_duplicate":"maybe"}`), http.StatusBadRequest)
+		if calls := s.ai.Calls(); len(calls) != 0 {
+			t.Errorf("expected no AI calls, got %v", calls)
+		}
+	})
+
+	t.Run("reports AI failures", func(t *testing.T) {
+		s := newTestServer(t)
+		s.ai.Enqueue(testutil.AIResponse{Err: errors.New("upstream unavailable")})
+
+		expectStatus(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"}), http.StatusInternalServerError)
+	})
+
+	t.Run("skips suspected duplicates by default", func(t *testing.T) {
+		s := newTestServer(t)
+		s.seed(t, coffee())
+		s.ai.OnText("coffee 45", testutil.AIResponse{Transactions: []domain.Transaction{coffee()}})
+
+		rec := s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"})
+		expectStatus(t, rec, http.StatusOK)
+
+		response := decode[domain.ParseInputResponse](t, rec)
+		if len(response.Transactions) != 0 || len(response.Duplicates) != 1 ||
+			response.Duplicates[0].Resolution != domain.DuplicatePolicySkip {
+			t.Fatalf("unexpected response: %+v", response)
+		}
+
+		stored, _ := s.repo.GetTransactions(context.Background(), testUserID, 10, 0)
+		if len(stored) != 1 {
+			t.Fatalf("expected the duplicate not to be saved, got %d transactions", len(stored))
+		}
+	})
+
+	t.Run("force-saves suspected duplicates on request", func(t *testing.T) {
+		s := newTestServer(t)
+		s.seed(t, coffee())
+		s.ai.OnText("coffee 45", testutil.AIResponse{Transactions: []domain.Transaction{coffee()}})
+
+		rec := s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45", OnDuplicate: domain.DuplicatePolicyForce})
+		expectStatus(t, rec, http.StatusOK)
+
+		stored, _ := s.repo.GetTransactions(context.Background(), testUserID, 10, 0)
+		if len(stored) != 2 {
+			t.Fatalf("expected the duplicate to be saved, got %d transactions", len(stored))
+		}
+	})
+
+	t.Run("replays retries with the same idempotency key", func(t *testing.T) {
+		s := newTestServer(t)
+		s.ai.OnText("coffee 45", testutil.AIResponse{Transactions: []domain.Transaction{coffee()}})
+		request := domain.ParseInputRequest{Text: "coffee 45"}
+
+		first := s.do(t, http.MethodPost, "/parse", request, handlers.IdempotencyKeyHeader, "key-1")
+		expectStatus(t, first, http.StatusOK)
+
+		retry := s.do(t, http.MethodPost, "/parse", request, handlers.IdempotencyKeyHeader, "key-1")
+		expectStatus(t, retry, http.StatusOK)
+		if retry.Header().Get(handlers.IdempotentReplayedHeader) != "true" {
+			t.Error("expected replayed response")
+		}
+		if retry.Body.String() != first.Body.String() {
+			t.Errorf("expected replayed body %q, got %q", first.Body.String(), retry.Body.String())
+		}
+		if operationID := first.Header().Get(handlers.OperationIDHeader); operationID == "" || retry.Header().Get(handlers.OperationIDHeader) != operationID {
+			t.Errorf("expected replayed Operation-ID %q, got %q", operationID, retry.Header().Get(handlers.OperationIDHeader))
+		}
+		if calls := s.ai.Calls(); len(calls) != 1 {
+			t.Errorf("expected a single AI call, got %d", len(calls))
+		}
+
+		reused := s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "tea 30"}, handlers.IdempotencyKeyHeader, "key-1")
+		expectStatus(t, reused, http.StatusUnprocessableEntity)
+	})
+}
+
+func TestGetTransaction(t *testing.T) {
+	s := newTestServer(t)
+	stored := s.seed(t, coffee())
+
+	rec := s.do(t, http.MethodGet, fmt.Sprintf("/transactions/%d", stored[0].ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[domain.Transaction](t, rec); got.ID != stored[0].ID || got.Description != "Coffee at Starbucks" {
+		t.Errorf("unexpected transaction: %+v", got)
+	}
+
+	expectStatus(t, s.do(t, http.MethodGet, "/transactions/999", nil), http.StatusNotFound)
+	expectStatus(t, s.do(t, http.MethodGet, "/transactions/abc", nil), http.StatusBadRequest)
+
+	t.Run("another user's transaction", func(t *testing.T) {
+		other := "Bearer " + testutil.MintJWT(t, "other-user", testutil.TokenOptions{})
+		expectProblem(t, s.do(t, http.MethodGet, fmt.Sprintf("/transactions/%d", stored[0].ID), nil, "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+	})
+}
+
+func TestGetTransactions(t *testing.T) {
+	s := newTestServer(t)
+	older, newer := coffee(), coffee()
+	older.Description = "older"
+	newer.Description = "newer"
+	newer.Date = testDate.Add(24 * time.Hour)
+	s.seed(t, older, newer)
+
+	rec := s.do(t, http.MethodGet, "/transactions?limit=1&offset=0", nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	page := decode[struct {
+		Transactions []domain.Transaction `json:"transactions"`
+		Limit        int                  `json:"limit"`
+		Offset       int                  `json:"offset"`
+	}](t, rec)
+	if page.Limit != 1 || page.Offset != 0 || len(page.Transactions) != 1 || page.Transactions[0].Description != "newer" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	rec = s.do(t, http.MethodGet, "/transactions", nil)
+	expectStatus(t, rec, http.StatusOK)
+	page = decode[struct {
+		Transactions []domain.Transaction `json:"transactions"`
+		Limit        int                  `json:"limit"`
+		Offset       int                  `json:"offset"`
+	}](t, rec)
+	if page.Limit != 10 || page.Offset != 0 || len(page.Transactions) != 2 {
+		t.Fatalf("expected default pagination, got %+v", page)
+	}
+
+	rec = s.do(t, http.MethodGet, "/transactions?limit=-5&offset=nope", nil)
+	expectFieldErrors(t, rec, "query", "limit", "offset")
+
+	t.Run("hides the transactions of other users", func(t *testing.T) {
+		other := "Bearer " + testutil.MintJWT(t, "other-user", testutil.TokenOptions{})
+		rec := s.do(t, http.MethodGet, "/transactions", nil, "Authorization", other)
+		expectStatus(t, rec, http.StatusOK)
+		if page := decode[struct {
+			Transactions []domain.Transaction `json:"transactions"`
+		}](t, rec); len(page.Transactions) != 0 {
+			t.Errorf("expected no transactions, got %+v", page.Transactions)
+		}
+	})
+}
+
+func TestCreateTransactions(t *testing.T) {
+	s := newTestServer(t)
+	s.seed(t, coffee())
+
+	request := domain.CreateTransactionsRequest{Transactions: []domain.UpdateTransactionRequest{
+		{Amount: 45, Currency: "MXN", Category: domain.CategoryFood, Type: domain.Expense, Date: testDate, Description: "Coffee at Starbucks"},
+		{Amount: 500, Currency: "MXN", Category: domain.CategoryFreelance, Type: domain.Income, Date: testDate, Tags: []string{"client"}},
+	}}
+	rec := s.do(t, http.MethodPost, "/transactions", request)
+	expectStatus(t, rec, http.StatusCreated)
+
+	response := decode[domain.CreateTransactionsResponse](t, rec)
+	if len(response.Transactions) != 2 || response.Transactions[1].Category != domain.CategoryFreelance {
+		t.Fatalf("expected both transactions in request order, got %+v", response.Transactions)
+	}
+	for _, created := range response.Transactions {
+		got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, created.ID)
+		if got == nil || got.UserID != testUserID || got.Amount != created.Amount {
+			t.Errorf("expected transaction %d to be saved for the user, got %+v", created.ID, got)
+		}
+	}
+	if calls := s.ai.Calls(); len(calls) != 0 {
+		t.Error("expected no AI call")
+	}
+
+	t.Run("validates every transaction", func(t *testing.T) {
+		expectFieldErrors(t, s.do(t, http.MethodPost, "/transactions", `{"transactions":[
+			{"amount":45,"currency":"MXN","category":"food","type":"expense","date":"2024-01-15T12:00:00Z"},
+			{"amount":0,"currency":"MXN","category":"groceries","type":"expense","date":"2024-01-15T12:00:00Z"}
+		]}`), "body", "transactions[1].amount", "transactions[1].category")
+		expectFieldErrors(t, s.do(t, http.MethodPost, "/transactions", `{"transactions":[]}`), "body", "transactions")
+
+		stored, _ := s.repo.GetTransactions(context.Background(), testUserID, 10, 0)
+		if len(stored) != 3 {
+			t.Errorf("expected invalid requests to create nothing, got %d transactions", len(stored))
+		}
+	})
+}
+
+func TestGetDuplicateTransactions(t *testing.T) {
+	s := newTestServer(t)
+	dinner := coffee()
+	dinner.Amount = 300
+	dinner.Description = "Dinner"
+	theirs := coffee()
+	theirs.UserID = "other-user"
+	// Another user's identical coffee is not a duplicate of the caller's
+	s.seed(t, coffee(), coffee(), dinner, theirs)
+
+	rec := s.do(t, http.MethodGet, "/transactions/duplicates", nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	response := decode[struct {
+		Groups []domain.DuplicateGroup `json:"groups"`
+	}](t, rec)
+	if len(response.Groups) != 1 || len(response.Groups[0].Transactions) != 2 {
+		t.Fatalf("expected one group of two duplicates, got %+v", response.Groups)
+	}
+}
+
+// etag reads the current ETag of a transaction with GET /transactions/:id
+func (s *testServer) etag(t *testing.T, path string) string {
+	t.Helper()
+	rec := s.do(t, http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	etag := rec.Header().Get(handlers.ETagHeader)
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+	return etag
+}
+
+func TestUpdateTransaction(t *testing.T) {
+	s := newTestServer(t)
+	stored := s.seed(t, coffee())
+	path := fmt.Sprintf("/transactions/%d", stored[0].ID)
+
+	request := domain.UpdateTransactionRequest{
+		Amount:      60,
+		Currency:    "MXN",
+		Category:    domain.CategoryEntertainment,
+		Type:        domain.Expense,
+		Date:        testDate,
+		Description: "Movie",
+		Tags:        []string{"weekend"},
+	}
+
+	etag := s.etag(t, path)
+	rec := s.do(t, http.MethodPut, path, request, handlers.IfMatchHeader, etag, handlers.IdempotencyKeyHeader, "key-1")
+	expectStatus(t, rec, http.StatusOK)
+	newETag := rec.Header().Get(handlers.ETagHeader)
+	if newETag == "" || newETag == etag || newETag != s.etag(t, path) {
+		t.Errorf("expected the response to carry the new ETag, got %q", newETag)
+	}
+
+	retry := s.do(t, http.MethodPut, path, request, handlers.IfMatchHeader, etag, handlers.IdempotencyKeyHeader, "key-1")
+	expectStatus(t, retry, http.StatusOK)
+	if retry.Header().Get(handlers.IdempotentReplayedHeader) != "true" || retry.Header().Get(handlers.ETagHeader) != newETag {
+		t.Errorf("expected the replayed response to carry the new ETag, got %v", retry.Header())
+	}
+
+	got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID)
+	if got.Amount != 60 || got.Category != domain.CategoryEntertainment || got.Description != "Movie" || len(got.Tags) != 1 {
+		t.Errorf("unexpected transaction after update: %+v", got)
+	}
+
+	expectStatus(t, s.do(t, http.MethodPut, path, `{"amount":-1}`, handlers.IfMatchHeader, "*"), http.StatusBadRequest)
+	expectStatus(t, s.do(t, http.MethodPut, "/transactions/999", request, handlers.IfMatchHeader, "*"), http.StatusNotFound)
+	expectStatus(t, s.do(t, http.MethodPut, "/transactions/abc", request, handlers.IfMatchHeader, "*"), http.StatusBadRequest)
+
+	t.Run("another user's transaction", func(t *testing.T) {
+		other := "Bearer " + testutil.MintJWT(t, "other-user", testutil.TokenOptions{})
+		request.Amount = 1
+		expectProblem(t, s.do(t, http.MethodPut, path, request, handlers.IfMatchHeader, newETag, "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+		if got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID); got.Amount != 60 {
+			t.Errorf("expected the transaction to be unchanged, got %+v", got)
+		}
+	})
+}
+
+func TestPatchTransaction(t *testing.T) {
+	s := newTestServer(t)
+	transaction := coffee()
+	transaction.Account = "Checking"
+	transaction.Tags = []string{"morning"}
+	stored := s.seed(t, transaction)
+	path := fmt.Sprintf("/transactions/%d", stored[0].ID)
+
+	rec := s.do(t, http.MethodPatch, path, `{"amount":50,"account":null}`, handlers.IfMatchHeader, s.etag(t, path))
+	expectStatus(t, rec, http.StatusOK)
+
+	got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID)
+	if got.Amount != 50 || got.Account != "" || got.Description != "Coffee at Starbucks" || got.Category != domain.CategoryFood ||
+		len(got.Tags) != 1 || !got.Date.Equal(testDate) {
+		t.Errorf("expected only amount and account to change, got %+v", got)
+	}
+
+	t.Run("validates the patch", func(t *testing.T) {
+		expectFieldErrors(t, s.do(t, http.MethodPatch, path, `{"category":"groceries","amount":null}`, handlers.IfMatchHeader, "*"),
+			"body", "category", "amount")
+	})
+
+	t.Run("missing transaction", func(t *testing.T) {
+		expectStatus(t, s.do(t, http.MethodPatch, "/transactions/999", `{"amount":1}`, handlers.IfMatchHeader, "*"), http.StatusNotFound)
+	})
+
+	t.Run("another user's transaction", func(t *testing.T) {
+		other := "Bearer " + testutil.MintJWT(t, "other-user", testutil.TokenOptions{})
+		for _, ifMatch := range []string{s.etag(t, path), "*"} {
+			expectProblem(t, s.do(t, http.MethodPatch, path, `{"amount":1}`, handlers.IfMatchHeader, ifMatch, "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+		}
+		if got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID); got.Amount != 50 {
+			t.Errorf("expected the transaction to be unchanged, got %+v", got)
+		}
+	})
+}
+
+func TestConditionalWrites(t *testing.T) {
+	s := newTestServer(t)
+	stored := s.seed(t, coffee())
+	path := fmt.Sprintf("/transactions/%d", stored[0].ID)
+	stale := s.etag(t, path)
+
+	expectStatus(t, s.do(t, http.MethodPatch, path, `{"description":"first device"}`, handlers.IfMatchHeader, stale), http.StatusOK)
+
+	t.Run("require If-Match", func(t *testing.T) {
+		expectProblem(t, s.do(t, http.MethodPatch, path, `{"amount":1}`), http.StatusPreconditionRequired, handlers.CodePreconditionRequired)
+		expectProblem(t, s.do(t, http.MethodPut, path, coffee()), http.StatusPreconditionRequired, handlers.CodePreconditionRequired)
+		expectProblem(t, s.do(t, http.MethodDelete, path, nil), http.StatusPreconditionRequired, handlers.CodePreconditionRequired)
+	})
+
+	t.Run("reject stale versions", func(t *testing.T) {
+		request := domain.UpdateTransactionRequest{Amount: 1, Currency: "MXN", Category: domain.CategoryFood, Type: domain.Expense, Date: testDate}
+		for _, ifMatch := range []string{stale, "W/" + stale, "not-an-etag"} {
+			expectProblem(t, s.do(t, http.MethodPatch, path, `{"description":"second device"}`, handlers.IfMatchHeader, ifMatch),
+				http.StatusPreconditionFailed, handlers.CodePreconditionFailed)
+			expectProblem(t, s.do(t, http.MethodPut, path, request, handlers.IfMatchHeader, ifMatch),
+				http.StatusPreconditionFailed, handlers.CodePreconditionFailed)
+			expectProblem(t, s.do(t, http.MethodDelete, path, nil, handlers.IfMatchHeader, ifMatch),
+				http.StatusPreconditionFailed, handlers.CodePreconditionFailed)
+		}
+
+		got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID)
+		if got == nil || got.Description != "first device" {
+			t.Errorf("expected the first write to be kept, got %+v", got)
+		}
+	})
+
+	t.Run("match any current version with *", func(t *testing.T) {
+		expectStatus(t, s.do(t, http.MethodPatch, path, `{"amount":50}`, handlers.IfMatchHeader, "*"), http.StatusOK)
+		if got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID); got.Amount != 50 || got.Description != "first device" {
+			t.Errorf("expected the patch to apply to the current version, got %+v", got)
+		}
+
+		request := domain.UpdateTransactionRequest{Amount: 60, Currency: "MXN", Category: domain.CategoryFood, Type: domain.Expense, Date: testDate}
+		expectStatus(t, s.do(t, http.MethodPut, path, request, handlers.IfMatchHeader, "*"), http.StatusOK)
+		if got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID); got.Amount != 60 || got.Description != "" {
+			t.Errorf("expected the update to replace the current version, got %+v", got)
+		}
+
+		expectStatus(t, s.do(t, http.MethodDelete, path, nil, handlers.IfMatchHeader, "*"), http.StatusOK)
+
+		// With no current version, * matches nothing
+		expectStatus(t, s.do(t, http.MethodPatch, path, `{"amount":1}`, handlers.IfMatchHeader, "*"), http.StatusNotFound)
+		expectStatus(t, s.do(t, http.MethodPut, path, request, handlers.IfMatchHeader, "*"), http.StatusNotFound)
+		expectStatus(t, s.do(t, http.MethodDelete, path, nil, handlers.IfMatchHeader, "*"), http.StatusNotFound)
+	})
+}
+
+// racingRepository lands another write on the first transaction it reads, as a second
+// device saving between the read and the write of a request would
+type racingRepository struct {
+	*infra.MemoryTransactionRepository
+	raced bool
+}
+
+func (r *racingRepository) GetTransactionByID(ctx context.Context, userID string, id int) (*domain.Transaction, error) {
+	transaction, err := r.MemoryTransactionRepository.GetTransactionByID(ctx, userID, id)
+	if err != nil || transaction == nil || r.raced {
+		return transaction, err
+	}
+	r.raced = true
+
+	concurrent := *transaction
+	concurrent.Tags = []string{"second device"}
+	concurrent.UpdatedAt = time.Time{}
+	if err := r.UpdateTransaction(ctx, &concurrent); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func TestPatchAnyVersionKeepsConcurrentWrites(t *testing.T) {
+	repo := infra.NewMemoryTransactionRepository()
+	racing := &racingRepository{MemoryTransactionRepository: repo}
+	transactionService := services.NewTransactionService(racing, services.NewRuleService(infra.NewMemoryRuleRepository(), repo), services.DuplicateDetectionConfig{})
+	stored := []domain.Transaction{coffee()}
+	if err := testutil.SaveTransactions(context.Background(), repo, stored); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(handlers.NewErrorMiddleware().Handle())
+	router.Use(handlers.NewAuthMiddleware(infra.NewSupabaseAuthService(testutil.AuthConfig()), services.NewAPIKeyService(infra.NewMemoryAPIKeyRepository())).Authenticate())
+	handlers.NewTransactionHandler(nil, nil, nil, transactionService).SetupRoutes(router)
+
+	req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/transactions/%d", stored[0].ID), strings.NewReader(`{"amount":50}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.IfMatchHeader, "*")
+	req.Header.Set("Authorization", "Bearer "+testutil.MintJWT(t, testUserID, testutil.TokenOptions{Email: "user@example.com"}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	expectStatus(t, rec, http.StatusOK)
+
+	got, _ := repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID)
+	if got.Amount != 50 || len(got.Tags) != 1 || got.Tags[0] != "second device" {
+		t.Errorf("expected the patch to apply on top of the concurrent write, got %+v", got)
+	}
+}
+
+func TestDeleteTransaction(t *testing.T) {
+	s := newTestServer(t)
+	stored := s.seed(t, coffee())
+	path := fmt.Sprintf("/transactions/%d", stored[0].ID)
+
+	other := "Bearer " + testutil.MintJWT(t, "other-user", testutil.TokenOptions{})
+	expectProblem(t, s.do(t, http.MethodDelete, path, nil, handlers.IfMatchHeader, "*", "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+	if got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID); got == nil {
+		t.Fatal("expected another user's delete to leave the transaction")
+	}
+
+	expectStatus(t, s.do(t, http.MethodDelete, path, nil, handlers.IfMatchHeader, s.etag(t, path)), http.StatusOK)
+
+	if got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID); got != nil {
+		t.Errorf("expected transaction to be deleted, got %+v", got)
+	}
+
+	expectStatus(t, s.do(t, http.MethodDelete, path, nil, handlers.IfMatchHeader, "*"), http.StatusNotFound)
+	expectStatus(t, s.do(t, http.MethodDelete, "/transactions/abc", nil, handlers.IfMatchHeader, "*"), http.StatusBadRequest)
+}
+
+func TestTrash(t *testing.T) {
+	s := newTestServer(t)
+	stored := s.seed(t, coffee())
+	path := fmt.Sprintf("/transactions/%d", stored[0].ID)
+
+	other := "Bearer " + testutil.MintJWT(t, "other-user", testutil.TokenOptions{})
+	expectProblem(t, s.do(t, http.MethodDelete, path, nil, handlers.IfMatchHeader, "*", "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+	if got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[0].ID); got == nil {
+		t.Fatal("expected another user's delete to leave the transaction")
+	}
+
+	expectStatus(t, s.do(t, http.MethodDelete, path, nil, handlers.IfMatchHeader, s.etag(t, path)), http.StatusOK)
+	expectStatus(t, s.do(t, http.MethodGet, path, nil), http.StatusNotFound)
+
+	t.Run("hides the trash of other users", func(t *testing.T) {
+		other := "Bearer " + testutil.MintJWT(t, "other-user", testutil.TokenOptions{})
+		rec := s.do(t, http.MethodGet, "/transactions/trash", nil, "Authorization", other)
+		expectStatus(t, rec, http.StatusOK)
+		if trash := decode[domain.TransactionListResponse](t, rec); len(trash.Transactions) != 0 {
+			t.Errorf("expected another user's trash to be empty, got %+v", trash.Transactions)
+		}
+		expectProblem(t, s.do(t, http.MethodPost, path+"/restore", nil, "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+	})
+
+	rec := s.do(t, http.MethodGet, "/transactions/trash", nil)
+	expectStatus(t, rec, http.StatusOK)
+	trash := decode[domain.TransactionListResponse](t, rec)
+	if len(trash.Transactions) != 1 || trash.Transactions[0].ID != stored[0].ID || trash.Transactions[0].DeletedAt == nil {
+		t.Fatalf("expected the deleted transaction in the trash, got %+v", trash.Transactions)
+	}
+
+	rec = s.do(t, http.MethodPost, path+"/restore", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if restored := decode[domain.Transaction](t, rec); restored.ID != stored[0].ID || restored.DeletedAt != nil || rec.Header().Get(handlers.ETagHeader) != s.etag(t, path) {
+		t.Errorf("expected the restored transaction with its new ETag, got %+v", restored)
+	}
+
+	expectStatus(t, s.do(t, http.MethodPost, path+"/restore", nil), http.StatusNotFound)
+	expectStatus(t, s.do(t, http.MethodPost, "/transactions/abc/restore", nil), http.StatusBadRequest)
+}
+
+func TestTransactionHistory(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := s.do(t, http.MethodPost, "/transactions", domain.CreateTransactionsRequest{Transactions: []domain.UpdateTransactionRequest{
+		{Amount: 45, Currency: "MXN", Category: domain.CategoryFood, Type: domain.Expense, Date: testDate, Description: "Coffee at Starbucks"},
+	}}, handlers.RequestIDHeader, "request-1")
+	expectStatus(t, rec, http.StatusCreated)
+	created := decode[domain.CreateTransactionsResponse](t, rec).Transactions[0]
+	path := fmt.Sprintf("/transactions/%d", created.ID)
+
+	key := s.createAPIKey(t, domain.CreateAPIKeyRequest{
+		Name:   "sync",
+		Scopes: []domain.Scope{domain.ScopeTransactionsRead, domain.ScopeTransactionsWrite},
+	})
+	expectStatus(t, s.do(t, http.MethodPatch, path, `{"amount":60}`,
+		"Authorization", "Bearer "+key.Key, handlers.IfMatchHeader, s.etag(t, path)), http.StatusOK)
+
+	rec = s.do(t, http.MethodGet, path+"/history", nil)
+	expectStatus(t, rec, http.StatusOK)
+	events := decode[domain.TransactionHistoryResponse](t, rec).Events
+	if len(events) != 2 {
+		t.Fatalf("expected a create and an update, got %+v", events)
+	}
+	if create := events[0]; create.Action != domain.AuditActionCreate || create.ActorID != testUserID ||
+		create.Source != domain.SourceManual || create.RequestID != "request-1" || create.Before != nil || create.After == nil || create.After.Amount != 45 {
+		t.Errorf("unexpected create event: %+v", create)
+	}
+	if update := events[1]; update.Action != domain.AuditActionUpdate || update.ActorID != testUserID || update.Source != domain.SourceAPIKey ||
+		update.RequestID == "" || update.Before == nil || update.Before.Amount != 45 || update.After == nil || update.After.Amount != 60 {
+		t.Errorf("unexpected update event: %+v", update)
+	}
+
+	t.Run("missing transaction", func(t *testing.T) {
+		expectStatus(t, s.do(t, http.MethodGet, "/transactions/999/history", nil), http.StatusNotFound)
+	})
+
+	t.Run("another user's transaction", func(t *testing.T) {
+		other := "Bearer " + testutil.MintJWT(t, "other-user", testutil.TokenOptions{})
+		expectProblem(t, s.do(t, http.MethodGet, path+"/history", nil, "Authorization", other), http.StatusNotFound, handlers.CodeNotFound)
+	})
+}
+
+func TestUndoOperation(t *testing.T) {
+	s := newTestServer(t)
+	stored := s.seed(t, coffee(), coffee())
+	updatePath := fmt.Sprintf("/transactions/%d", stored[0].ID)
+	deletePath := fmt.Sprintf("/transactions/%d", stored[1].ID)
+
+	request := fmt.Sprintf(`{"operations":[
+		{"action":"create","transaction":{"amount":12,"currency":"MXN","category":"transport","type":"expense","date":"2024-01-15T12:00:00Z","description":"Bus"}},
+		{"action":"update","id":%d,"if_match":"*","transaction":{"amount":50,"currency":"MXN","category":"food","type":"expense","date":"2024-01-15T12:00:00Z","description":"Lunch"}},
+		{"action":"delete","id":%d,"if_match":"*"}
+	]}`, stored[0].ID, stored[1].ID)
+	rec := s.do(t, http.MethodPost, "/transactions/bulk", request)
+	expectStatus(t, rec, http.StatusOK)
+	response := decode[domain.BulkResponse](t, rec)
+	if response.OperationID == "" || rec.Header().Get(handlers.OperationIDHeader) != response.OperationID {
+		t.Fatalf("expected the operation ID in the body and header, got %q and %q", response.OperationID, rec.Header().Get(handlers.OperationIDHeader))
+	}
+	createdPath := fmt.Sprintf("/transactions/%d", response.Results[0].ID)
+
+	rec = s.do(t, http.MethodPost, "/operations/"+response.OperationID+"/undo", nil)
+	expectStatus(t, rec, http.StatusOK)
+	undo := decode[domain.Operation](t, rec)
+	if undo.ID == "" || undo.ID == response.OperationID || rec.Header().Get(handlers.OperationIDHeader) != undo.ID || len(undo.Events) != 3 {
+		t.Fatalf("unexpected undo operation: %+v", undo)
+	}
+	for _, event := range undo.Events {
+		if event.RevertsOperationID != response.OperationID || event.ActorID != testUserID {
+			t.Errorf("unexpected undo event: %+v", event)
+		}
+	}
+	expectStatus(t, s.do(t, http.MethodGet, createdPath, nil), http.StatusNotFound)
+	expectStatus(t, s.do(t, http.MethodGet, deletePath, nil), http.StatusOK)
+	if got := decode[domain.Transaction](t, s.do(t, http.MethodGet, updatePath, nil)); got.Amount != stored[0].Amount || got.Description != stored[0].Description {
+		t.Errorf("expected the update to be reverted, got %+v", got)
+	}
+
+	t.Run("already undone", func(t *testing.T) {
+		expectProblem(t, s.do(t, http.MethodPost, "/operations/"+response.OperationID+"/undo", nil), http.StatusConflict, handlers.CodeConflict)
+	})
+
+	t.Run("undoing the undo redoes the changes", func(t *testing.T) {
+		expectStatus(t, s.do(t, http.MethodPost, "/operations/"+undo.ID+"/undo", nil), http.StatusOK)
+		expectStatus(t, s.do(t, http.MethodGet, createdPath, nil), http.StatusOK)
+		expectStatus(t, s.do(t, http.MethodGet, deletePath, nil), http.StatusNotFound)
+		if got := decode[domain.Transaction](t, s.do(t, http.MethodGet, updatePath, nil)); got.Amount != 50 {
+			t.Errorf("expected the update to be applied again, got %+v", got)
+		}
+	})
+
+	t.Run("transaction changed since", func(t *testing.T) {
+		rec := s.do(t, http.MethodPatch, updatePath, `{"amount":70}`, handlers.IfMatchHeader, "*")
+		expectStatus(t, rec, http.StatusOK)
+		operationID := rec.Header().Get(handlers.OperationIDHeader)
+		expectStatus(t, s.do(t, http.MethodPatch, updatePath, `{"amount":80}`, handlers.IfMatchHeader, "*"), http.StatusOK)
+
+		expectProblem(t, s.do(t, http.MethodPost, "/operations/"+operationID+"/undo", nil), http.StatusConflict, handlers.CodeConflict)
+		if got := decode[domain.Transaction](t, s.do(t, http.MethodGet, updatePath, nil)); got.Amount != 80 {
+			t.Errorf("expected the later change to be kept, got %+v", got)
+		}
+	})
+
+	t.Run("another user's operation", func(t *testing.T) {
+		rec := s.do(t, http.MethodDelete, createdPath, nil, handlers.IfMatchHeader, "*")
+		expectStatus(t, rec, http.StatusOK)
+		other := testutil.MintJWT(t, "other-user", testutil.TokenOptions{Email: "other@example.com"})
+		expectProblem(t, s.do(t, http.MethodPost, "/operations/"+rec.Header().Get(handlers.OperationIDHeader)+"/undo", nil,
+			"Authorization", "Bearer "+other), http.StatusNotFound, handlers.CodeNotFound)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		expectProblem(t, s.do(t, http.MethodPost, "/operations/missing/undo", nil), http.StatusNotFound, handlers.CodeNotFound)
+	})
+}
+
+func TestBulkTransactions(t *testing.T) {
+	s := newTestServer(t)
+	stored := s.seed(t, coffee(), coffee(), coffee())
+	updatePath := fmt.Sprintf("/transactions/%d", stored[0].ID)
+	deletePath := fmt.Sprintf("/transactions/%d", stored[1].ID)
+
+	request := fmt.Sprintf(`{"operations":[
+		{"action":"create","transaction":{"amount":12,"currency":"MXN","category":"transport","type":"expense","date":"2024-01-15T12:00:00Z","description":"Bus"}},
+		{"action":"update","id":%d,"if_match":%q,"transaction":{"amount":50,"currency":"MXN","category":"food","type":"expense","date":"2024-01-15T12:00:00Z","description":"Lunch"}},
+		{"action":"delete","id":%d,"if_match":"*"}
+	]}`, stored[0].ID, s.etag(t, updatePath), stored[1].ID)
+	rec := s.do(t, http.MethodPost, "/transactions/bulk", request)
+	expectStatus(t, rec, http.StatusOK)
+
+	response := decode[domain.BulkResponse](t, rec)
+	if len(response.Results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", response.Results)
+	}
+	created := response.Results[0]
+	if created.Action != domain.BulkActionCreate || created.ID == 0 || created.ETag == "" || created.Transaction == nil || created.Transaction.Description != "Bus" {
+		t.Errorf("unexpected create result %+v", created)
+	}
+	if got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, created.ID); got == nil || got.UserID != testUserID {
+		t.Errorf("expected the created transaction to be owned by the user, got %+v", got)
+	}
+	if updated := response.Results[1]; updated.ID != stored[0].ID || updated.ETag != s.etag(t, updatePath) {
+		t.Errorf("expected the update result to carry the new ETag, got %+v", updated)
+	}
+	if deleted := response.Results[2]; deleted.ID != stored[1].ID || deleted.ETag != "" || deleted.Transaction != nil {
+		t.Errorf("unexpected delete result %+v", deleted)
+	}
+	expectStatus(t, s.do(t, http.MethodGet, fmt.Sprintf("/transactions/%d", created.ID), nil), http.StatusOK)
+	expectStatus(t, s.do(t, http.MethodGet, deletePath, nil), http.StatusNotFound)
+
+	t.Run("applies nothing when an operation fails", func(t *testing.T) {
+		request := fmt.Sprintf(`{"operations":[
+			{"action":"delete","id":%d},
+			{"action":"delete","id":999}
+		]}`, stored[2].ID)
+		problem := expectProblem(t, s.do(t, http.MethodPost, "/transactions/bulk", request), http.StatusNotFound, handlers.CodeNotFound)
+		if len(problem.Errors) != 1 || problem.Errors[0].Field != "operations[1]" {
+			t.Errorf("expected the failing operation to be named, got %+v", problem.Errors)
+		}
+		expectStatus(t, s.do(t, http.MethodGet, fmt.Sprintf("/transactions/%d", stored[2].ID), nil), http.StatusOK)
+	})
+
+	t.Run("rejects stale operations", func(t *testing.T) {
+		request := fmt.Sprintf(`{"operations":[{"action":"delete","id":%d,"if_match":"\"1\""}]}`, stored[2].ID)
+		expectProblem(t, s.do(t, http.MethodPost, "/transactions/bulk", request), http.StatusPreconditionFailed, handlers.CodePreconditionFailed)
+	})
+
+	t.Run("cannot change another user's transactions", func(t *testing.T) {
+		theirs := []domain.Transaction{coffee()}
+		theirs[0].UserID = "other-user"
+		if err := testutil.SaveTransactions(context.Background(), s.repo, theirs); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		for _, request := range []string{
+			fmt.Sprintf(`{"operations":[{"action":"update","id":%d,"transaction":{"amount":1,"currency":"MXN","category":"food","type":"expense","date":"2024-01-15T12:00:00Z"}}]}`, theirs[0].ID),
+			fmt.Sprintf(`{"operations":[{"action":"delete","id":%d}]}`, theirs[0].ID),
+		} {
+			expectProblem(t, s.do(t, http.MethodPost, "/transactions/bulk", request), http.StatusNotFound, handlers.CodeNotFound)
+		}
+		if got, _ := s.repo.GetTransactionByID(context.Background(), "other-user", theirs[0].ID); got == nil || got.Amount != theirs[0].Amount {
+			t.Errorf("expected the other user's transaction to be unchanged, got %+v", got)
+		}
+	})
+
+	t.Run("validates operations", func(t *testing.T) {
+		expectFieldErrors(t, s.do(t, http.MethodPost, "/transactions/bulk", `{"operations":[
+			{"action":"update","transaction":{"amount":1,"currency":"MXN","category":"food","type":"expense","date":"2024-01-15T12:00:00Z"}},
+			{"action":"create"},
+			{"action":"delete","id":1,"if_match":"W/\"1\""}
+		]}`), "body", "operations[0].id", "operations[1].transaction", "operations[2].if_match")
+		expectFieldErrors(t, s.do(t, http.MethodPost, "/transactions/bulk", `{"operations":[{"action":"rename","id":1}]}`),
+			"body", "operations[0].action")
+		expectFieldErrors(t, s.do(t, http.MethodPost, "/transactions/bulk", `{}`), "body", "operations")
+	})
+
+	t.Run("applies an action to a filter", func(t *testing.T) {
+		rec := s.do(t, http.MethodPost, "/transactions/bulk", `{"filter":{"description_contains":"starbucks"},"action":"set_category","category":"entertainment"}`)
+		expectStatus(t, rec, http.StatusOK)
+
+		response := decode[domain.BulkResponse](t, rec)
+		if len(response.Results) != 1 || response.Results[0].ID != stored[2].ID || response.Results[0].Action != domain.BulkActionSetCategory {
+			t.Fatalf("expected the remaining Starbucks transaction to be changed, got %+v", response.Results)
+		}
+		got, _ := s.repo.GetTransactionByID(context.Background(), testUserID, stored[2].ID)
+		if got.Category != domain.CategoryEntertainment {
+			t.Errorf("expected category entertainment, got %q", got.Category)
+		}
+
+		expectFieldErrors(t, s.do(t, http.MethodPost, "/transactions/bulk", `{"filter":{},"action":"set_category"}`),
+			"body", "filter", "category")
+	})
+}
+
+func TestParseInputQuota(t *testing.T) {
+	s := newTestServer(t)
+	s.ai.OnText("coffee 45", testutil.AIResponse{
+		Transactions: []domain.Transaction{coffee()},
+		TotalTokens:  testMonthlyTokenQuota,
+	})
+	request := domain.ParseInputRequest{Text: "coffee 45", OnDuplicate: domain.DuplicatePolicyForce}
+
+	expectStatus(t, s.do(t, http.MethodPost, "/parse", request), http.StatusOK)
+
+	rec := s.do(t, http.MethodPost, "/parse", request)
+	expectStatus(t, rec, http.StatusTooManyRequests)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+	if calls := s.ai.Calls(); len(calls) != 1 {
+		t.Errorf("expected the AI not to be called once the quota is exhausted, got %d calls", len(calls))
+	}
+
+	// Other users keep their own quota
+	other := testutil.MintJWT(t, "other-user", testutil.TokenOptions{})
+	expectStatus(t, s.do(t, http.MethodPost, "/parse", request, "Authorization", "Bearer "+other), http.StatusOK)
+}
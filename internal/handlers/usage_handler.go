@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// usageDateFormat is the format of the from and to query parameters of usage reports
+const usageDateFormat = "2006-01-02"
+
+// UsageHandler handles HTTP requests related to AI usage and cost
+type UsageHandler struct {
+	usageService domain.AIUsageService
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(usageService domain.AIUsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// GetUsage handles GET /usage, reporting the caller's own AI usage
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.report(c, userID)
+}
+
+// GetAllUsage handles GET /admin/usage, reporting AI usage across all users
+func (h *UsageHandler) GetAllUsage(c *gin.Context) {
+	h.report(c, "")
+}
+
+// report responds with the usage report of userID, or of every user when it is empty
+func (h *UsageHandler) report(c *gin.Context, userID string) {
+	filter, err := parseUsageFilter(c, time.Now())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	filter.UserID = userID
+
+	report, err := h.usageService.GetUsageReport(c.Request.Context(), filter)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("failed to get usage: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseUsageFilter reads the inclusive from and to dates of a report, defaulting to the
+// current UTC month
+func parseUsageFilter(c *gin.Context, now time.Time) (domain.AIUsageFilter, error) {
+	now = now.UTC()
+	filter := domain.AIUsageFilter{
+		From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if from := c.Query("from"); from != "" {
+		date, err := time.Parse(usageDateFormat, from)
+		if err != nil {
+			return filter, domain.NewValidationError("from must be a date in YYYY-MM-DD format")
+		}
+		filter.From = date
+	}
+
+	if to := c.Query("to"); to != "" {
+		date, err := time.Parse(usageDateFormat, to)
+		if err != nil {
+			return filter, domain.NewValidationError("to must be a date in YYYY-MM-DD format")
+		}
+		filter.To = date.AddDate(0, 0, 1)
+	}
+
+	if !filter.From.Before(filter.To) {
+		return filter, domain.NewValidationError("from must not be after to")
+	}
+
+	return filter, nil
+}
+
+// SetupRoutes sets up the HTTP routes
+func (h *UsageHandler) SetupRoutes(router gin.IRouter) {
+	router.GET("/usage", RequireScope(domain.ScopeUsageRead), h.GetUsage)
+	router.GET("/admin/usage", RequirePermission(domain.PermissionViewUsage), h.GetAllUsage)
+}
+
+// DescribeRoutes adds the routes of SetupRoutes to the OpenAPI document
+func (h *UsageHandler) DescribeRoutes(doc *openapi.Document) {
+	period := func(route *openapi.Route) *openapi.Route {
+		return route.
+			Query("from", openapi.String().WithFormat("date"), "First day of the period (UTC); defaults to the start of the current month").
+			Query("to", openapi.String().WithFormat("date"), "Last day of the period, inclusive; defaults to the end of the current month").
+			ResponseRefs(http.StatusBadRequest)
+	}
+
+	period(protectedRoute(doc, http.MethodGet, "/usage", domain.ScopeUsageRead).
+		Summary("Report the caller's AI usage").
+		Tags("usage").
+		Response(http.StatusOK, "Usage and estimated cost per day and model, with the monthly quota", domain.AIUsageReport{}))
+
+	period(adminRoute(doc, http.MethodGet, "/admin/usage", domain.PermissionViewUsage).
+		Summary("Report AI usage across all users").
+		Response(http.StatusOK, "Usage and estimated cost per day and model", domain.AIUsageReport{}))
+}
@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+func TestUsageEndpoints(t *testing.T) {
+	s := newTestServer(t)
+	s.ai.OnText("coffee 45", testutil.AIResponse{
+		Transactions:     []domain.Transaction{coffee()},
+		PromptTokens:     300,
+		CompletionTokens: 100,
+		TotalTokens:      400,
+	})
+	s.ai.OnText("garbled", testutil.AIResponse{Err: errors.New("upstream unavailable")})
+
+	expectStatus(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"}), http.StatusOK)
+	expectStatus(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "garbled"}), http.StatusInternalServerError)
+
+	other := []string{"Authorization", "Bearer " + testutil.MintJWT(t, "user-456", testutil.TokenOptions{})}
+	expectStatus(t, s.do(t, http.MethodPost, "/parse", domain.ParseInputRequest{Text: "coffee 45"}, other...), http.StatusOK)
+
+	today := time.Now().UTC().Format("2006-01-02")
+
+	t.Run("reports the caller's usage with cost and quota", func(t *testing.T) {
+		rec := s.do(t, http.MethodGet, "/usage", nil)
+		expectStatus(t, rec, http.StatusOK)
+
+		report := decode[domain.AIUsageReport](t, rec)
+		if len(report.Days) != 1 || report.Days[0].Day != today || report.Days[0].Model != testutil.FakeAIModel {
+			t.Fatalf("unexpected days: %+v", report.Days)
+		}
+		totals := report.Totals
+		if totals.Calls != 2 || totals.Failures != 1 || totals.PromptTokens != 300 || totals.CompletionTokens != 100 {
+			t.Errorf("unexpected totals: %+v", totals)
+		}
+		// 300 prompt tokens at $1/M plus 100 completion tokens at $2/M
+		if math.Abs(totals.EstimatedCost-0.0005) > 1e-12 {
+			t.Errorf("expected estimated cost 0.0005, got %v", totals.EstimatedCost)
+		}
+		if report.Quota == nil || report.Quota.Used != 400 || report.Quota.Limit != testMonthlyTokenQuota {
+			t.Errorf("unexpected quota: %+v", report.Quota)
+		}
+	})
+
+	t.Run("filters by period", func(t *testing.T) {
+		rec := s.do(t, http.MethodGet, "/usage?from=2020-01-01&to=2020-01-31", nil)
+		expectStatus(t, rec, http.StatusOK)
+
+		report := decode[domain.AIUsageReport](t, rec)
+		if len(report.Days) != 0 || report.Totals.Calls != 0 {
+			t.Errorf("expected no usage in 2020, got %+v", report)
+		}
+
+		expectStatus(t, s.do(t, http.MethodGet, "/usage?from=yesterday", nil), http.StatusBadRequest)
+		expectStatus(t, s.do(t, http.MethodGet, "/usage?from=2024-02-01&to=2024-01-01", nil), http.StatusBadRequest)
+	})
+
+	t.Run("requires the usage scope for API keys", func(t *testing.T) {
+		key := s.createAPIKey(t, domain.CreateAPIKeyRequest{Name: "reader", Scopes: []domain.Scope{domain.ScopeTransactionsRead}})
+		expectStatus(t, s.do(t, http.MethodGet, "/usage", nil, "Authorization", "Bearer "+key.Key), http.StatusForbidden)
+
+		key = s.createAPIKey(t, domain.CreateAPIKeyRequest{Name: "billing", Scopes: []domain.Scope{domain.ScopeUsageRead}})
+		expectStatus(t, s.do(t, http.MethodGet, "/usage", nil, "Authorization", "Bearer "+key.Key), http.StatusOK)
+	})
+
+	t.Run("aggregates every user for admins", func(t *testing.T) {
+		expectStatus(t, s.do(t, http.MethodGet, "/admin/usage", nil), http.StatusForbidden)
+
+		admin := testutil.MintJWT(t, "admin-1", testutil.TokenOptions{AppRoles: []string{domain.RoleAdmin}})
+		rec := s.do(t, http.MethodGet, "/admin/usage", nil, "Authorization", "Bearer "+admin)
+		expectStatus(t, rec, http.StatusOK)
+
+		report := decode[domain.AIUsageReport](t, rec)
+		if report.Totals.Calls != 3 || report.Totals.TotalTokens != 800 {
+			t.Errorf("unexpected totals: %+v", report.Totals)
+		}
+		if report.Quota != nil {
+			t.Errorf("expected no quota in a cross-user report, got %+v", report.Quota)
+		}
+	})
+}
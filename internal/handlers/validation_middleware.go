@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/openapi"
+)
+
+// ValidationMiddleware validates path and query parameters and JSON request bodies against
+// the OpenAPI document, so the served schema and the accepted requests cannot drift
+type ValidationMiddleware struct {
+	doc *openapi.Document
+}
+
+// NewValidationMiddleware creates a new validation middleware for the operations of doc
+func NewValidationMiddleware(doc *openapi.Document) *ValidationMiddleware {
+	return &ValidationMiddleware{
+		doc: doc,
+	}
+}
+
+// Handle is the middleware function rejecting invalid requests with the list of invalid
+// fields. Routes missing from the document are not validated.
+func (m *ValidationMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := m.doc.Operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		fields := m.validateParameters(c, op)
+		bodyFields, err := m.validateBody(c, op)
+		if err != nil {
+			abortWithError(c, invalidBodyError(err))
+			return
+		}
+		fields = append(fields, bodyFields...)
+
+		if len(fields) > 0 {
+			abortWithError(c, domain.NewFieldValidationError(fields))
+			return
+		}
+		c.Next()
+	}
+}
+
+// validateParameters validates the path and query parameters present in the request
+func (m *ValidationMiddleware) validateParameters(c *gin.Context, op *openapi.Operation) []domain.FieldError {
+	var fields []domain.FieldError
+	for _, param := range op.Parameters {
+		var raw string
+		switch param.In {
+		case "path":
+			raw = c.Param(param.Name)
+		case "query":
+			values, ok := c.GetQueryArray(param.Name)
+			if !ok {
+				if param.Required {
+					fields = append(fields, domain.FieldError{In: param.In, Field: param.Name, Message: "is required"})
+				}
+				continue
+			}
+			raw = values[0]
+		default:
+			continue
+		}
+
+		for _, e := range m.doc.ValidateParameter(param, raw) {
+			fields = append(fields, domain.FieldError{In: param.In, Field: param.Name, Message: e.Message})
+		}
+	}
+	return fields
+}
+
+// validateBody validates a JSON request body, leaving it readable for the handler. It
+// returns an error when the body is not JSON at all.
+func (m *ValidationMiddleware) validateBody(c *gin.Context, op *openapi.Operation) ([]domain.FieldError, error) {
+	schema := jsonBodySchema(op)
+	if schema == nil || c.Request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return []domain.FieldError{{In: "body", Message: "is required"}}, nil
+		}
+		return nil, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+
+	var fields []domain.FieldError
+	for _, e := range m.doc.Validate(schema, value) {
+		fields = append(fields, domain.FieldError{In: "body", Field: e.Path, Message: e.Message})
+	}
+	return fields, nil
+}
+
+// jsonBodySchema returns the schema of a JSON request body, such as application/json or
+// application/merge-patch+json, or nil if the operation takes none
+func jsonBodySchema(op *openapi.Operation) *openapi.Schema {
+	if op.RequestBody == nil {
+		return nil
+	}
+	for contentType, media := range op.RequestBody.Content {
+		if strings.HasSuffix(contentType, "json") {
+			return media.Schema
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package infra
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+// adminTestRepos bundles the repositories an admin repository reads from
+type adminTestRepos struct {
+	transactions domain.TransactionRepository
+	rules        domain.RuleRepository
+	apiKeys      domain.APIKeyRepository
+	aiQuota      domain.AIQuotaRepository
+	aiUsage      domain.AIUsageRepository
+	admin        domain.AdminRepository
+}
+
+func testAdminRepository(t *testing.T, repos adminTestRepos) {
+	ctx := context.Background()
+	date := time.Date(2024, 8, 14, 15, 30, 0, 0, time.UTC)
+
+	transaction := func(userID string) domain.Transaction {
+		return domain.Transaction{Amount: 10, Currency: "MXN", Category: domain.CategoryFood, Type: domain.Expense, Date: date, UserID: userID}
+	}
+	saved := []domain.Transaction{transaction("alice"), transaction("alice"), transaction("bob"), transaction("")}
+	if err := testutil.SaveTransactions(ctx, repos.transactions, saved); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	rule := &domain.Rule{
+		UserID:     "bob",
+		Name:       "rule",
+		Enabled:    true,
+		Conditions: []domain.RuleCondition{{Field: domain.RuleFieldDescription, Operator: domain.OperatorContains, Value: "x"}},
+		Actions:    []domain.RuleAction{{Type: domain.ActionAddTag, Value: "x"}},
+	}
+	if err := repos.rules.CreateRule(ctx, rule); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	key := &domain.APIKey{UserID: "carol", Name: "key", Prefix: "etk_test", KeyHash: "hash", Scopes: []domain.Scope{domain.ScopeTransactionsRead}}
+	if err := repos.apiKeys.CreateAPIKey(ctx, key); err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+
+	for _, userID := range []string{"alice", "bob"} {
+		if err := repos.aiQuota.AddAITokens(ctx, userID, "2024-08", 500); err != nil {
+			t.Fatalf("AddAITokens: %v", err)
+		}
+		if err := repos.aiUsage.SaveAIUsage(ctx, &domain.AIUsage{UserID: userID, Model: "gpt-4o-mini", TotalTokens: 500, Success: true, CreatedAt: date}); err != nil {
+			t.Fatalf("SaveAIUsage: %v", err)
+		}
+	}
+
+	usage, err := repos.admin.GetUsersUsage(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("GetUsersUsage: %v", err)
+	}
+	if len(usage) != 3 {
+		t.Fatalf("expected usage for 3 users, got %+v", usage)
+	}
+	if usage[0].UserID != "alice" || usage[0].TransactionCount != 2 ||
+		usage[1].UserID != "bob" || usage[1].TransactionCount != 1 || usage[1].RuleCount != 1 || usage[1].LastActivityAt == nil ||
+		usage[2].UserID != "carol" || usage[2].APIKeyCount != 1 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+
+	page, err := repos.admin.GetUsersUsage(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("GetUsersUsage: %v", err)
+	}
+	if len(page) != 1 || page[0].UserID != "bob" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+
+	result, err := repos.admin.PurgeUserData(ctx, "bob")
+	if err != nil {
+		t.Fatalf("PurgeUserData: %v", err)
+	}
+	if result.Transactions != 1 || result.Rules != 1 || result.APIKeys != 0 {
+		t.Errorf("unexpected purge result: %+v", result)
+	}
+
+	for userID, count := range map[string]int{"alice": 2, "bob": 0, "": 1} {
+		remaining, err := repos.transactions.GetTransactions(ctx, userID, 10, 0)
+		if err != nil {
+			t.Fatalf("GetTransactions: %v", err)
+		}
+		if len(remaining) != count {
+			t.Errorf("expected only bob's transaction to be purged, %d of %q remain", len(remaining), userID)
+		}
+	}
+
+	if history, _ := repos.transactions.GetTransactionHistory(ctx, "bob", saved[2].ID); len(history) != 0 {
+		t.Errorf("expected bob's transaction history to be purged, got %+v", history)
+	}
+	if history, _ := repos.transactions.GetTransactionHistory(ctx, "alice", saved[0].ID); len(history) != 1 {
+		t.Errorf("expected alice's transaction history to remain, got %+v", history)
+	}
+
+	if tokens, _ := repos.aiQuota.GetAITokens(ctx, "bob", "2024-08"); tokens != 0 {
+		t.Errorf("expected bob's AI token counter to be purged, got %d", tokens)
+	}
+	if tokens, _ := repos.aiQuota.GetAITokens(ctx, "alice", "2024-08"); tokens != 500 {
+		t.Errorf("expected alice's AI token counter to remain, got %d", tokens)
+	}
+
+	calls := func(userID string) int {
+		aggregates, err := repos.aiUsage.GetAIUsageAggregates(ctx, domain.AIUsageFilter{UserID: userID, From: date.Add(-time.Hour), To: date.Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("GetAIUsageAggregates: %v", err)
+		}
+		total := 0
+		for _, aggregate := range aggregates {
+			total += aggregate.Calls
+		}
+		return total
+	}
+	if n := calls("bob"); n != 0 {
+		t.Errorf("expected bob's AI calls to be purged, got %d", n)
+	}
+	if n := calls("alice"); n != 1 {
+		t.Errorf("expected alice's AI call to remain, got %d", n)
+	}
+}
+
+func TestMemoryAdminRepository(t *testing.T) {
+	transactions := NewMemoryTransactionRepository()
+	rules := NewMemoryRuleRepository()
+	apiKeys := NewMemoryAPIKeyRepository()
+	aiQuota := NewMemoryAIQuotaRepository()
+	aiUsage := NewMemoryAIUsageRepository()
+
+	testAdminRepository(t, adminTestRepos{
+		transactions: transactions,
+		rules:        rules,
+		apiKeys:      apiKeys,
+		aiQuota:      aiQuota,
+		aiUsage:      aiUsage,
+		admin:        NewMemoryAdminRepository(transactions, rules, apiKeys, NewMemoryIdempotencyRepository(), aiQuota, aiUsage),
+	})
+}
+
+func TestSQLiteAdminRepository(t *testing.T) {
+	db := newSQLiteTestDB(t)
+
+	testAdminRepository(t, adminTestRepos{
+		transactions: NewSQLiteTransactionRepository(db),
+		rules:        NewSQLiteRuleRepository(db),
+		apiKeys:      NewSQLiteAPIKeyRepository(db),
+		aiQuota:      NewSQLiteAIQuotaRepository(db),
+		aiUsage:      NewSQLiteAIUsageRepository(db),
+		admin:        NewSQLiteAdminRepository(db),
+	})
+}
@@ -0,0 +1,65 @@
+package infra
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+func testAIUsageRepository(t *testing.T, repo domain.AIUsageRepository) {
+	ctx := context.Background()
+	day := time.Date(2024, 8, 14, 0, 0, 0, 0, time.UTC)
+
+	for _, usage := range []domain.AIUsage{
+		{UserID: "alice", Model: "gpt-4o", PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, Latency: 100 * time.Millisecond, Success: true, CreatedAt: day.Add(time.Hour)},
+		{UserID: "alice", Model: "gpt-4o", Latency: 300 * time.Millisecond, Success: false, Error: "timeout", CreatedAt: day.Add(2 * time.Hour)},
+		{UserID: "alice", Model: "gpt-3.5-turbo", PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30, Latency: 50 * time.Millisecond, Success: true, CreatedAt: day.Add(3 * time.Hour)},
+		{UserID: "bob", Model: "gpt-4o", PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2, Latency: 200 * time.Millisecond, Success: true, CreatedAt: day.Add(25 * time.Hour)},
+		{UserID: "bob", Model: "gpt-4o", PromptTokens: 7, CompletionTokens: 7, TotalTokens: 14, Success: true, CreatedAt: day.AddDate(0, 0, 5)},
+	} {
+		usage := usage
+		if err := repo.SaveAIUsage(ctx, &usage); err != nil {
+			t.Fatalf("SaveAIUsage: %v", err)
+		}
+		if usage.ID == 0 {
+			t.Fatalf("expected SaveAIUsage to populate the ID")
+		}
+	}
+
+	t.Run("aggregates by day and model across users", func(t *testing.T) {
+		aggregates, err := repo.GetAIUsageAggregates(ctx, domain.AIUsageFilter{From: day, To: day.AddDate(0, 0, 2)})
+		if err != nil {
+			t.Fatalf("GetAIUsageAggregates: %v", err)
+		}
+
+		expected := []domain.AIUsageAggregate{
+			{Day: "2024-08-14", Model: "gpt-3.5-turbo", Calls: 1, PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30, AvgLatencyMs: 50},
+			{Day: "2024-08-14", Model: "gpt-4o", Calls: 2, Failures: 1, PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, AvgLatencyMs: 200},
+			{Day: "2024-08-15", Model: "gpt-4o", Calls: 1, PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2, AvgLatencyMs: 200},
+		}
+		if !reflect.DeepEqual(aggregates, expected) {
+			t.Errorf("expected %+v, got %+v", expected, aggregates)
+		}
+	})
+
+	t.Run("filters by user", func(t *testing.T) {
+		aggregates, err := repo.GetAIUsageAggregates(ctx, domain.AIUsageFilter{UserID: "bob", From: day, To: day.AddDate(0, 1, 0)})
+		if err != nil {
+			t.Fatalf("GetAIUsageAggregates: %v", err)
+		}
+		if len(aggregates) != 2 || aggregates[0].Day != "2024-08-15" || aggregates[1].Day != "2024-08-19" {
+			t.Errorf("unexpected aggregates for bob: %+v", aggregates)
+		}
+	})
+}
+
+func TestMemoryAIUsageRepository(t *testing.T) {
+	testAIUsageRepository(t, NewMemoryAIUsageRepository())
+}
+
+func TestSQLiteAIUsageRepository(t *testing.T) {
+	testAIUsageRepository(t, NewSQLiteAIUsageRepository(newSQLiteTestDB(t)))
+}
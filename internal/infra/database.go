@@ -21,6 +21,9 @@ func NewDatabaseConnection(ctx context.Context, cfg *config.Config) (*pgxpool.Po
 	config.MaxConns = 10
 	config.MinConns = 2
 
+	// Record a span for every query
+	config.ConnConfig.Tracer = NewPgxTracer()
+
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -0,0 +1,136 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// PingHealthChecker implements the HealthChecker interface with a ping function
+type PingHealthChecker struct {
+	name string
+	ping func(ctx context.Context) error
+}
+
+// NewPostgreSQLHealthChecker checks that the pool can reach PostgreSQL
+func NewPostgreSQLHealthChecker(db *pgxpool.Pool) *PingHealthChecker {
+	return &PingHealthChecker{name: "database", ping: db.Ping}
+}
+
+// NewSQLiteHealthChecker checks that the SQLite database can be queried
+func NewSQLiteHealthChecker(db *sql.DB) *PingHealthChecker {
+	return &PingHealthChecker{name: "database", ping: db.PingContext}
+}
+
+// Name returns the name of the checked dependency
+func (c *PingHealthChecker) Name() string {
+	return c.name
+}
+
+// Check pings the dependency
+func (c *PingHealthChecker) Check(ctx context.Context) error {
+	return c.ping(ctx)
+}
+
+// MigrationHealthChecker implements the HealthChecker interface by verifying that the
+// schema is at the latest migration version this binary embeds
+type MigrationHealthChecker struct {
+	migrator Migrator
+}
+
+// NewMigrationHealthChecker creates a new schema version checker
+func NewMigrationHealthChecker(migrator Migrator) *MigrationHealthChecker {
+	return &MigrationHealthChecker{
+		migrator: migrator,
+	}
+}
+
+// Name returns the name of the checked dependency
+func (c *MigrationHealthChecker) Name() string {
+	return "migrations"
+}
+
+// Check fails when migrations are pending
+func (c *MigrationHealthChecker) Check(ctx context.Context) error {
+	status, err := c.migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if len(status.Pending) > 0 {
+		return fmt.Errorf("schema is at version %d, expected %d (%d migration(s) pending)", status.Current, status.Latest, len(status.Pending))
+	}
+	return nil
+}
+
+// OpenAIHealthChecker implements the HealthChecker interface by listing the models
+// available to the configured API key
+type OpenAIHealthChecker struct {
+	service *OpenAIService
+}
+
+// NewOpenAIHealthChecker creates a new OpenAI reachability checker
+func NewOpenAIHealthChecker(service *OpenAIService) *OpenAIHealthChecker {
+	return &OpenAIHealthChecker{
+		service: service,
+	}
+}
+
+// Name returns the name of the checked dependency
+func (c *OpenAIHealthChecker) Name() string {
+	return "openai"
+}
+
+// Check calls the OpenAI models endpoint
+func (c *OpenAIHealthChecker) Check(ctx context.Context) error {
+	if _, err := c.service.client.ListModels(ctx); err != nil {
+		return fmt.Errorf("failed to reach OpenAI API: %w", err)
+	}
+	return nil
+}
+
+// CachedHealthChecker decorates a HealthChecker, reusing its last result for a while so
+// that frequent probes do not hammer slow or rate limited dependencies
+type CachedHealthChecker struct {
+	next domain.HealthChecker
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// NewCachedHealthChecker caches the results of next for ttl
+func NewCachedHealthChecker(next domain.HealthChecker, ttl time.Duration) *CachedHealthChecker {
+	return &CachedHealthChecker{
+		next: next,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// Name returns the name of the checked dependency
+func (c *CachedHealthChecker) Name() string {
+	return c.next.Name()
+}
+
+// Check returns the cached result, checking again once it has expired. Concurrent
+// callers wait for a single check instead of each calling the dependency.
+func (c *CachedHealthChecker) Check(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkedAt.IsZero() && c.now().Sub(c.checkedAt) < c.ttl {
+		return c.err
+	}
+
+	err := c.next.Check(ctx)
+	c.checkedAt = c.now()
+	c.err = err
+	return err
+}
@@ -0,0 +1,105 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/migrations"
+)
+
+// countingChecker fails with err and counts how often it was called
+type countingChecker struct {
+	calls int
+	err   error
+}
+
+func (c *countingChecker) Name() string { return "counting" }
+
+func (c *countingChecker) Check(ctx context.Context) error {
+	c.calls++
+	return c.err
+}
+
+func TestCachedHealthChecker(t *testing.T) {
+	next := &countingChecker{err: errors.New("unreachable")}
+	checker := NewCachedHealthChecker(next, time.Minute)
+	now := time.Date(2024, 8, 14, 12, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time { return now }
+
+	for range 3 {
+		if err := checker.Check(context.Background()); err == nil || err.Error() != "unreachable" {
+			t.Fatalf("expected the cached failure, got %v", err)
+		}
+	}
+	if next.calls != 1 {
+		t.Errorf("expected one check within the TTL, got %d", next.calls)
+	}
+
+	next.err = nil
+	now = now.Add(time.Minute)
+	if err := checker.Check(context.Background()); err != nil {
+		t.Errorf("expected a fresh successful check after the TTL, got %v", err)
+	}
+	if next.calls != 2 {
+		t.Errorf("expected a second check after the TTL, got %d", next.calls)
+	}
+}
+
+func TestMigrationHealthChecker(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+
+	migrator, err := NewSQLiteMigrator(db, migrations.SQLiteFS)
+	if err != nil {
+		t.Fatalf("NewSQLiteMigrator: %v", err)
+	}
+	checker := NewMigrationHealthChecker(migrator)
+
+	if err := checker.Check(ctx); err != nil {
+		t.Fatalf("expected a migrated schema to be healthy, got %v", err)
+	}
+
+	if _, err := migrator.Down(ctx, 1); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if err := checker.Check(ctx); err == nil {
+		t.Errorf("expected a pending migration to fail the check")
+	}
+
+	if err := NewSQLiteHealthChecker(db).Check(ctx); err != nil {
+		t.Errorf("expected the database ping to succeed, got %v", err)
+	}
+}
+
+func TestMigrationStatusIsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewSQLiteConnection(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteConnection: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := NewSQLiteMigrator(db, migrations.SQLiteFS)
+	if err != nil {
+		t.Fatalf("NewSQLiteMigrator: %v", err)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Current != 0 || len(status.Pending) != status.Latest {
+		t.Errorf("expected every migration to be pending, got %+v", status)
+	}
+
+	var tables int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		t.Fatalf("count tables: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("expected Status not to create tables, found %d", tables)
+	}
+}
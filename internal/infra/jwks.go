@@ -0,0 +1,238 @@
+package infra
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+)
+
+// jwksMinRefreshInterval limits how often an unknown key ID can trigger a refetch,
+// so tokens with made-up kids cannot be used to hammer the JWKS endpoint
+const jwksMinRefreshInterval = 30 * time.Second
+
+// JWKSKeySet caches the public keys published at a JSON Web Key Set URL
+type JWKSKeySet struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]any
+	fetchedAt time.Time
+
+	// refreshMu serializes fetches so concurrent misses share one request
+	refreshMu sync.Mutex
+}
+
+// NewJWKSKeySet creates a key set for url that is considered stale after refreshInterval
+func NewJWKSKeySet(url string, refreshInterval time.Duration, client *http.Client) *JWKSKeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &JWKSKeySet{
+		url:             url,
+		client:          client,
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]any),
+	}
+}
+
+// Key returns the public key with the given key ID. The set is refetched when it is
+// stale or when the key is unknown, which picks up rotated keys without a restart.
+func (k *JWKSKeySet) Key(ctx context.Context, kid string) (any, error) {
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	fetchedAt := k.fetchedAt
+	k.mu.RUnlock()
+
+	stale := time.Since(fetchedAt) > k.refreshInterval
+	if ok && !stale {
+		return key, nil
+	}
+
+	if stale || time.Since(fetchedAt) > jwksMinRefreshInterval {
+		if err := k.refreshIfOlder(ctx, fetchedAt); err != nil && !ok {
+			return nil, err
+		}
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if key, ok := k.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no signing key found for kid %q", kid)
+}
+
+// Refresh fetches the key set and replaces the cached keys
+func (k *JWKSKeySet) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip keys we cannot use rather than rejecting the whole set
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+
+	return nil
+}
+
+// Start refreshes the key set every refresh interval until ctx is cancelled.
+// Failed refreshes keep the previously fetched keys.
+func (k *JWKSKeySet) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(k.refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := k.Refresh(ctx); err != nil {
+					logging.FromContext(ctx).Warn("failed to refresh JWKS; keeping previous keys", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// refreshIfOlder refreshes the key set unless another caller already did since seen
+func (k *JWKSKeySet) refreshIfOlder(ctx context.Context, seen time.Time) error {
+	k.refreshMu.Lock()
+	defer k.refreshMu.Unlock()
+
+	k.mu.RLock()
+	fetchedAt := k.fetchedAt
+	k.mu.RUnlock()
+	if fetchedAt.After(seen) {
+		return nil
+	}
+
+	return k.Refresh(ctx)
+}
+
+// jsonWebKey is a single public key as published in a JWKS document (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey converts the JWK into the key type expected by golang-jwt
+func (j jsonWebKey) publicKey() (any, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", j.Crv)
+		}
+		x, err := decodeJWKInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", j.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Ed25519 key: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+}
+
+// decodeJWKInt decodes a base64url-encoded big-endian integer
+func decodeJWKInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK integer: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,158 @@
+package infra
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// MemoryAdminRepository implements the AdminRepository interface over the in-memory repositories
+type MemoryAdminRepository struct {
+	transactions *MemoryTransactionRepository
+	rules        *MemoryRuleRepository
+	apiKeys      *MemoryAPIKeyRepository
+	idempotency  *MemoryIdempotencyRepository
+	aiQuota      *MemoryAIQuotaRepository
+	aiUsage      *MemoryAIUsageRepository
+}
+
+// NewMemoryAdminRepository creates an admin repository reading the given in-memory repositories
+func NewMemoryAdminRepository(transactions *MemoryTransactionRepository, rules *MemoryRuleRepository, apiKeys *MemoryAPIKeyRepository, idempotency *MemoryIdempotencyRepository, aiQuota *MemoryAIQuotaRepository, aiUsage *MemoryAIUsageRepository) *MemoryAdminRepository {
+	return &MemoryAdminRepository{
+		transactions: transactions,
+		rules:        rules,
+		apiKeys:      apiKeys,
+		idempotency:  idempotency,
+		aiQuota:      aiQuota,
+		aiUsage:      aiUsage,
+	}
+}
+
+// GetUsersUsage summarizes the stored data of every user that owns any, ordered by user ID
+func (r *MemoryAdminRepository) GetUsersUsage(ctx context.Context, limit, offset int) ([]domain.UserUsage, error) {
+	usage := make(map[string]*domain.UserUsage)
+	get := func(userID string) *domain.UserUsage {
+		if usage[userID] == nil {
+			usage[userID] = &domain.UserUsage{UserID: userID}
+		}
+		return usage[userID]
+	}
+	touch := func(u *domain.UserUsage, at time.Time) {
+		if u.LastActivityAt == nil || at.After(*u.LastActivityAt) {
+			u.LastActivityAt = &at
+		}
+	}
+
+	r.transactions.mu.RLock()
+	for _, transaction := range r.transactions.transactions {
+		if transaction.UserID != "" {
+			get(transaction.UserID).TransactionCount++
+		}
+	}
+	r.transactions.mu.RUnlock()
+
+	r.rules.mu.RLock()
+	for _, rule := range r.rules.rules {
+		u := get(rule.UserID)
+		u.RuleCount++
+		touch(u, rule.UpdatedAt)
+	}
+	r.rules.mu.RUnlock()
+
+	r.apiKeys.mu.RLock()
+	for _, key := range r.apiKeys.keys {
+		u := get(key.UserID)
+		u.APIKeyCount++
+		if key.LastUsedAt != nil {
+			touch(u, *key.LastUsedAt)
+		} else {
+			touch(u, key.CreatedAt)
+		}
+	}
+	r.apiKeys.mu.RUnlock()
+
+	result := make([]domain.UserUsage, 0, len(usage))
+	for _, u := range usage {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UserID < result[j].UserID
+	})
+
+	if offset >= len(result) {
+		return nil, nil
+	}
+	end := min(offset+limit, len(result))
+
+	return result[offset:end], nil
+}
+
+// PurgeUserData deletes the user's transactions and their history, rules, API keys, idempotency keys and AI usage
+func (r *MemoryAdminRepository) PurgeUserData(ctx context.Context, userID string) (*domain.PurgeResult, error) {
+	result := &domain.PurgeResult{UserID: userID}
+
+	r.transactions.mu.Lock()
+	for id, transaction := range r.transactions.transactions {
+		if transaction.UserID == userID {
+			delete(r.transactions.transactions, id)
+			result.Transactions++
+		}
+	}
+	events := r.transactions.events[:0:0]
+	for _, event := range r.transactions.events {
+		if event.UserID != userID {
+			events = append(events, event)
+		}
+	}
+	r.transactions.events = events
+	r.transactions.mu.Unlock()
+
+	r.rules.mu.Lock()
+	for id, rule := range r.rules.rules {
+		if rule.UserID == userID {
+			delete(r.rules.rules, id)
+			result.Rules++
+		}
+	}
+	r.rules.mu.Unlock()
+
+	r.apiKeys.mu.Lock()
+	for id, key := range r.apiKeys.keys {
+		if key.UserID == userID {
+			delete(r.apiKeys.keys, id)
+			result.APIKeys++
+		}
+	}
+	r.apiKeys.mu.Unlock()
+
+	r.idempotency.mu.Lock()
+	for id := range r.idempotency.records {
+		if strings.HasPrefix(id, idempotencyRecordID(userID, "")) {
+			delete(r.idempotency.records, id)
+		}
+	}
+	r.idempotency.mu.Unlock()
+
+	r.aiQuota.mu.Lock()
+	for id := range r.aiQuota.tokens {
+		if strings.HasPrefix(id, userID+"\x00") {
+			delete(r.aiQuota.tokens, id)
+		}
+	}
+	r.aiQuota.mu.Unlock()
+
+	r.aiUsage.mu.Lock()
+	usages := r.aiUsage.usages[:0:0]
+	for _, usage := range r.aiUsage.usages {
+		if usage.UserID != userID {
+			usages = append(usages, usage)
+		}
+	}
+	r.aiUsage.usages = usages
+	r.aiUsage.mu.Unlock()
+
+	return result, nil
+}
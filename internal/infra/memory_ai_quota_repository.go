@@ -0,0 +1,37 @@
+package infra
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryAIQuotaRepository implements the AIQuotaRepository interface in memory
+type MemoryAIQuotaRepository struct {
+	mu     sync.Mutex
+	tokens map[string]int
+}
+
+// NewMemoryAIQuotaRepository creates a new, empty in-memory AI quota repository
+func NewMemoryAIQuotaRepository() *MemoryAIQuotaRepository {
+	return &MemoryAIQuotaRepository{
+		tokens: make(map[string]int),
+	}
+}
+
+// AddAITokens adds tokens to the user's counter for period
+func (r *MemoryAIQuotaRepository) AddAITokens(ctx context.Context, userID, period string, tokens int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[userID+"\x00"+period] += tokens
+
+	return nil
+}
+
+// GetAITokens retrieves the tokens the user consumed in period
+func (r *MemoryAIQuotaRepository) GetAITokens(ctx context.Context, userID, period string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tokens[userID+"\x00"+period], nil
+}
@@ -0,0 +1,91 @@
+package infra
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// MemoryAIUsageRepository implements the AIUsageRepository interface in memory
+type MemoryAIUsageRepository struct {
+	mu     sync.Mutex
+	usages []domain.AIUsage
+	nextID int
+}
+
+// NewMemoryAIUsageRepository creates a new, empty in-memory AI usage repository
+func NewMemoryAIUsageRepository() *MemoryAIUsageRepository {
+	return &MemoryAIUsageRepository{
+		nextID: 1,
+	}
+}
+
+// SaveAIUsage saves a single AI call and populates its ID and creation time
+func (r *MemoryAIUsageRepository) SaveAIUsage(ctx context.Context, usage *domain.AIUsage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usage.ID = r.nextID
+	r.nextID++
+	if usage.CreatedAt.IsZero() {
+		usage.CreatedAt = time.Now()
+	}
+	usage.CreatedAt = usage.CreatedAt.UTC()
+
+	r.usages = append(r.usages, *usage)
+
+	return nil
+}
+
+// GetAIUsageAggregates summarizes the matching calls by UTC day and model
+func (r *MemoryAIUsageRepository) GetAIUsageAggregates(ctx context.Context, filter domain.AIUsageFilter) ([]domain.AIUsageAggregate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type key struct{ day, model string }
+	byKey := make(map[key]*domain.AIUsageAggregate)
+	latency := make(map[key]time.Duration)
+
+	for _, usage := range r.usages {
+		if usage.CreatedAt.Before(filter.From) || !usage.CreatedAt.Before(filter.To) {
+			continue
+		}
+		if filter.UserID != "" && usage.UserID != filter.UserID {
+			continue
+		}
+
+		k := key{usage.CreatedAt.Format("2006-01-02"), usage.Model}
+		a, ok := byKey[k]
+		if !ok {
+			a = &domain.AIUsageAggregate{Day: k.day, Model: k.model}
+			byKey[k] = a
+		}
+
+		a.Calls++
+		if !usage.Success {
+			a.Failures++
+		}
+		a.PromptTokens += usage.PromptTokens
+		a.CompletionTokens += usage.CompletionTokens
+		a.TotalTokens += usage.TotalTokens
+		latency[k] += usage.Latency.Truncate(time.Millisecond)
+	}
+
+	aggregates := make([]domain.AIUsageAggregate, 0, len(byKey))
+	for k, a := range byKey {
+		a.AvgLatencyMs = float64(latency[k].Milliseconds()) / float64(a.Calls)
+		aggregates = append(aggregates, *a)
+	}
+
+	sort.Slice(aggregates, func(i, j int) bool {
+		if aggregates[i].Day != aggregates[j].Day {
+			return aggregates[i].Day < aggregates[j].Day
+		}
+		return aggregates[i].Model < aggregates[j].Model
+	})
+
+	return aggregates, nil
+}
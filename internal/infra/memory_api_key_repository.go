@@ -0,0 +1,116 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// MemoryAPIKeyRepository implements the APIKeyRepository interface in memory
+type MemoryAPIKeyRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	keys   map[int]domain.APIKey
+}
+
+// NewMemoryAPIKeyRepository creates a new, empty in-memory API key repository
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		nextID: 1,
+		keys:   make(map[int]domain.APIKey),
+	}
+}
+
+// CreateAPIKey saves a new API key and populates its ID and creation time
+func (r *MemoryAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.keys {
+		if existing.Prefix == key.Prefix {
+			return fmt.Errorf("failed to insert API key: prefix %q already exists", key.Prefix)
+		}
+	}
+
+	key.ID = r.nextID
+	key.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.keys[key.ID] = cloneAPIKey(*key)
+
+	return nil
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its public prefix
+func (r *MemoryAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			key = cloneAPIKey(key)
+			return &key, nil
+		}
+	}
+
+	return nil, nil // API key not found
+}
+
+// GetAPIKeys retrieves all API keys for the given user, newest first
+func (r *MemoryAPIKeyRepository) GetAPIKeys(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []domain.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, cloneAPIKey(key))
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID > keys[j].ID
+	})
+
+	return keys, nil
+}
+
+// DeleteAPIKey deletes an API key by ID for the given user
+func (r *MemoryAPIKeyRepository) DeleteAPIKey(ctx context.Context, userID string, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok || key.UserID != userID {
+		return domain.NewNotFoundError("API key", id)
+	}
+
+	delete(r.keys, id)
+
+	return nil
+}
+
+// TouchAPIKey records when an API key was last used
+func (r *MemoryAPIKeyRepository) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil
+	}
+
+	key.LastUsedAt = &usedAt
+	r.keys[id] = key
+
+	return nil
+}
+
+// cloneAPIKey copies a key so callers cannot mutate stored scopes
+func cloneAPIKey(key domain.APIKey) domain.APIKey {
+	key.Scopes = append([]domain.Scope{}, key.Scopes...)
+	return key
+}
@@ -0,0 +1,88 @@
+package infra
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// MemoryIdempotencyRepository implements the IdempotencyRepository interface in memory
+type MemoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+// NewMemoryIdempotencyRepository creates a new, empty in-memory idempotency repository
+func NewMemoryIdempotencyRepository() *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{
+		records: make(map[string]domain.IdempotencyRecord),
+	}
+}
+
+// CreateIdempotencyRecord stores a pending record, reporting false if the key is already taken
+func (r *MemoryIdempotencyRepository) CreateIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyRecordID(record.UserID, record.Key)
+	if _, ok := r.records[id]; ok {
+		return false, nil // Key already exists
+	}
+
+	record.CreatedAt = time.Now().UTC()
+	r.records[id] = *record
+
+	return true, nil
+}
+
+// GetIdempotencyRecord retrieves the record stored for a user's idempotency key
+func (r *MemoryIdempotencyRepository) GetIdempotencyRecord(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[idempotencyRecordID(userID, key)]
+	if !ok {
+		return nil, nil // Key not found
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyRecord stores the response of the request that owns the key
+func (r *MemoryIdempotencyRepository) CompleteIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyRecordID(record.UserID, record.Key)
+	stored, ok := r.records[id]
+	if !ok {
+		return nil
+	}
+
+	stored.StatusCode = record.StatusCode
+	stored.ResponseBody = append([]byte(nil), record.ResponseBody...)
+	stored.ResponseHeaders = maps.Clone(record.ResponseHeaders)
+	stored.Completed = true
+	r.records[id] = stored
+	record.Completed = true
+
+	return nil
+}
+
+// DeleteIdempotencyRecord releases a user's idempotency key
+func (r *MemoryIdempotencyRepository) DeleteIdempotencyRecord(ctx context.Context, userID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, idempotencyRecordID(userID, key))
+
+	return nil
+}
+
+// idempotencyRecordID builds the map key identifying a user's idempotency key
+func idempotencyRecordID(userID, key string) string {
+	return userID + "\x00" + key
+}
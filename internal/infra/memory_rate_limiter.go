@@ -0,0 +1,102 @@
+package infra
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// rateLimiterSweepInterval is how often buckets that have refilled completely are dropped
+const rateLimiterSweepInterval = 10 * time.Minute
+
+// MemoryRateLimiter implements the RateLimiter interface with per-process token buckets.
+// Limits are enforced per instance; use PostgreSQLRateLimiter to share them between instances.
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// tokenBucket is the state of a single bucket
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     domain.RateLimit
+}
+
+// NewMemoryRateLimiter creates a new in-memory rate limiter
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Allow takes a token from the bucket identified by key
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string, limit domain.RateLimit) (*domain.RateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updatedAt: now}
+		l.buckets[key] = bucket
+	}
+	bucket.limit = limit
+	bucket.refill(now)
+
+	if bucket.tokens < 1 {
+		return &domain.RateLimitResult{
+			Allowed:    false,
+			RetryAfter: retryAfter(bucket.tokens, limit),
+		}, nil
+	}
+
+	bucket.tokens--
+	return &domain.RateLimitResult{
+		Allowed:   true,
+		Remaining: int(bucket.tokens),
+	}, nil
+}
+
+// refill adds the tokens earned since the last update, up to the burst size
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.RatePerSecond())
+		b.updatedAt = now
+	}
+}
+
+// sweep drops full buckets so memory does not grow with every user ever seen
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= float64(bucket.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// retryAfter returns how long a bucket holding tokens needs to regain a whole token
+func retryAfter(tokens float64, limit domain.RateLimit) time.Duration {
+	rate := limit.RatePerSecond()
+	if rate <= 0 {
+		return time.Minute
+	}
+	seconds := (1 - tokens) / rate
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,36 @@
+package infra
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+func TestMemoryRateLimiterRefills(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 8, 14, 15, 30, 0, 0, time.UTC)
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := domain.RateLimit{PerMinute: 6, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		if result, _ := limiter.Allow(ctx, "user", limit); !result.Allowed {
+			t.Fatalf("request %d: expected burst to be allowed", i)
+		}
+	}
+
+	result, _ := limiter.Allow(ctx, "user", limit)
+	if result.Allowed || result.RetryAfter != 10*time.Second {
+		t.Fatalf("expected denial with a 10s retry, got %+v", result)
+	}
+
+	now = now.Add(10 * time.Second)
+	if result, _ := limiter.Allow(ctx, "user", limit); !result.Allowed {
+		t.Fatal("expected a token after refilling")
+	}
+	if result, _ := limiter.Allow(ctx, "user", limit); result.Allowed {
+		t.Fatal("expected only one token to have refilled")
+	}
+}
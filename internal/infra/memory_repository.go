@@ -0,0 +1,426 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// MemoryTransactionRepository implements the TransactionRepository interface in memory.
+// It is intended for tests and ephemeral local runs; data is lost when the process exits.
+type MemoryTransactionRepository struct {
+	mu           sync.RWMutex
+	nextID       int
+	transactions map[int]domain.Transaction
+	events       []domain.TransactionEvent
+}
+
+// NewMemoryTransactionRepository creates a new, empty in-memory transaction repository
+func NewMemoryTransactionRepository() *MemoryTransactionRepository {
+	return &MemoryTransactionRepository{
+		nextID:       1,
+		transactions: make(map[int]domain.Transaction),
+	}
+}
+
+// GetTransactionByID retrieves the user's transaction by its ID
+func (r *MemoryTransactionRepository) GetTransactionByID(ctx context.Context, userID string, id int) (*domain.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transaction, ok := liveTransaction(r.transactions, id)
+	if !ok || transaction.UserID != userID {
+		return nil, nil // Transaction not found
+	}
+
+	transaction = cloneTransaction(transaction)
+	return &transaction, nil
+}
+
+// GetTransactions retrieves the user's transactions with pagination, newest first
+func (r *MemoryTransactionRepository) GetTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.Transaction, error) {
+	transactions := r.filter(func(t domain.Transaction) bool { return t.UserID == userID })
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.After(transactions[j].Date)
+	})
+
+	if offset >= len(transactions) {
+		return nil, nil
+	}
+	end := min(offset+limit, len(transactions))
+
+	return transactions[offset:end], nil
+}
+
+// UpdateTransaction updates an existing transaction of transaction.UserID
+func (r *MemoryTransactionRepository) UpdateTransaction(ctx context.Context, transaction *domain.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := liveTransaction(r.transactions, transaction.ID)
+	if !ok || existing.UserID != transaction.UserID {
+		return domain.NewNotFoundError("transaction", transaction.ID)
+	}
+	if err := checkUnmodified(existing, transaction.UpdatedAt); err != nil {
+		return err
+	}
+
+	// Ownership and creation time are recorded on save and never changed by updates
+	transaction.CreatedAt = existing.CreatedAt
+	transaction.UpdatedAt = nextVersion(existing.UpdatedAt)
+	updated := cloneTransaction(*transaction)
+	updated.UserID = existing.UserID
+	r.transactions[transaction.ID] = updated
+	r.events = appendMemoryTransactionEvent(ctx, r.events, domain.AuditActionUpdate, &existing, &updated)
+
+	return nil
+}
+
+// DeleteTransaction moves the user's transaction to the trash, if it has not changed since
+// ifUpdatedAt when that is set
+func (r *MemoryTransactionRepository) DeleteTransaction(ctx context.Context, userID string, id int, ifUpdatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := liveTransaction(r.transactions, id)
+	if !ok || existing.UserID != userID {
+		return domain.NewNotFoundError("transaction", id)
+	}
+	if err := checkUnmodified(existing, ifUpdatedAt); err != nil {
+		return err
+	}
+
+	trashed := trashedTransaction(existing)
+	r.transactions[id] = trashed
+	r.events = appendMemoryTransactionEvent(ctx, r.events, domain.AuditActionDelete, &existing, &trashed)
+
+	return nil
+}
+
+// FindTransactions retrieves the transactions matching filter, ordered by ID
+func (r *MemoryTransactionRepository) FindTransactions(ctx context.Context, filter domain.TransactionFilter) ([]domain.Transaction, error) {
+	return r.filter(filter.Matches), nil
+}
+
+// FindDuplicateCandidates retrieves the user's transactions that share their type, amount
+// and currency with another of the user's transactions dated within dateWindow, by date
+func (r *MemoryTransactionRepository) FindDuplicateCandidates(ctx context.Context, userID string, dateWindow time.Duration) ([]domain.Transaction, error) {
+	owned := r.filter(func(t domain.Transaction) bool {
+		return t.UserID == userID
+	})
+
+	var candidates []domain.Transaction
+	for i, transaction := range owned {
+		for j, other := range owned {
+			if i != j && transaction.Type == other.Type && domain.SameAmount(transaction, other) &&
+				transaction.Date.Sub(other.Date).Abs() <= dateWindow {
+				candidates = append(candidates, transaction)
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Date.Before(candidates[j].Date)
+	})
+
+	return candidates, nil
+}
+
+// ApplyTransactionWrites applies the writes in order, all or none
+func (r *MemoryTransactionRepository) ApplyTransactionWrites(ctx context.Context, writes []domain.TransactionWrite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Work on a copy so a failed write leaves the stored transactions untouched
+	transactions := make(map[int]domain.Transaction, len(r.transactions))
+	for id, transaction := range r.transactions {
+		transactions[id] = transaction
+	}
+	nextID := r.nextID
+	// Cap the events so appending copies them rather than writing into r.events
+	events := r.events[:len(r.events):len(r.events)]
+	now := memoryNow()
+
+	for i := range writes {
+		transaction := &writes[i].Transaction
+		existing, ok := liveTransaction(transactions, transaction.ID)
+		if writes[i].Action == domain.BulkActionRestore {
+			existing, ok = transactions[transaction.ID]
+			if !ok || existing.DeletedAt == nil || existing.UserID != transaction.UserID {
+				return &domain.BatchError{Index: i, Err: trashedTransactionNotFoundError(transaction.ID)}
+			}
+		}
+		if writes[i].Action != domain.BulkActionCreate {
+			if !ok || existing.UserID != transaction.UserID {
+				return &domain.BatchError{Index: i, Err: domain.NewNotFoundError("transaction", transaction.ID)}
+			}
+			if err := checkUnmodified(existing, transaction.UpdatedAt); err != nil {
+				return &domain.BatchError{Index: i, Err: err}
+			}
+		}
+
+		switch writes[i].Action {
+		case domain.BulkActionCreate:
+			transaction.ID = nextID
+			nextID++
+			transaction.CreatedAt = now
+			transaction.UpdatedAt = now
+			created := cloneTransaction(*transaction)
+			transactions[transaction.ID] = created
+			events = appendMemoryTransactionEvent(ctx, events, domain.AuditActionCreate, nil, &created)
+		case domain.BulkActionUpdate:
+			transaction.CreatedAt = existing.CreatedAt
+			transaction.UpdatedAt = nextVersion(existing.UpdatedAt)
+			updated := cloneTransaction(*transaction)
+			updated.UserID = existing.UserID
+			transactions[transaction.ID] = updated
+			events = appendMemoryTransactionEvent(ctx, events, domain.AuditActionUpdate, &existing, &updated)
+		case domain.BulkActionDelete:
+			trashed := trashedTransaction(existing)
+			transactions[transaction.ID] = trashed
+			events = appendMemoryTransactionEvent(ctx, events, domain.AuditActionDelete, &existing, &trashed)
+		case domain.BulkActionRestore:
+			restored := restoredTransaction(existing)
+			transactions[transaction.ID] = restored
+			events = appendMemoryTransactionEvent(ctx, events, domain.AuditActionRestore, &existing, &restored)
+			*transaction = cloneTransaction(restored)
+		default:
+			return &domain.BatchError{Index: i, Err: fmt.Errorf("unsupported write action %q", writes[i].Action)}
+		}
+	}
+
+	r.transactions = transactions
+	r.nextID = nextID
+	r.events = events
+
+	return nil
+}
+
+// GetDeletedTransactions retrieves the user's trashed transactions with pagination, most
+// recently deleted first
+func (r *MemoryTransactionRepository) GetDeletedTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.Transaction, error) {
+	r.mu.RLock()
+	var transactions []domain.Transaction
+	for _, transaction := range r.transactions {
+		if transaction.DeletedAt != nil && transaction.UserID == userID {
+			transactions = append(transactions, cloneTransaction(transaction))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if !transactions[i].DeletedAt.Equal(*transactions[j].DeletedAt) {
+			return transactions[i].DeletedAt.After(*transactions[j].DeletedAt)
+		}
+		return transactions[i].ID > transactions[j].ID
+	})
+
+	if offset >= len(transactions) {
+		return nil, nil
+	}
+	end := min(offset+limit, len(transactions))
+
+	return transactions[offset:end], nil
+}
+
+// RestoreTransaction moves the user's transaction out of the trash and returns it
+func (r *MemoryTransactionRepository) RestoreTransaction(ctx context.Context, userID string, id int) (*domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trashed, ok := r.transactions[id]
+	if !ok || trashed.DeletedAt == nil || trashed.UserID != userID {
+		return nil, trashedTransactionNotFoundError(id)
+	}
+
+	transaction := restoredTransaction(trashed)
+	r.transactions[id] = transaction
+	r.events = appendMemoryTransactionEvent(ctx, r.events, domain.AuditActionRestore, &trashed, &transaction)
+
+	transaction = cloneTransaction(transaction)
+	return &transaction, nil
+}
+
+// PurgeDeletedTransactions permanently deletes the transactions trashed before deletedBefore
+// and returns how many were deleted
+func (r *MemoryTransactionRepository) PurgeDeletedTransactions(ctx context.Context, deletedBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for id, transaction := range r.transactions {
+		if transaction.DeletedAt != nil && transaction.DeletedAt.Before(deletedBefore) {
+			delete(r.transactions, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// GetTransactionHistory returns the audit events of the user's transaction, oldest first
+func (r *MemoryTransactionRepository) GetTransactionHistory(ctx context.Context, userID string, id int) ([]domain.TransactionEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []domain.TransactionEvent
+	for _, event := range r.events {
+		if event.TransactionID == id && event.UserID == userID {
+			events = append(events, cloneTransactionEvent(event))
+		}
+	}
+
+	// Transactions saved before the audit log was introduced have no events
+	if transaction, ok := r.transactions[id]; len(events) == 0 && (!ok || transaction.UserID != userID) {
+		return nil, domain.NewNotFoundError("transaction", id)
+	}
+
+	return events, nil
+}
+
+// GetOperation returns the audit events of an operation, or nil if it made no changes
+func (r *MemoryTransactionRepository) GetOperation(ctx context.Context, id string) (*domain.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []domain.TransactionEvent
+	for _, event := range r.events {
+		if event.OperationID == id || event.RevertsOperationID == id {
+			events = append(events, cloneTransactionEvent(event))
+		}
+	}
+
+	return newOperation(id, events), nil
+}
+
+// filter returns copies of the live transactions accepted by keep, ordered by ID
+func (r *MemoryTransactionRepository) filter(keep func(domain.Transaction) bool) []domain.Transaction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var transactions []domain.Transaction
+	for _, transaction := range r.transactions {
+		if transaction.DeletedAt == nil && keep(transaction) {
+			transactions = append(transactions, cloneTransaction(transaction))
+		}
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].ID < transactions[j].ID
+	})
+
+	return transactions
+}
+
+// checkUnmodified fails when ifUpdatedAt is set and the stored transaction changed since
+func checkUnmodified(stored domain.Transaction, ifUpdatedAt time.Time) error {
+	if !ifUpdatedAt.IsZero() && !stored.UpdatedAt.Equal(ifUpdatedAt) {
+		return staleTransactionError(stored.ID)
+	}
+	return nil
+}
+
+// liveTransaction returns the transaction with id unless it is missing or trashed
+func liveTransaction(transactions map[int]domain.Transaction, id int) (domain.Transaction, bool) {
+	transaction, ok := transactions[id]
+	if !ok || transaction.DeletedAt != nil {
+		return domain.Transaction{}, false
+	}
+	return transaction, true
+}
+
+// trashedTransaction returns transaction as moved to the trash now
+func trashedTransaction(transaction domain.Transaction) domain.Transaction {
+	now := nextVersion(transaction.UpdatedAt)
+	transaction.DeletedAt = &now
+	transaction.UpdatedAt = now
+	return transaction
+}
+
+// restoredTransaction returns transaction as moved out of the trash now
+func restoredTransaction(transaction domain.Transaction) domain.Transaction {
+	transaction.DeletedAt = nil
+	transaction.UpdatedAt = nextVersion(transaction.UpdatedAt)
+	return transaction
+}
+
+// staleTransactionError reports a conditional write to a transaction that changed since
+func staleTransactionError(id int) error {
+	return domain.NewPreconditionFailedError(fmt.Sprintf("transaction with id %d was modified since it was last read", id))
+}
+
+// trashedTransactionNotFoundError reports a restore of a transaction that is not in the trash
+func trashedTransactionNotFoundError(id int) error {
+	return domain.NewNotFoundError("trashed transaction", id)
+}
+
+// newOperation builds the operation with id from the events made by it or undoing it,
+// returning nil when it made no changes
+func newOperation(id string, events []domain.TransactionEvent) *domain.Operation {
+	operation := &domain.Operation{ID: id}
+	for _, event := range events {
+		if event.OperationID == id {
+			operation.Events = append(operation.Events, event)
+		} else if operation.UndoneBy == "" {
+			operation.UndoneBy = event.OperationID
+		}
+	}
+
+	if len(operation.Events) == 0 {
+		return nil
+	}
+	return operation
+}
+
+// memoryNow returns the current time at the microsecond precision of the SQL stores
+func memoryNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// nextVersion returns the UpdatedAt of a write to a transaction last updated at previous,
+// kept distinct when writes land within the same microsecond
+func nextVersion(previous time.Time) time.Time {
+	now := memoryNow()
+	if !now.After(previous) {
+		return previous.Add(time.Microsecond)
+	}
+	return now
+}
+
+// appendMemoryTransactionEvent appends a change of the transaction to events, copying the
+// transaction as it was before and after
+func appendMemoryTransactionEvent(ctx context.Context, events []domain.TransactionEvent, action domain.AuditAction, before, after *domain.Transaction) []domain.TransactionEvent {
+	event := cloneTransactionEvent(domain.NewTransactionEvent(ctx, action, before, after))
+	event.ID = 1
+	if len(events) > 0 {
+		event.ID = events[len(events)-1].ID + 1
+	}
+	event.UserID = after.UserID
+	event.CreatedAt = memoryNow()
+	return append(events, event)
+}
+
+// cloneTransactionEvent copies an event so callers cannot mutate the recorded transactions
+func cloneTransactionEvent(event domain.TransactionEvent) domain.TransactionEvent {
+	if event.Before != nil {
+		before := cloneTransaction(*event.Before)
+		event.Before = &before
+	}
+	if event.After != nil {
+		after := cloneTransaction(*event.After)
+		event.After = &after
+	}
+	return event
+}
+
+// cloneTransaction copies a transaction so callers cannot mutate stored tags
+func cloneTransaction(transaction domain.Transaction) domain.Transaction {
+	transaction.Tags = append([]string{}, transaction.Tags...)
+	return transaction
+}
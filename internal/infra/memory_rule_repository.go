@@ -0,0 +1,107 @@
+package infra
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// MemoryRuleRepository implements the RuleRepository interface in memory
+type MemoryRuleRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	rules  map[int]domain.Rule
+}
+
+// NewMemoryRuleRepository creates a new, empty in-memory rule repository
+func NewMemoryRuleRepository() *MemoryRuleRepository {
+	return &MemoryRuleRepository{
+		nextID: 1,
+		rules:  make(map[int]domain.Rule),
+	}
+}
+
+// CreateRule saves a new rule and populates its ID and timestamps
+func (r *MemoryRuleRepository) CreateRule(ctx context.Context, rule *domain.Rule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	rule.ID = r.nextID
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	r.nextID++
+	r.rules[rule.ID] = *rule
+
+	return nil
+}
+
+// GetRuleByID retrieves a rule by its ID for the given user
+func (r *MemoryRuleRepository) GetRuleByID(ctx context.Context, userID string, id int) (*domain.Rule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rule, ok := r.rules[id]
+	if !ok || rule.UserID != userID {
+		return nil, nil // Rule not found
+	}
+
+	return &rule, nil
+}
+
+// GetRules retrieves all rules for the given user ordered by position
+func (r *MemoryRuleRepository) GetRules(ctx context.Context, userID string) ([]domain.Rule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var rules []domain.Rule
+	for _, rule := range r.rules {
+		if rule.UserID == userID {
+			rules = append(rules, rule)
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Position != rules[j].Position {
+			return rules[i].Position < rules[j].Position
+		}
+		return rules[i].ID < rules[j].ID
+	})
+
+	return rules, nil
+}
+
+// UpdateRule updates an existing rule owned by rule.UserID
+func (r *MemoryRuleRepository) UpdateRule(ctx context.Context, rule *domain.Rule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.rules[rule.ID]
+	if !ok || existing.UserID != rule.UserID {
+		return domain.NewNotFoundError("rule", rule.ID)
+	}
+
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now().UTC()
+	r.rules[rule.ID] = *rule
+
+	return nil
+}
+
+// DeleteRule deletes a rule by ID for the given user
+func (r *MemoryRuleRepository) DeleteRule(ctx context.Context, userID string, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rule, ok := r.rules[id]
+	if !ok || rule.UserID != userID {
+		return domain.NewNotFoundError("rule", id)
+	}
+
+	delete(r.rules, id)
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// MigrationStatus describes how far the database schema is from the embedded migrations
+type MigrationStatus struct {
+	Current int         `json:"current"`
+	Latest  int         `json:"latest"`
+	Pending []Migration `json:"-"`
+}
+
+// Migrator applies and reverts versioned schema migrations
+type Migrator interface {
+	// Up applies every pending migration and returns how many were applied
+	Up(ctx context.Context) (int, error)
+	// Down reverts the latest applied migrations, at most steps of them, and returns how many were reverted
+	Down(ctx context.Context, steps int) (int, error)
+	// Status reports the current and latest schema versions. It must not change the database,
+	// since readiness probes call it.
+	Status(ctx context.Context) (*MigrationStatus, error)
+}
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// LoadMigrations reads NNN_name.up.sql / NNN_name.down.sql pairs from fsys, sorted by version
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.UpSQL = string(content)
+		} else {
+			migration.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// migrationStatus compares the applied versions with the available migrations
+func migrationStatus(migrations []Migration, applied map[int]bool) *MigrationStatus {
+	status := &MigrationStatus{}
+
+	for _, migration := range migrations {
+		status.Latest = migration.Version
+		if applied[migration.Version] {
+			status.Current = migration.Version
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+
+	return status
+}
@@ -4,22 +4,38 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
 	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // OpenAIService implements the AIService interface
 type OpenAIService struct {
-	client *openai.Client
+	client   *openai.Client
+	recorder domain.AIUsageRecorder
 }
 
-// NewOpenAIService creates a new OpenAI service
-func NewOpenAIService(apiKey string) *OpenAIService {
-	client := openai.NewClient(apiKey)
+// NewOpenAIService creates a new OpenAI service. Every completion call, including failed
+// ones, is reported to recorder, which may be nil.
+func NewOpenAIService(apiKey string, recorder domain.AIUsageRecorder) *OpenAIService {
+	clientConfig := openai.DefaultConfig(apiKey)
+	// Trace every API call as a client span propagating the request's trace context
+	clientConfig.HTTPClient = &http.Client{
+		Transport: otelhttp.NewTransport(http.DefaultTransport,
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return "OpenAI " + r.Method + " " + r.URL.Path
+			}),
+		),
+	}
+
+	client := openai.NewClientWithConfig(clientConfig)
 	return &OpenAIService{
-		client: client,
+		client:   client,
+		recorder: recorder,
 	}
 }
 
@@ -70,11 +86,26 @@ Parse this text:`
 		Temperature: 0.1,
 	}
 
+	start := time.Now()
 	resp, err := s.client.CreateChatCompletion(ctx, req)
+	latency := time.Since(start)
+	if err != nil {
+		err = fmt.Errorf("failed to call OpenAI API: %w", err)
+		s.recordUsage(ctx, req.Model, resp, latency, err)
+		return nil, domain.NewUpstreamAIError(err)
+	}
+
+	transactions, err := parseCompletion(resp)
+	s.recordUsage(ctx, req.Model, resp, latency, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call OpenAI API: %w", err)
+		return nil, domain.NewUpstreamAIError(err)
 	}
 
+	return transactions, nil
+}
+
+// parseCompletion converts the JSON content of a completion into transactions
+func parseCompletion(resp openai.ChatCompletionResponse) ([]domain.Transaction, error) {
 	if len(resp.Choices) == 0 {
 		return nil, fmt.Errorf("no response from OpenAI API")
 	}
@@ -136,3 +167,48 @@ Parse this text:`
 
 	return transactions, nil
 }
+
+// recordUsage logs a completion call, successful or not, and reports it to the usage recorder.
+// model is the requested model, used when the call failed before the API reported one.
+func (s *OpenAIService) recordUsage(ctx context.Context, model string, resp openai.ChatCompletionResponse, latency time.Duration, callErr error) {
+	if resp.Model != "" {
+		model = resp.Model
+	}
+
+	logger := logging.FromContext(ctx).With(
+		"model", model,
+		"latency_ms", latency.Milliseconds(),
+		"prompt_tokens", resp.Usage.PromptTokens,
+		"completion_tokens", resp.Usage.CompletionTokens,
+	)
+	if callErr != nil {
+		logger.Error("OpenAI call failed", "error", callErr)
+	} else {
+		logger.Info("OpenAI call completed")
+	}
+
+	if s.recorder == nil {
+		return
+	}
+
+	userID, _ := domain.UserIDFromContext(ctx)
+	usage := domain.AIUsage{
+		UserID:           userID,
+		Model:            model,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+		Latency:          latency,
+		Success:          callErr == nil,
+		CreatedAt:        time.Now(),
+	}
+	if callErr != nil {
+		usage.Error = callErr.Error()
+	}
+
+	// Usage accounting is best effort; the tokens are spent either way, so they are recorded
+	// even when the client has gone and cancelled the request
+	if err := s.recorder.RecordAIUsage(context.WithoutCancel(ctx), usage); err != nil {
+		logger.Warn("failed to record AI usage", "error", err)
+	}
+}
@@ -0,0 +1,54 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/services"
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestOpenAIServiceRecordUsage(t *testing.T) {
+	// The client has gone by the time the completion returns
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), domain.UserIDKey, "user-1"))
+	cancel()
+
+	resp := openai.ChatCompletionResponse{
+		Model: "gpt-4o",
+		Usage: openai.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}
+
+	t.Run("records the usage of a cancelled request", func(t *testing.T) {
+		repo := NewSQLiteAIUsageRepository(newSQLiteTestDB(t))
+		service := &OpenAIService{recorder: services.NewAIUsageService(repo, nil, nil)}
+
+		service.recordUsage(ctx, "gpt-4o", resp, time.Second, errors.New("context canceled"))
+
+		day := time.Now().UTC().Truncate(24 * time.Hour)
+		aggregates, err := repo.GetAIUsageAggregates(context.Background(), domain.AIUsageFilter{UserID: "user-1", From: day, To: day.AddDate(0, 0, 1)})
+		if err != nil {
+			t.Fatalf("GetAIUsageAggregates: %v", err)
+		}
+		if len(aggregates) != 1 || aggregates[0].Calls != 1 || aggregates[0].Failures != 1 || aggregates[0].TotalTokens != 15 {
+			t.Errorf("expected the failed call to be recorded, got %+v", aggregates)
+		}
+	})
+
+	t.Run("charges the monthly quota of a cancelled request", func(t *testing.T) {
+		quota := services.NewAIQuotaService(NewSQLiteAIQuotaRepository(newSQLiteTestDB(t)), 100)
+		service := &OpenAIService{recorder: quota}
+
+		service.recordUsage(ctx, "gpt-4o", resp, time.Second, nil)
+
+		status, err := quota.GetQuotaStatus(context.Background(), "user-1")
+		if err != nil {
+			t.Fatalf("GetQuotaStatus: %v", err)
+		}
+		if status.Used != 15 {
+			t.Errorf("expected the call's 15 tokens to be charged, got %d", status.Used)
+		}
+	})
+}
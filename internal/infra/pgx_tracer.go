@@ -0,0 +1,59 @@
+package infra
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PgxTracer implements pgx.QueryTracer, recording a client span for every query
+type PgxTracer struct{}
+
+// NewPgxTracer creates a new pgx query tracer using the global tracer provider
+func NewPgxTracer() *PgxTracer {
+	return &PgxTracer{}
+}
+
+// TraceQueryStart starts a span named after the SQL operation, e.g. "postgres SELECT"
+func (t *PgxTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := sqlOperation(data.SQL)
+
+	ctx, _ = otel.Tracer(TracerName).Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(data.SQL),
+			attribute.Int("db.query.args", len(data.Args)),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd ends the query span, recording its error if any
+func (t *PgxTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil && data.Err != pgx.ErrNoRows {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+}
+
+// sqlOperation returns the leading keyword of a statement, e.g. SELECT or INSERT
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,103 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// PostgreSQLAdminRepository implements the AdminRepository interface
+type PostgreSQLAdminRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgreSQLAdminRepository creates a new PostgreSQL admin repository
+func NewPostgreSQLAdminRepository(db *pgxpool.Pool) *PostgreSQLAdminRepository {
+	return &PostgreSQLAdminRepository{
+		db: db,
+	}
+}
+
+// GetUsersUsage summarizes the stored data of every user that owns any, ordered by user ID
+func (r *PostgreSQLAdminRepository) GetUsersUsage(ctx context.Context, limit, offset int) ([]domain.UserUsage, error) {
+	stmt := `SELECT user_id,
+			        SUM(transactions)::BIGINT,
+			        SUM(rules)::BIGINT,
+			        SUM(api_keys)::BIGINT,
+			        MAX(last_activity_at)
+			 FROM (
+			     SELECT user_id, COUNT(*) AS transactions, 0 AS rules, 0 AS api_keys, MAX(updated_at) AS last_activity_at
+			     FROM transactions WHERE user_id <> '' GROUP BY user_id
+			     UNION ALL
+			     SELECT user_id, 0, COUNT(*), 0, MAX(updated_at)
+			     FROM rules GROUP BY user_id
+			     UNION ALL
+			     SELECT user_id, 0, 0, COUNT(*), MAX(COALESCE(last_used_at, created_at))
+			     FROM api_keys GROUP BY user_id
+			 ) usage
+			 GROUP BY user_id
+			 ORDER BY user_id
+			 LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, stmt, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []domain.UserUsage
+	for rows.Next() {
+		var u domain.UserUsage
+		if err := rows.Scan(&u.UserID, &u.TransactionCount, &u.RuleCount, &u.APIKeyCount, &u.LastActivityAt); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return usage, nil
+}
+
+// PurgeUserData deletes the user's transactions and their history, rules, API keys, idempotency keys and AI usage in one transaction
+func (r *PostgreSQLAdminRepository) PurgeUserData(ctx context.Context, userID string) (*domain.PurgeResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result := &domain.PurgeResult{UserID: userID}
+	deletes := []struct {
+		stmt  string
+		count *int
+	}{
+		{`DELETE FROM transactions WHERE user_id = $1`, &result.Transactions},
+		{`DELETE FROM transaction_events WHERE user_id = $1`, nil},
+		{`DELETE FROM rules WHERE user_id = $1`, &result.Rules},
+		{`DELETE FROM api_keys WHERE user_id = $1`, &result.APIKeys},
+		{`DELETE FROM idempotency_keys WHERE user_id = $1`, nil},
+		{`DELETE FROM ai_token_usage WHERE user_id = $1`, nil},
+		{`DELETE FROM ai_usage WHERE user_id = $1`, nil},
+	}
+
+	for _, d := range deletes {
+		tag, err := tx.Exec(ctx, d.stmt, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge user data: %w", err)
+		}
+		if d.count != nil {
+			*d.count = int(tag.RowsAffected())
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgreSQLAIQuotaRepository implements the AIQuotaRepository interface
+type PostgreSQLAIQuotaRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgreSQLAIQuotaRepository creates a new PostgreSQL AI quota repository
+func NewPostgreSQLAIQuotaRepository(db *pgxpool.Pool) *PostgreSQLAIQuotaRepository {
+	return &PostgreSQLAIQuotaRepository{
+		db: db,
+	}
+}
+
+// AddAITokens adds tokens to the user's counter for period
+func (r *PostgreSQLAIQuotaRepository) AddAITokens(ctx context.Context, userID, period string, tokens int) error {
+	stmt := `INSERT INTO ai_token_usage (user_id, period, tokens)
+			 VALUES ($1, $2, $3)
+			 ON CONFLICT (user_id, period) DO UPDATE
+			 SET tokens = ai_token_usage.tokens + EXCLUDED.tokens, updated_at = CURRENT_TIMESTAMP`
+
+	if _, err := r.db.Exec(ctx, stmt, userID, period, tokens); err != nil {
+		return fmt.Errorf("failed to record AI tokens: %w", err)
+	}
+
+	return nil
+}
+
+// GetAITokens retrieves the tokens the user consumed in period
+func (r *PostgreSQLAIQuotaRepository) GetAITokens(ctx context.Context, userID, period string) (int, error) {
+	stmt := `SELECT tokens FROM ai_token_usage WHERE user_id = $1 AND period = $2`
+
+	var tokens int
+	if err := r.db.QueryRow(ctx, stmt, userID, period).Scan(&tokens); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get AI tokens: %w", err)
+	}
+
+	return tokens, nil
+}
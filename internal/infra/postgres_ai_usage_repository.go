@@ -0,0 +1,77 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// PostgreSQLAIUsageRepository implements the AIUsageRepository interface
+type PostgreSQLAIUsageRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgreSQLAIUsageRepository creates a new PostgreSQL AI usage repository
+func NewPostgreSQLAIUsageRepository(db *pgxpool.Pool) *PostgreSQLAIUsageRepository {
+	return &PostgreSQLAIUsageRepository{
+		db: db,
+	}
+}
+
+// SaveAIUsage saves a single AI call and populates its ID and creation time
+func (r *PostgreSQLAIUsageRepository) SaveAIUsage(ctx context.Context, usage *domain.AIUsage) error {
+	stmt := `INSERT INTO ai_usage (user_id, model, prompt_tokens, completion_tokens, total_tokens, latency_ms, success, error)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			 RETURNING id, created_at`
+
+	err := r.db.QueryRow(ctx, stmt,
+		usage.UserID,
+		usage.Model,
+		usage.PromptTokens,
+		usage.CompletionTokens,
+		usage.TotalTokens,
+		usage.Latency.Milliseconds(),
+		usage.Success,
+		usage.Error,
+	).Scan(&usage.ID, &usage.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert AI usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetAIUsageAggregates summarizes the matching calls by UTC day and model
+func (r *PostgreSQLAIUsageRepository) GetAIUsageAggregates(ctx context.Context, filter domain.AIUsageFilter) ([]domain.AIUsageAggregate, error) {
+	stmt := `SELECT TO_CHAR(created_at, 'YYYY-MM-DD') AS day, model,
+			        COUNT(*), COUNT(*) FILTER (WHERE NOT success),
+			        COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0), COALESCE(SUM(total_tokens), 0),
+			        COALESCE(AVG(latency_ms), 0)::DOUBLE PRECISION
+			 FROM ai_usage
+			 WHERE created_at >= $1 AND created_at < $2 AND ($3::TEXT = '' OR user_id = $3)
+			 GROUP BY day, model
+			 ORDER BY day, model`
+
+	rows, err := r.db.Query(ctx, stmt, filter.From.UTC(), filter.To.UTC(), filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query AI usage: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []domain.AIUsageAggregate
+	for rows.Next() {
+		var a domain.AIUsageAggregate
+		if err := rows.Scan(&a.Day, &a.Model, &a.Calls, &a.Failures, &a.PromptTokens, &a.CompletionTokens, &a.TotalTokens, &a.AvgLatencyMs); err != nil {
+			return nil, fmt.Errorf("failed to scan AI usage: %w", err)
+		}
+		aggregates = append(aggregates, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating AI usage: %w", err)
+	}
+
+	return aggregates, nil
+}
@@ -0,0 +1,157 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// PostgreSQLAPIKeyRepository implements the APIKeyRepository interface
+type PostgreSQLAPIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgreSQLAPIKeyRepository creates a new PostgreSQL API key repository
+func NewPostgreSQLAPIKeyRepository(db *pgxpool.Pool) *PostgreSQLAPIKeyRepository {
+	return &PostgreSQLAPIKeyRepository{
+		db: db,
+	}
+}
+
+// CreateAPIKey saves a new API key and populates its ID and creation time
+func (r *PostgreSQLAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	stmt := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 RETURNING id, created_at`
+
+	err := r.db.QueryRow(ctx, stmt,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		scopesToStrings(key.Scopes),
+		key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its public prefix
+func (r *PostgreSQLAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	stmt := `SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+			 FROM api_keys WHERE prefix = $1`
+
+	key, err := scanAPIKey(r.db.QueryRow(ctx, stmt, prefix))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // API key not found
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeys retrieves all API keys for the given user, newest first
+func (r *PostgreSQLAPIKeyRepository) GetAPIKeys(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	stmt := `SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+			 FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(ctx, stmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return keys, nil
+}
+
+// DeleteAPIKey deletes an API key by ID for the given user
+func (r *PostgreSQLAPIKeyRepository) DeleteAPIKey(ctx context.Context, userID string, id int) error {
+	stmt := `DELETE FROM api_keys WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(ctx, stmt, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.NewNotFoundError("API key", id)
+	}
+
+	return nil
+}
+
+// TouchAPIKey records when an API key was last used
+func (r *PostgreSQLAPIKeyRepository) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	stmt := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`
+
+	if _, err := r.db.Exec(ctx, stmt, id, usedAt); err != nil {
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+
+	return nil
+}
+
+// scanAPIKey scans a single API key row
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes []string
+
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = stringsToScopes(scopes)
+
+	return &key, nil
+}
+
+// scopesToStrings converts scopes for storage in a text array
+func scopesToStrings(scopes []domain.Scope) []string {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	return values
+}
+
+// stringsToScopes converts a stored text array back into scopes
+func stringsToScopes(values []string) []domain.Scope {
+	scopes := make([]domain.Scope, len(values))
+	for i, value := range values {
+		scopes[i] = domain.Scope(value)
+	}
+	return scopes
+}
@@ -0,0 +1,120 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// PostgreSQLIdempotencyRepository implements the IdempotencyRepository interface
+type PostgreSQLIdempotencyRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgreSQLIdempotencyRepository creates a new PostgreSQL idempotency repository
+func NewPostgreSQLIdempotencyRepository(db *pgxpool.Pool) *PostgreSQLIdempotencyRepository {
+	return &PostgreSQLIdempotencyRepository{
+		db: db,
+	}
+}
+
+// CreateIdempotencyRecord stores a pending record, reporting false if the key is already taken
+func (r *PostgreSQLIdempotencyRepository) CreateIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	stmt := `INSERT INTO idempotency_keys (user_id, key, method, path, request_hash)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (user_id, key) DO NOTHING
+			 RETURNING created_at`
+
+	err := r.db.QueryRow(ctx, stmt,
+		record.UserID,
+		record.Key,
+		record.Method,
+		record.Path,
+		record.RequestHash,
+	).Scan(&record.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil // Key already exists
+		}
+		return false, fmt.Errorf("failed to insert idempotency key: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetIdempotencyRecord retrieves the record stored for a user's idempotency key
+func (r *PostgreSQLIdempotencyRepository) GetIdempotencyRecord(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error) {
+	stmt := `SELECT user_id, key, method, path, request_hash, status_code, response_body, response_headers, completed, created_at
+			 FROM idempotency_keys WHERE user_id = $1 AND key = $2`
+
+	var record domain.IdempotencyRecord
+	var statusCode *int
+	err := r.db.QueryRow(ctx, stmt, userID, key).Scan(
+		&record.UserID,
+		&record.Key,
+		&record.Method,
+		&record.Path,
+		&record.RequestHash,
+		&statusCode,
+		&record.ResponseBody,
+		&record.ResponseHeaders,
+		&record.Completed,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Key not found
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	if statusCode != nil {
+		record.StatusCode = *statusCode
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyRecord stores the response of the request that owns the key
+func (r *PostgreSQLIdempotencyRepository) CompleteIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	stmt := `UPDATE idempotency_keys
+			 SET status_code = $3, response_body = $4, response_headers = $5, completed = TRUE
+			 WHERE user_id = $1 AND key = $2`
+
+	_, err := r.db.Exec(ctx, stmt,
+		record.UserID,
+		record.Key,
+		record.StatusCode,
+		record.ResponseBody,
+		nonNilHeaders(record.ResponseHeaders),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	record.Completed = true
+
+	return nil
+}
+
+// DeleteIdempotencyRecord releases a user's idempotency key
+func (r *PostgreSQLIdempotencyRepository) DeleteIdempotencyRecord(ctx context.Context, userID, key string) error {
+	stmt := `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2`
+
+	if _, err := r.db.Exec(ctx, stmt, userID, key); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// nonNilHeaders stores missing response headers as an empty JSON object rather than null
+func nonNilHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return map[string]string{}
+	}
+	return headers
+}
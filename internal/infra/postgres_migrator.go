@@ -0,0 +1,196 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockKey identifies the advisory lock held while migrating, so that
+// concurrently starting instances apply each migration exactly once
+const migrationLockKey int64 = 0x65787074726b // "exptrk"
+
+// PostgreSQLMigrator implements the Migrator interface using a schema_migrations table
+type PostgreSQLMigrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewPostgreSQLMigrator creates a new PostgreSQL migrator for the migrations in fsys
+func NewPostgreSQLMigrator(db *pgxpool.Pool, fsys fs.FS) (*PostgreSQLMigrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLMigrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies every pending migration, each in its own transaction
+func (m *PostgreSQLMigrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		versions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrationStatus(m.migrations, versions).Pending {
+			if err := m.apply(ctx, conn, migration, migration.UpSQL,
+				`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name); err != nil {
+				return err
+			}
+			applied++
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the latest applied migrations, at most steps of them
+func (m *PostgreSQLMigrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		versions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if !versions[migration.Version] {
+				continue
+			}
+			if migration.DownSQL == "" {
+				return fmt.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+			}
+
+			if err := m.apply(ctx, conn, migration, migration.DownSQL,
+				`DELETE FROM schema_migrations WHERE version = $1`, migration.Version); err != nil {
+				return err
+			}
+			reverted++
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status reports the current and latest schema versions without changing the database.
+// Every migration is pending while the schema_migrations table doesn't exist.
+func (m *PostgreSQLMigrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up schema_migrations table: %w", err)
+	}
+	if !exists {
+		return migrationStatus(m.migrations, nil), nil
+	}
+
+	versions, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrationStatus(m.migrations, versions), nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock
+func (m *PostgreSQLMigrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	if err := m.createMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// apply runs a migration script and records the change in schema_migrations within one transaction
+func (m *PostgreSQLMigrator) apply(ctx context.Context, conn *pgxpool.Conn, migration Migration, script, record string, args ...any) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return fmt.Errorf("failed to run migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if _, err := tx.Exec(ctx, record, args...); err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
+
+// appliedVersions returns the set of migration versions recorded in schema_migrations
+func (m *PostgreSQLMigrator) appliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int]bool, error) {
+	rows, err := conn.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		versions[version] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return versions, nil
+}
+
+// createMigrationsTable creates the schema_migrations table if it doesn't exist
+func (m *PostgreSQLMigrator) createMigrationsTable(ctx context.Context, conn *pgxpool.Conn) error {
+	stmt := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	if _, err := conn.Exec(ctx, stmt); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// PostgreSQLRateLimiter implements the RateLimiter interface with token buckets stored
+// in PostgreSQL, so every instance behind a load balancer shares the same limits
+type PostgreSQLRateLimiter struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgreSQLRateLimiter creates a new PostgreSQL-backed rate limiter
+func NewPostgreSQLRateLimiter(db *pgxpool.Pool) *PostgreSQLRateLimiter {
+	return &PostgreSQLRateLimiter{
+		db: db,
+	}
+}
+
+// Allow takes a token from the bucket identified by key. The refill and the
+// decrement happen in a single statement, so concurrent requests cannot overspend.
+func (l *PostgreSQLRateLimiter) Allow(ctx context.Context, key string, limit domain.RateLimit) (*domain.RateLimitResult, error) {
+	// $2 is the burst size and $3 the refill rate in tokens per second
+	take := `INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+			 VALUES ($1, $2::DOUBLE PRECISION - 1, NOW())
+			 ON CONFLICT (key) DO UPDATE
+			 SET tokens = LEAST($2::DOUBLE PRECISION,
+			                    b.tokens + EXTRACT(EPOCH FROM (NOW() - b.updated_at))::DOUBLE PRECISION * $3::DOUBLE PRECISION) - 1,
+			     updated_at = NOW()
+			 WHERE LEAST($2::DOUBLE PRECISION,
+			             b.tokens + EXTRACT(EPOCH FROM (NOW() - b.updated_at))::DOUBLE PRECISION * $3::DOUBLE PRECISION) >= 1
+			 RETURNING tokens`
+
+	var tokens float64
+	err := l.db.QueryRow(ctx, take, key, limit.Burst, limit.RatePerSecond()).Scan(&tokens)
+	if err == nil {
+		return &domain.RateLimitResult{
+			Allowed:   true,
+			Remaining: int(tokens),
+		}, nil
+	}
+	if err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	// The bucket is empty; report how long until it refills enough
+	peek := `SELECT LEAST($2::DOUBLE PRECISION,
+			                tokens + EXTRACT(EPOCH FROM (NOW() - updated_at))::DOUBLE PRECISION * $3::DOUBLE PRECISION)
+			 FROM rate_limit_buckets WHERE key = $1`
+
+	if err := l.db.QueryRow(ctx, peek, key, limit.Burst, limit.RatePerSecond()).Scan(&tokens); err != nil {
+		return nil, fmt.Errorf("failed to read rate limit bucket: %w", err)
+	}
+
+	return &domain.RateLimitResult{
+		Allowed:    false,
+		RetryAfter: retryAfter(tokens, limit),
+	}, nil
+}
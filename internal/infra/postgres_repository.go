@@ -2,9 +2,12 @@ package infra
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
 )
@@ -21,52 +24,13 @@ func NewPostgreSQLTransactionRepository(db *pgxpool.Pool) *PostgreSQLTransaction
 	}
 }
 
-// SaveTransactions saves multiple transactions to the database
-func (r *PostgreSQLTransactionRepository) SaveTransactions(ctx context.Context, transactions []domain.Transaction) error {
-	if len(transactions) == 0 {
-		return nil
-	}
-
-	// Start a transaction
-	tx, err := r.db.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback(ctx)
-
-	// Prepare the insert statement
-	stmt := `INSERT INTO transactions (amount, currency, category, type, date, description) 
-			 VALUES ($1, $2, $3, $4, $5, $6)`
-
-	for _, transaction := range transactions {
-		_, err := tx.Exec(ctx, stmt,
-			transaction.Amount,
-			transaction.Currency,
-			transaction.Category,
-			transaction.Type,
-			transaction.Date,
-			transaction.Description,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to insert transaction: %w", err)
-		}
-	}
-
-	// Commit the transaction
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
-}
-
-// GetTransactionByID retrieves a transaction by its ID
-func (r *PostgreSQLTransactionRepository) GetTransactionByID(ctx context.Context, id int) (*domain.Transaction, error) {
-	stmt := `SELECT id, amount, currency, category, type, date, description 
-			 FROM transactions WHERE id = $1`
+// GetTransactionByID retrieves the user's transaction by its ID
+func (r *PostgreSQLTransactionRepository) GetTransactionByID(ctx context.Context, userID string, id int) (*domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at
+			 FROM transactions WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 
 	var transaction domain.Transaction
-	err := r.db.QueryRow(ctx, stmt, id).Scan(
+	err := r.db.QueryRow(ctx, stmt, id, userID).Scan(
 		&transaction.ID,
 		&transaction.Amount,
 		&transaction.Currency,
@@ -74,6 +38,10 @@ func (r *PostgreSQLTransactionRepository) GetTransactionByID(ctx context.Context
 		&transaction.Type,
 		&transaction.Date,
 		&transaction.Description,
+		&transaction.Account,
+		&transaction.Tags,
+		&transaction.CreatedAt,
+		&transaction.UpdatedAt,
 	)
 
 	if err != nil {
@@ -86,17 +54,156 @@ func (r *PostgreSQLTransactionRepository) GetTransactionByID(ctx context.Context
 	return &transaction, nil
 }
 
-// GetTransactions retrieves transactions with pagination
-func (r *PostgreSQLTransactionRepository) GetTransactions(ctx context.Context, limit, offset int) ([]domain.Transaction, error) {
-	stmt := `SELECT id, amount, currency, category, type, date, description 
-			 FROM transactions ORDER BY date DESC LIMIT $1 OFFSET $2`
+// GetTransactions retrieves the user's transactions with pagination
+func (r *PostgreSQLTransactionRepository) GetTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at
+			 FROM transactions WHERE deleted_at IS NULL AND user_id = $3 ORDER BY date DESC, id LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.Query(ctx, stmt, limit, offset)
+	rows, err := r.db.Query(ctx, stmt, limit, offset, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query transactions: %w", err)
 	}
 	defer rows.Close()
 
+	return collectTransactions(rows)
+}
+
+// UpdateTransaction updates an existing transaction of transaction.UserID and sets its
+// CreatedAt and new UpdatedAt
+func (r *PostgreSQLTransactionRepository) UpdateTransaction(ctx context.Context, transaction *domain.Transaction) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return updatePostgreSQLTransaction(ctx, tx, transaction)
+	})
+}
+
+// DeleteTransaction moves the user's transaction to the trash, if it has not changed since
+// ifUpdatedAt when that is set
+func (r *PostgreSQLTransactionRepository) DeleteTransaction(ctx context.Context, userID string, id int, ifUpdatedAt time.Time) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return deletePostgreSQLTransaction(ctx, tx, userID, id, ifUpdatedAt)
+	})
+}
+
+// FindTransactions retrieves the transactions matching filter, ordered by ID
+func (r *PostgreSQLTransactionRepository) FindTransactions(ctx context.Context, filter domain.TransactionFilter) ([]domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at
+			 FROM transactions
+			 WHERE deleted_at IS NULL
+			   AND ($1 = '' OR category = $1)
+			   AND ($2 = '' OR type = $2)
+			   AND ($3 = '' OR account = $3)
+			   AND ($4 = '' OR position(lower($4) in lower(COALESCE(description, ''))) > 0)
+			   AND ($5::timestamp IS NULL OR date >= $5)
+			   AND ($6::timestamp IS NULL OR date <= $6)
+			   AND user_id = $7
+			 ORDER BY id`
+
+	rows, err := r.db.Query(ctx, stmt,
+		string(filter.Category),
+		string(filter.Type),
+		filter.Account,
+		filter.DescriptionContains,
+		filter.From,
+		filter.To,
+		filter.UserID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	return collectTransactions(rows)
+}
+
+// FindDuplicateCandidates retrieves the user's transactions that share their type, amount
+// and currency with another of the user's transactions dated within dateWindow, by date
+func (r *PostgreSQLTransactionRepository) FindDuplicateCandidates(ctx context.Context, userID string, dateWindow time.Duration) ([]domain.Transaction, error) {
+	stmt := `SELECT t.id, t.amount, t.currency, t.category, t.type, t.date, t.description, t.account, t.tags, t.created_at, t.updated_at
+			 FROM transactions t
+			 WHERE t.user_id = $1 AND t.deleted_at IS NULL
+			   AND EXISTS (
+			     SELECT 1 FROM transactions o
+			     WHERE o.user_id = t.user_id AND o.id <> t.id AND o.deleted_at IS NULL
+			       AND o.type = t.type
+			       AND lower(o.currency) = lower(t.currency)
+			       AND abs(o.amount - t.amount) < $3
+			       AND o.date BETWEEN t.date - $2::bigint * interval '1 microsecond' AND t.date + $2::bigint * interval '1 microsecond'
+			   )
+			 ORDER BY t.date, t.id`
+
+	rows, err := r.db.Query(ctx, stmt, userID, dateWindow.Microseconds(), domain.AmountTolerance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query duplicate candidates: %w", err)
+	}
+	defer rows.Close()
+
+	return collectTransactions(rows)
+}
+
+// ApplyTransactionWrites applies the writes in order within a single database
+// transaction, so either all or none of them are applied
+func (r *PostgreSQLTransactionRepository) ApplyTransactionWrites(ctx context.Context, writes []domain.TransactionWrite) error {
+	if len(writes) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for i := 0; i < len(writes); i++ {
+		transaction := &writes[i].Transaction
+
+		var err error
+		switch writes[i].Action {
+		case domain.BulkActionCreate:
+			// Consecutive creates are inserted together, keeping the writes in order
+			end := i + 1
+			for end < len(writes) && writes[end].Action == domain.BulkActionCreate {
+				end++
+			}
+			if err := insertPostgreSQLTransactions(ctx, tx, writes[i:end], i); err != nil {
+				return err
+			}
+			i = end - 1
+		case domain.BulkActionUpdate:
+			err = updatePostgreSQLTransaction(ctx, tx, transaction)
+		case domain.BulkActionDelete:
+			err = deletePostgreSQLTransaction(ctx, tx, transaction.UserID, transaction.ID, transaction.UpdatedAt)
+		case domain.BulkActionRestore:
+			var restored *domain.Transaction
+			if restored, err = restorePostgreSQLTransaction(ctx, tx, transaction.UserID, transaction.ID, transaction.UpdatedAt); err == nil {
+				*transaction = *restored
+			}
+		default:
+			err = fmt.Errorf("unsupported write action %q", writes[i].Action)
+		}
+		if err != nil {
+			return &domain.BatchError{Index: i, Err: err}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeletedTransactions retrieves the user's trashed transactions with pagination, most
+// recently deleted first
+func (r *PostgreSQLTransactionRepository) GetDeletedTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at, deleted_at
+			 FROM transactions WHERE deleted_at IS NOT NULL AND user_id = $3 ORDER BY deleted_at DESC, id DESC LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, stmt, limit, offset, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted transactions: %w", err)
+	}
+	defer rows.Close()
+
 	var transactions []domain.Transaction
 	for rows.Next() {
 		var transaction domain.Transaction
@@ -108,6 +215,11 @@ func (r *PostgreSQLTransactionRepository) GetTransactions(ctx context.Context, l
 			&transaction.Type,
 			&transaction.Date,
 			&transaction.Description,
+			&transaction.Account,
+			&transaction.Tags,
+			&transaction.CreatedAt,
+			&transaction.UpdatedAt,
+			&transaction.DeletedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction: %w", err)
@@ -122,44 +234,208 @@ func (r *PostgreSQLTransactionRepository) GetTransactions(ctx context.Context, l
 	return transactions, nil
 }
 
-// CreateTransactionsTable creates the transactions table if it doesn't exist
-func (r *PostgreSQLTransactionRepository) CreateTransactionsTable(ctx context.Context) error {
-	stmt := `
-	CREATE TABLE IF NOT EXISTS transactions (
-		id SERIAL PRIMARY KEY,
-		amount DECIMAL(12,2) NOT NULL,
-		currency VARCHAR(3) NOT NULL DEFAULT 'USD',
-		category VARCHAR(50) NOT NULL,
-		type VARCHAR(10) NOT NULL CHECK (type IN ('income', 'expense')),
-		date TIMESTAMP NOT NULL,
-		description TEXT,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-	
-	-- Create index on date for better query performance
-	CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date);
-	-- Create index on type for filtering
-	CREATE INDEX IF NOT EXISTS idx_transactions_type ON transactions(type);
-	-- Create index on category for filtering
-	CREATE INDEX IF NOT EXISTS idx_transactions_category ON transactions(category);
-	`
-
-	_, err := r.db.Exec(ctx, stmt)
-	if err != nil {
-		return fmt.Errorf("failed to create transactions table: %w", err)
+// RestoreTransaction moves the user's transaction out of the trash and returns it
+func (r *PostgreSQLTransactionRepository) RestoreTransaction(ctx context.Context, userID string, id int) (*domain.Transaction, error) {
+	var restored *domain.Transaction
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var err error
+		restored, err = restorePostgreSQLTransaction(ctx, tx, userID, id, time.Time{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// PurgeDeletedTransactions permanently deletes the transactions trashed before deletedBefore
+// and returns how many were deleted
+func (r *PostgreSQLTransactionRepository) PurgeDeletedTransactions(ctx context.Context, deletedBefore time.Time) (int, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM transactions WHERE deleted_at < $1`, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted transactions: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// GetTransactionHistory returns the audit events of the user's transaction, oldest first
+func (r *PostgreSQLTransactionRepository) GetTransactionHistory(ctx context.Context, userID string, id int) ([]domain.TransactionEvent, error) {
+	stmt := `SELECT id, transaction_id, action, user_id, actor_id, source, request_id, operation_id, reverts_operation_id, before_state, after_state, created_at
+			 FROM transaction_events WHERE transaction_id = $1 AND user_id = $2 ORDER BY id`
+
+	rows, err := r.db.Query(ctx, stmt, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := collectPostgreSQLTransactionEvents(rows)
+	if err != nil || len(events) > 0 {
+		return events, err
+	}
+
+	// Transactions saved before the audit log was introduced have no events
+	var owned bool
+	stmt = `SELECT EXISTS (SELECT 1 FROM transactions WHERE id = $1 AND user_id = $2)`
+	if err := r.db.QueryRow(ctx, stmt, id, userID).Scan(&owned); err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if !owned {
+		return nil, domain.NewNotFoundError("transaction", id)
+	}
+
+	return nil, nil
+}
+
+// GetOperation returns the audit events of an operation, or nil if it made no changes
+func (r *PostgreSQLTransactionRepository) GetOperation(ctx context.Context, id string) (*domain.Operation, error) {
+	stmt := `SELECT id, transaction_id, action, user_id, actor_id, source, request_id, operation_id, reverts_operation_id, before_state, after_state, created_at
+			 FROM transaction_events WHERE operation_id = $1 OR reverts_operation_id = $1 ORDER BY id`
+
+	rows, err := r.db.Query(ctx, stmt, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := collectPostgreSQLTransactionEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOperation(id, events), nil
+}
+
+// collectPostgreSQLTransactionEvents scans every remaining row into a transaction event
+func collectPostgreSQLTransactionEvents(rows pgx.Rows) ([]domain.TransactionEvent, error) {
+	var events []domain.TransactionEvent
+	for rows.Next() {
+		var event domain.TransactionEvent
+		var before, after []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.TransactionID,
+			&event.Action,
+			&event.UserID,
+			&event.ActorID,
+			&event.Source,
+			&event.RequestID,
+			&event.OperationID,
+			&event.RevertsOperationID,
+			&before,
+			&after,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction event: %w", err)
+		}
+		if event.Before, err = unmarshalTransactionSnapshot(before); err != nil {
+			return nil, err
+		}
+		if event.After, err = unmarshalTransactionSnapshot(after); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return events, nil
+}
+
+// pgQuerier is implemented by *pgxpool.Pool and pgx.Tx
+type pgQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// insertPostgreSQLTransactionStmt inserts a transaction and returns its generated columns
+const insertPostgreSQLTransactionStmt = `INSERT INTO transactions (amount, currency, category, type, date, description, account, tags, user_id)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			 RETURNING id, created_at, updated_at`
+
+// insertPostgreSQLTransactionArgs returns the arguments of insertPostgreSQLTransactionStmt
+func insertPostgreSQLTransactionArgs(transaction *domain.Transaction) []any {
+	return []any{
+		transaction.Amount,
+		transaction.Currency,
+		transaction.Category,
+		transaction.Type,
+		transaction.Date,
+		transaction.Description,
+		transaction.Account,
+		nonNilTags(transaction.Tags),
+		transaction.UserID,
+	}
+}
+
+// insertPostgreSQLTransactions inserts the transactions of create writes in one round
+// trip, records their creation in a second and sets their ID, CreatedAt and UpdatedAt.
+// A failed write is reported as a *domain.BatchError, indexed from first.
+func insertPostgreSQLTransactions(ctx context.Context, tx pgx.Tx, writes []domain.TransactionWrite, first int) error {
+	batch := &pgx.Batch{}
+	for i := range writes {
+		batch.Queue(insertPostgreSQLTransactionStmt, insertPostgreSQLTransactionArgs(&writes[i].Transaction)...)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for i := range writes {
+		transaction := &writes[i].Transaction
+		if err := results.QueryRow().Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt); err != nil {
+			results.Close()
+			return &domain.BatchError{Index: first + i, Err: fmt.Errorf("failed to insert transaction: %w", err)}
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to insert transactions: %w", err)
+	}
+
+	// The events are queued once the IDs they reference are known
+	events := &pgx.Batch{}
+	for i := range writes {
+		args, err := insertPostgreSQLTransactionEventArgs(domain.NewTransactionEvent(ctx, domain.AuditActionCreate, nil, &writes[i].Transaction))
+		if err != nil {
+			return &domain.BatchError{Index: first + i, Err: err}
+		}
+		events.Queue(insertPostgreSQLTransactionEventStmt, args...)
+	}
+
+	results = tx.SendBatch(ctx, events)
+	for i := range writes {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return &domain.BatchError{Index: first + i, Err: fmt.Errorf("failed to record transaction event: %w", err)}
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to record transaction events: %w", err)
 	}
 
 	return nil
 }
 
-// UpdateTransaction updates an existing transaction
-func (r *PostgreSQLTransactionRepository) UpdateTransaction(ctx context.Context, transaction *domain.Transaction) error {
+// updatePostgreSQLTransaction updates a transaction of transaction.UserID, if it has not
+// changed since its UpdatedAt when that is set, records the change and sets its CreatedAt
+// and new UpdatedAt. q must be a database transaction.
+func updatePostgreSQLTransaction(ctx context.Context, q pgQuerier, transaction *domain.Transaction) error {
+	before, err := lockPostgreSQLTransaction(ctx, q, transaction.UserID, transaction.ID, false)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return domain.NewNotFoundError("transaction", transaction.ID)
+	}
+
 	stmt := `UPDATE transactions 
-			 SET amount = $2, currency = $3, category = $4, type = $5, date = $6, description = $7, updated_at = CURRENT_TIMESTAMP
-			 WHERE id = $1`
+			 SET amount = $2, currency = $3, category = $4, type = $5, date = $6, description = $7, account = $8, tags = $9, updated_at = CURRENT_TIMESTAMP
+			 WHERE id = $1 AND user_id = $11 AND ($10::timestamp IS NULL OR updated_at = $10)
+			 RETURNING created_at, updated_at`
 
-	result, err := r.db.Exec(ctx, stmt,
+	err = q.QueryRow(ctx, stmt,
 		transaction.ID,
 		transaction.Amount,
 		transaction.Currency,
@@ -167,33 +443,227 @@ func (r *PostgreSQLTransactionRepository) UpdateTransaction(ctx context.Context,
 		transaction.Type,
 		transaction.Date,
 		transaction.Description,
-	)
+		transaction.Account,
+		nonNilTags(transaction.Tags),
+		optionalTime(transaction.UpdatedAt),
+		transaction.UserID,
+	).Scan(&transaction.CreatedAt, &transaction.UpdatedAt)
 
+	if err == pgx.ErrNoRows {
+		return staleTransactionError(transaction.ID)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update transaction: %w", err)
 	}
 
-	rowsAffected := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("transaction with id %d not found", transaction.ID)
+	after := *transaction
+	after.DeletedAt = nil
+	return appendPostgreSQLTransactionEvent(ctx, q, domain.NewTransactionEvent(ctx, domain.AuditActionUpdate, before, &after))
+}
+
+// deletePostgreSQLTransaction moves the user's transaction to the trash, if it has not
+// changed since ifUpdatedAt when that is set, and records the change. q must be a database
+// transaction.
+func deletePostgreSQLTransaction(ctx context.Context, q pgQuerier, userID string, id int, ifUpdatedAt time.Time) error {
+	before, err := lockPostgreSQLTransaction(ctx, q, userID, id, false)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return domain.NewNotFoundError("transaction", id)
 	}
 
-	return nil
+	stmt := `UPDATE transactions SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			 WHERE id = $1 AND user_id = $3 AND ($2::timestamp IS NULL OR updated_at = $2)
+			 RETURNING updated_at, deleted_at`
+
+	after := *before
+	err = q.QueryRow(ctx, stmt, id, optionalTime(ifUpdatedAt), userID).Scan(&after.UpdatedAt, &after.DeletedAt)
+	if err == pgx.ErrNoRows {
+		return staleTransactionError(id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+
+	return appendPostgreSQLTransactionEvent(ctx, q, domain.NewTransactionEvent(ctx, domain.AuditActionDelete, before, &after))
 }
 
-// DeleteTransaction deletes a transaction by ID
-func (r *PostgreSQLTransactionRepository) DeleteTransaction(ctx context.Context, id int) error {
-	stmt := `DELETE FROM transactions WHERE id = $1`
+// restorePostgreSQLTransaction moves the user's transaction out of the trash, if it has
+// not changed since ifUpdatedAt when that is set, records the change and returns it. q
+// must be a database transaction.
+func restorePostgreSQLTransaction(ctx context.Context, q pgQuerier, userID string, id int, ifUpdatedAt time.Time) (*domain.Transaction, error) {
+	before, err := lockPostgreSQLTransaction(ctx, q, userID, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, trashedTransactionNotFoundError(id)
+	}
+	if err := checkUnmodified(*before, ifUpdatedAt); err != nil {
+		return nil, err
+	}
 
-	result, err := r.db.Exec(ctx, stmt, id)
+	after := *before
+	after.DeletedAt = nil
+	err = q.QueryRow(ctx, `UPDATE transactions SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 RETURNING updated_at`, id, userID).
+		Scan(&after.UpdatedAt)
 	if err != nil {
-		return fmt.Errorf("failed to delete transaction: %w", err)
+		return nil, fmt.Errorf("failed to restore transaction: %w", err)
+	}
+
+	if err := appendPostgreSQLTransactionEvent(ctx, q, domain.NewTransactionEvent(ctx, domain.AuditActionRestore, before, &after)); err != nil {
+		return nil, err
 	}
+	return &after, nil
+}
+
+// lockPostgreSQLTransaction reads a live or, if trashed is set, trashed transaction of the
+// user and locks it until the end of the database transaction. It returns nil if there is
+// none.
+func lockPostgreSQLTransaction(ctx context.Context, q pgQuerier, userID string, id int, trashed bool) (*domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at, deleted_at
+			 FROM transactions WHERE id = $1 AND user_id = $3 AND (deleted_at IS NOT NULL) = $2
+			 FOR UPDATE`
 
-	rowsAffected := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("transaction with id %d not found", id)
+	var transaction domain.Transaction
+	err := q.QueryRow(ctx, stmt, id, trashed, userID).Scan(
+		&transaction.ID,
+		&transaction.Amount,
+		&transaction.Currency,
+		&transaction.Category,
+		&transaction.Type,
+		&transaction.Date,
+		&transaction.Description,
+		&transaction.Account,
+		&transaction.Tags,
+		&transaction.CreatedAt,
+		&transaction.UpdatedAt,
+		&transaction.DeletedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	return &transaction, nil
+}
+
+// insertPostgreSQLTransactionEventStmt appends an event to the audit log, owned by the
+// owner of its transaction
+const insertPostgreSQLTransactionEventStmt = `INSERT INTO transaction_events (transaction_id, user_id, action, actor_id, source, request_id, operation_id, reverts_operation_id, before_state, after_state)
+			 VALUES ($1, (SELECT user_id FROM transactions WHERE id = $1), $2, $3, $4, $5, $6, $7, $8, $9)`
+
+// insertPostgreSQLTransactionEventArgs returns the arguments of insertPostgreSQLTransactionEventStmt
+func insertPostgreSQLTransactionEventArgs(event domain.TransactionEvent) ([]any, error) {
+	before, err := marshalTransactionSnapshot(event.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := marshalTransactionSnapshot(event.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{
+		event.TransactionID,
+		event.Action,
+		event.ActorID,
+		event.Source,
+		event.RequestID,
+		event.OperationID,
+		event.RevertsOperationID,
+		before,
+		after,
+	}, nil
+}
+
+// appendPostgreSQLTransactionEvent appends an event to the audit log
+func appendPostgreSQLTransactionEvent(ctx context.Context, q pgQuerier, event domain.TransactionEvent) error {
+	args, err := insertPostgreSQLTransactionEventArgs(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := q.Exec(ctx, insertPostgreSQLTransactionEventStmt, args...); err != nil {
+		return fmt.Errorf("failed to record transaction event: %w", err)
 	}
 
 	return nil
 }
+
+// marshalTransactionSnapshot encodes a transaction as recorded in the audit log; nil
+// is stored as NULL
+func marshalTransactionSnapshot(transaction *domain.Transaction) ([]byte, error) {
+	if transaction == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(transaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// unmarshalTransactionSnapshot decodes a transaction recorded in the audit log
+func unmarshalTransactionSnapshot(data []byte) (*domain.Transaction, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var transaction domain.Transaction
+	if err := json.Unmarshal(data, &transaction); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction snapshot: %w", err)
+	}
+	return &transaction, nil
+}
+
+// optionalTime returns nil for the zero time, so it is stored or compared as NULL
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// nonNilTags returns an empty slice for nil tags so they are stored as '{}' instead of NULL
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// collectTransactions scans every remaining row into a transaction
+func collectTransactions(rows pgx.Rows) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	for rows.Next() {
+		var transaction domain.Transaction
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.Amount,
+			&transaction.Currency,
+			&transaction.Category,
+			&transaction.Type,
+			&transaction.Date,
+			&transaction.Description,
+			&transaction.Account,
+			&transaction.Tags,
+			&transaction.CreatedAt,
+			&transaction.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return transactions, nil
+}
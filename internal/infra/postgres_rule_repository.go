@@ -0,0 +1,184 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// PostgreSQLRuleRepository implements the RuleRepository interface
+type PostgreSQLRuleRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgreSQLRuleRepository creates a new PostgreSQL rule repository
+func NewPostgreSQLRuleRepository(db *pgxpool.Pool) *PostgreSQLRuleRepository {
+	return &PostgreSQLRuleRepository{
+		db: db,
+	}
+}
+
+// CreateRule saves a new rule and populates its ID and timestamps
+func (r *PostgreSQLRuleRepository) CreateRule(ctx context.Context, rule *domain.Rule) error {
+	conditions, actions, err := marshalRuleBody(rule)
+	if err != nil {
+		return err
+	}
+
+	stmt := `INSERT INTO rules (user_id, name, position, enabled, conditions, actions)
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 RETURNING id, created_at, updated_at`
+
+	err = r.db.QueryRow(ctx, stmt,
+		rule.UserID,
+		rule.Name,
+		rule.Position,
+		rule.Enabled,
+		conditions,
+		actions,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetRuleByID retrieves a rule by its ID for the given user
+func (r *PostgreSQLRuleRepository) GetRuleByID(ctx context.Context, userID string, id int) (*domain.Rule, error) {
+	stmt := `SELECT id, user_id, name, position, enabled, conditions, actions, created_at, updated_at
+			 FROM rules WHERE id = $1 AND user_id = $2`
+
+	rule, err := scanRule(r.db.QueryRow(ctx, stmt, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Rule not found
+		}
+		return nil, fmt.Errorf("failed to get rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetRules retrieves all rules for the given user ordered by position
+func (r *PostgreSQLRuleRepository) GetRules(ctx context.Context, userID string) ([]domain.Rule, error) {
+	stmt := `SELECT id, user_id, name, position, enabled, conditions, actions, created_at, updated_at
+			 FROM rules WHERE user_id = $1 ORDER BY position, id`
+
+	rows, err := r.db.Query(ctx, stmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []domain.Rule
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return rules, nil
+}
+
+// UpdateRule updates an existing rule owned by rule.UserID
+func (r *PostgreSQLRuleRepository) UpdateRule(ctx context.Context, rule *domain.Rule) error {
+	conditions, actions, err := marshalRuleBody(rule)
+	if err != nil {
+		return err
+	}
+
+	stmt := `UPDATE rules
+			 SET name = $3, position = $4, enabled = $5, conditions = $6, actions = $7, updated_at = CURRENT_TIMESTAMP
+			 WHERE id = $1 AND user_id = $2
+			 RETURNING created_at, updated_at`
+
+	err = r.db.QueryRow(ctx, stmt,
+		rule.ID,
+		rule.UserID,
+		rule.Name,
+		rule.Position,
+		rule.Enabled,
+		conditions,
+		actions,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NewNotFoundError("rule", rule.ID)
+		}
+		return fmt.Errorf("failed to update rule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRule deletes a rule by ID for the given user
+func (r *PostgreSQLRuleRepository) DeleteRule(ctx context.Context, userID string, id int) error {
+	stmt := `DELETE FROM rules WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(ctx, stmt, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.NewNotFoundError("rule", id)
+	}
+
+	return nil
+}
+
+// scanRule scans a single rule row, decoding its JSONB conditions and actions
+func scanRule(row pgx.Row) (*domain.Rule, error) {
+	var rule domain.Rule
+	var conditions, actions []byte
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.Name,
+		&rule.Position,
+		&rule.Enabled,
+		&conditions,
+		&actions,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(conditions, &rule.Conditions); err != nil {
+		return nil, fmt.Errorf("failed to decode rule conditions: %w", err)
+	}
+	if err := json.Unmarshal(actions, &rule.Actions); err != nil {
+		return nil, fmt.Errorf("failed to decode rule actions: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// marshalRuleBody encodes the rule conditions and actions for JSONB storage
+func marshalRuleBody(rule *domain.Rule) ([]byte, []byte, error) {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode rule conditions: %w", err)
+	}
+
+	actions, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode rule actions: %w", err)
+	}
+
+	return conditions, actions, nil
+}
@@ -0,0 +1,189 @@
+package infra
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsNamespace prefixes every metric the service exports
+const metricsNamespace = "expense_tracker"
+
+// PrometheusMetrics implements the RequestMetrics, TransactionMetrics and AIUsageRecorder
+// interfaces with Prometheus collectors on a dedicated registry
+type PrometheusMetrics struct {
+	registry *prometheus.Registry
+
+	requestDuration   *prometheus.HistogramVec
+	aiCalls           *prometheus.CounterVec
+	aiLatency         *prometheus.HistogramVec
+	aiTokens          *prometheus.CounterVec
+	transactionsSaved *prometheus.CounterVec
+}
+
+// NewPrometheusMetrics creates the service metrics along with the Go runtime and process collectors
+func NewPrometheusMetrics() *PrometheusMetrics {
+	m := &PrometheusMetrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		aiCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "openai_calls_total",
+			Help:      "OpenAI chat completion calls by model and outcome (success or error).",
+		}, []string{"model", "outcome"}),
+		aiLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "openai_call_duration_seconds",
+			Help:      "Duration of OpenAI chat completion calls by model.",
+			Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32},
+		}, []string{"model"}),
+		aiTokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "openai_tokens_total",
+			Help:      "OpenAI tokens consumed by model and kind (prompt or completion).",
+		}, []string{"model", "kind"}),
+		transactionsSaved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "transactions_saved_total",
+			Help:      "Transactions saved by source (parse, manual or api_key).",
+		}, []string{"source"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestDuration,
+		m.aiCalls,
+		m.aiLatency,
+		m.aiTokens,
+		m.transactionsSaved,
+	)
+
+	return m
+}
+
+// Register adds collectors, such as database pool statistics, to the exported metrics
+func (m *PrometheusMetrics) Register(collectors ...prometheus.Collector) error {
+	for _, collector := range collectors {
+		if err := m.registry.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *PrometheusMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest records a completed HTTP request
+func (m *PrometheusMetrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.requestDuration.WithLabelValues(route, method, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// TransactionsSaved counts transactions saved from source
+func (m *PrometheusMetrics) TransactionsSaved(source domain.TransactionSource, count int) {
+	m.transactionsSaved.WithLabelValues(string(source)).Add(float64(count))
+}
+
+// RecordAIUsage records the outcome, latency and tokens of an AI call
+func (m *PrometheusMetrics) RecordAIUsage(ctx context.Context, usage domain.AIUsage) error {
+	outcome := "success"
+	if !usage.Success {
+		outcome = "error"
+	}
+
+	m.aiCalls.WithLabelValues(usage.Model, outcome).Inc()
+	m.aiLatency.WithLabelValues(usage.Model).Observe(usage.Latency.Seconds())
+	m.aiTokens.WithLabelValues(usage.Model, "prompt").Add(float64(usage.PromptTokens))
+	m.aiTokens.WithLabelValues(usage.Model, "completion").Add(float64(usage.CompletionTokens))
+
+	return nil
+}
+
+// pgxPoolCollector exports the statistics of a pgx connection pool
+type pgxPoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+	emptyAcquire     *prometheus.Desc
+	canceledAcquire  *prometheus.Desc
+	newConns         *prometheus.Desc
+	maxLifetimeClose *prometheus.Desc
+	maxIdleClose     *prometheus.Desc
+}
+
+// NewPgxPoolCollector creates a Prometheus collector reading pool.Stat() on every scrape
+func NewPgxPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &pgxPoolCollector{
+		pool:             pool,
+		acquiredConns:    desc("acquired_connections", "Connections currently in use."),
+		idleConns:        desc("idle_connections", "Idle connections in the pool."),
+		totalConns:       desc("total_connections", "Open connections, including those being established."),
+		maxConns:         desc("max_connections", "Maximum size of the pool."),
+		acquireCount:     desc("acquires_total", "Successful connection acquisitions."),
+		acquireDuration:  desc("acquire_duration_seconds_total", "Total time spent waiting to acquire connections."),
+		emptyAcquire:     desc("empty_acquires_total", "Acquisitions that had to wait because the pool was empty."),
+		canceledAcquire:  desc("canceled_acquires_total", "Acquisitions canceled by their context."),
+		newConns:         desc("new_connections_total", "Connections opened."),
+		maxLifetimeClose: desc("max_lifetime_closes_total", "Connections closed for exceeding their maximum lifetime."),
+		maxIdleClose:     desc("max_idle_closes_total", "Connections closed for exceeding their maximum idle time."),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *pgxPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.acquiredConns, c.idleConns, c.totalConns, c.maxConns,
+		c.acquireCount, c.acquireDuration, c.emptyAcquire, c.canceledAcquire,
+		c.newConns, c.maxLifetimeClose, c.maxIdleClose,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *pgxPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(c.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(c.idleConns, float64(stat.IdleConns()))
+	gauge(c.totalConns, float64(stat.TotalConns()))
+	gauge(c.maxConns, float64(stat.MaxConns()))
+	counter(c.acquireCount, float64(stat.AcquireCount()))
+	counter(c.acquireDuration, stat.AcquireDuration().Seconds())
+	counter(c.emptyAcquire, float64(stat.EmptyAcquireCount()))
+	counter(c.canceledAcquire, float64(stat.CanceledAcquireCount()))
+	counter(c.newConns, float64(stat.NewConnsCount()))
+	counter(c.maxLifetimeClose, float64(stat.MaxLifetimeDestroyCount()))
+	counter(c.maxIdleClose, float64(stat.MaxIdleDestroyCount()))
+}
@@ -0,0 +1,107 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// SQLiteAdminRepository implements the AdminRepository interface
+type SQLiteAdminRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAdminRepository creates a new SQLite admin repository
+func NewSQLiteAdminRepository(db *sql.DB) *SQLiteAdminRepository {
+	return &SQLiteAdminRepository{
+		db: db,
+	}
+}
+
+// GetUsersUsage summarizes the stored data of every user that owns any, ordered by user ID
+func (r *SQLiteAdminRepository) GetUsersUsage(ctx context.Context, limit, offset int) ([]domain.UserUsage, error) {
+	stmt := `SELECT user_id, SUM(transactions), SUM(rules), SUM(api_keys), MAX(last_activity_at)
+			 FROM (
+			     SELECT user_id, COUNT(*) AS transactions, 0 AS rules, 0 AS api_keys, MAX(updated_at) AS last_activity_at
+			     FROM transactions WHERE user_id <> '' GROUP BY user_id
+			     UNION ALL
+			     SELECT user_id, 0, COUNT(*), 0, MAX(updated_at)
+			     FROM rules GROUP BY user_id
+			     UNION ALL
+			     SELECT user_id, 0, 0, COUNT(*), MAX(COALESCE(last_used_at, created_at))
+			     FROM api_keys GROUP BY user_id
+			 )
+			 GROUP BY user_id
+			 ORDER BY user_id
+			 LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, stmt, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []domain.UserUsage
+	for rows.Next() {
+		var u domain.UserUsage
+		var lastActivityAt sql.NullString
+		if err := rows.Scan(&u.UserID, &u.TransactionCount, &u.RuleCount, &u.APIKeyCount, &lastActivityAt); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		if u.LastActivityAt, err = parseNullableSQLiteTime(lastActivityAt); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return usage, nil
+}
+
+// PurgeUserData deletes the user's transactions and their history, rules, API keys, idempotency keys and AI usage in one transaction
+func (r *SQLiteAdminRepository) PurgeUserData(ctx context.Context, userID string) (*domain.PurgeResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &domain.PurgeResult{UserID: userID}
+	deletes := []struct {
+		stmt  string
+		count *int
+	}{
+		{`DELETE FROM transactions WHERE user_id = ?`, &result.Transactions},
+		{`DELETE FROM transaction_events WHERE user_id = ?`, nil},
+		{`DELETE FROM rules WHERE user_id = ?`, &result.Rules},
+		{`DELETE FROM api_keys WHERE user_id = ?`, &result.APIKeys},
+		{`DELETE FROM idempotency_keys WHERE user_id = ?`, nil},
+		{`DELETE FROM ai_token_usage WHERE user_id = ?`, nil},
+		{`DELETE FROM ai_usage WHERE user_id = ?`, nil},
+	}
+
+	for _, d := range deletes {
+		res, err := tx.ExecContext(ctx, d.stmt, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge user data: %w", err)
+		}
+		if d.count != nil {
+			rowsAffected, err := res.RowsAffected()
+			if err != nil {
+				return nil, fmt.Errorf("failed to purge user data: %w", err)
+			}
+			*d.count = int(rowsAffected)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
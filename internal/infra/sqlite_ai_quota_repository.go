@@ -0,0 +1,49 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SQLiteAIQuotaRepository implements the AIQuotaRepository interface
+type SQLiteAIQuotaRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAIQuotaRepository creates a new SQLite AI quota repository
+func NewSQLiteAIQuotaRepository(db *sql.DB) *SQLiteAIQuotaRepository {
+	return &SQLiteAIQuotaRepository{
+		db: db,
+	}
+}
+
+// AddAITokens adds tokens to the user's counter for period
+func (r *SQLiteAIQuotaRepository) AddAITokens(ctx context.Context, userID, period string, tokens int) error {
+	stmt := `INSERT INTO ai_token_usage (user_id, period, tokens, updated_at)
+			 VALUES (?, ?, ?, ?)
+			 ON CONFLICT (user_id, period) DO UPDATE
+			 SET tokens = tokens + excluded.tokens, updated_at = excluded.updated_at`
+
+	if _, err := r.db.ExecContext(ctx, stmt, userID, period, tokens, formatSQLiteTime(time.Now())); err != nil {
+		return fmt.Errorf("failed to record AI tokens: %w", err)
+	}
+
+	return nil
+}
+
+// GetAITokens retrieves the tokens the user consumed in period
+func (r *SQLiteAIQuotaRepository) GetAITokens(ctx context.Context, userID, period string) (int, error) {
+	stmt := `SELECT tokens FROM ai_token_usage WHERE user_id = ? AND period = ?`
+
+	var tokens int
+	if err := r.db.QueryRowContext(ctx, stmt, userID, period).Scan(&tokens); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get AI tokens: %w", err)
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,98 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// SQLiteAIUsageRepository implements the AIUsageRepository interface
+type SQLiteAIUsageRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAIUsageRepository creates a new SQLite AI usage repository
+func NewSQLiteAIUsageRepository(db *sql.DB) *SQLiteAIUsageRepository {
+	return &SQLiteAIUsageRepository{
+		db: db,
+	}
+}
+
+// SaveAIUsage saves a single AI call and populates its ID and creation time
+func (r *SQLiteAIUsageRepository) SaveAIUsage(ctx context.Context, usage *domain.AIUsage) error {
+	createdAt := usage.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	createdAt = createdAt.UTC().Truncate(time.Microsecond)
+
+	stmt := `INSERT INTO ai_usage (user_id, model, prompt_tokens, completion_tokens, total_tokens, latency_ms, success, error, created_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := r.db.ExecContext(ctx, stmt,
+		usage.UserID,
+		usage.Model,
+		usage.PromptTokens,
+		usage.CompletionTokens,
+		usage.TotalTokens,
+		usage.Latency.Milliseconds(),
+		usage.Success,
+		usage.Error,
+		formatSQLiteTime(createdAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert AI usage: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to insert AI usage: %w", err)
+	}
+
+	usage.ID = int(id)
+	usage.CreatedAt = createdAt
+
+	return nil
+}
+
+// GetAIUsageAggregates summarizes the matching calls by UTC day and model
+func (r *SQLiteAIUsageRepository) GetAIUsageAggregates(ctx context.Context, filter domain.AIUsageFilter) ([]domain.AIUsageAggregate, error) {
+	// Timestamps are stored as fixed-width UTC strings, so they compare and slice lexically
+	stmt := `SELECT substr(created_at, 1, 10) AS day, model,
+			        COUNT(*), SUM(CASE WHEN success THEN 0 ELSE 1 END),
+			        SUM(prompt_tokens), SUM(completion_tokens), SUM(total_tokens),
+			        AVG(latency_ms)
+			 FROM ai_usage
+			 WHERE created_at >= ? AND created_at < ? AND (? = '' OR user_id = ?)
+			 GROUP BY day, model
+			 ORDER BY day, model`
+
+	rows, err := r.db.QueryContext(ctx, stmt,
+		formatSQLiteTime(filter.From),
+		formatSQLiteTime(filter.To),
+		filter.UserID,
+		filter.UserID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query AI usage: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []domain.AIUsageAggregate
+	for rows.Next() {
+		var a domain.AIUsageAggregate
+		if err := rows.Scan(&a.Day, &a.Model, &a.Calls, &a.Failures, &a.PromptTokens, &a.CompletionTokens, &a.TotalTokens, &a.AvgLatencyMs); err != nil {
+			return nil, fmt.Errorf("failed to scan AI usage: %w", err)
+		}
+		aggregates = append(aggregates, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating AI usage: %w", err)
+	}
+
+	return aggregates, nil
+}
@@ -0,0 +1,170 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// SQLiteAPIKeyRepository implements the APIKeyRepository interface
+type SQLiteAPIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAPIKeyRepository creates a new SQLite API key repository
+func NewSQLiteAPIKeyRepository(db *sql.DB) *SQLiteAPIKeyRepository {
+	return &SQLiteAPIKeyRepository{
+		db: db,
+	}
+}
+
+// CreateAPIKey saves a new API key and populates its ID and creation time
+func (r *SQLiteAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	scopes, err := json.Marshal(scopesToStrings(key.Scopes))
+	if err != nil {
+		return fmt.Errorf("failed to encode API key scopes: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	stmt := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := r.db.ExecContext(ctx, stmt,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		string(scopes),
+		formatNullableSQLiteTime(key.ExpiresAt),
+		formatSQLiteTime(now),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert API key: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to insert API key: %w", err)
+	}
+
+	key.ID = int(id)
+	key.CreatedAt = now
+
+	return nil
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its public prefix
+func (r *SQLiteAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	stmt := `SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+			 FROM api_keys WHERE prefix = ?`
+
+	key, err := scanSQLiteAPIKey(r.db.QueryRowContext(ctx, stmt, prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // API key not found
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeys retrieves all API keys for the given user, newest first
+func (r *SQLiteAPIKeyRepository) GetAPIKeys(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	stmt := `SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+			 FROM api_keys WHERE user_id = ? ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.QueryContext(ctx, stmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		key, err := scanSQLiteAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return keys, nil
+}
+
+// DeleteAPIKey deletes an API key by ID for the given user
+func (r *SQLiteAPIKeyRepository) DeleteAPIKey(ctx context.Context, userID string, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_keys WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("API key", id)
+	}
+
+	return nil
+}
+
+// TouchAPIKey records when an API key was last used
+func (r *SQLiteAPIKeyRepository) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	stmt := `UPDATE api_keys SET last_used_at = ? WHERE id = ?`
+
+	if _, err := r.db.ExecContext(ctx, stmt, formatSQLiteTime(usedAt), id); err != nil {
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+
+	return nil
+}
+
+// scanSQLiteAPIKey scans a single API key row, decoding its JSON scopes
+func scanSQLiteAPIKey(row sqliteScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes, createdAt string
+	var expiresAt, lastUsedAt sql.NullString
+
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	if err := json.Unmarshal([]byte(scopes), &values); err != nil {
+		return nil, fmt.Errorf("failed to decode API key scopes: %w", err)
+	}
+	key.Scopes = stringsToScopes(values)
+
+	if key.ExpiresAt, err = parseNullableSQLiteTime(expiresAt); err != nil {
+		return nil, err
+	}
+	if key.LastUsedAt, err = parseNullableSQLiteTime(lastUsedAt); err != nil {
+		return nil, err
+	}
+	if key.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,68 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver
+)
+
+// sqliteTimeFormat is a fixed-width UTC layout, so stored timestamps sort and compare as text
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000Z"
+
+// NewSQLiteConnection opens (creating if needed) the SQLite database at path
+func NewSQLiteConnection(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	// SQLite allows a single writer; serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	// Test the connection
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping sqlite database: %w", err)
+	}
+
+	return db, nil
+}
+
+// formatSQLiteTime formats a timestamp for storage
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+// parseSQLiteTime parses a stored timestamp
+func parseSQLiteTime(value string) (time.Time, error) {
+	t, err := time.Parse(sqliteTimeFormat, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid stored timestamp %q: %w", value, err)
+	}
+	return t, nil
+}
+
+// formatNullableSQLiteTime formats an optional timestamp for storage
+func formatNullableSQLiteTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatSQLiteTime(*t)
+}
+
+// parseNullableSQLiteTime parses an optional stored timestamp
+func parseNullableSQLiteTime(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	t, err := parseSQLiteTime(value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,129 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// SQLiteIdempotencyRepository implements the IdempotencyRepository interface
+type SQLiteIdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteIdempotencyRepository creates a new SQLite idempotency repository
+func NewSQLiteIdempotencyRepository(db *sql.DB) *SQLiteIdempotencyRepository {
+	return &SQLiteIdempotencyRepository{
+		db: db,
+	}
+}
+
+// CreateIdempotencyRecord stores a pending record, reporting false if the key is already taken
+func (r *SQLiteIdempotencyRepository) CreateIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	stmt := `INSERT INTO idempotency_keys (user_id, key, method, path, request_hash, created_at)
+			 VALUES (?, ?, ?, ?, ?, ?)
+			 ON CONFLICT (user_id, key) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, stmt,
+		record.UserID,
+		record.Key,
+		record.Method,
+		record.Path,
+		record.RequestHash,
+		formatSQLiteTime(now),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert idempotency key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to insert idempotency key: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil // Key already exists
+	}
+
+	record.CreatedAt = now
+
+	return true, nil
+}
+
+// GetIdempotencyRecord retrieves the record stored for a user's idempotency key
+func (r *SQLiteIdempotencyRepository) GetIdempotencyRecord(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error) {
+	stmt := `SELECT user_id, key, method, path, request_hash, status_code, response_body, response_headers, completed, created_at
+			 FROM idempotency_keys WHERE user_id = ? AND key = ?`
+
+	var record domain.IdempotencyRecord
+	var statusCode sql.NullInt64
+	var headers, createdAt string
+	err := r.db.QueryRowContext(ctx, stmt, userID, key).Scan(
+		&record.UserID,
+		&record.Key,
+		&record.Method,
+		&record.Path,
+		&record.RequestHash,
+		&statusCode,
+		&record.ResponseBody,
+		&headers,
+		&record.Completed,
+		&createdAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Key not found
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	if err := json.Unmarshal([]byte(headers), &record.ResponseHeaders); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency response headers: %w", err)
+	}
+	if record.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyRecord stores the response of the request that owns the key
+func (r *SQLiteIdempotencyRepository) CompleteIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	headers, err := json.Marshal(nonNilHeaders(record.ResponseHeaders))
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency response headers: %w", err)
+	}
+
+	stmt := `UPDATE idempotency_keys
+			 SET status_code = ?, response_body = ?, response_headers = ?, completed = 1
+			 WHERE user_id = ? AND key = ?`
+
+	_, err = r.db.ExecContext(ctx, stmt,
+		record.StatusCode,
+		record.ResponseBody,
+		string(headers),
+		record.UserID,
+		record.Key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	record.Completed = true
+
+	return nil
+}
+
+// DeleteIdempotencyRecord releases a user's idempotency key
+func (r *SQLiteIdempotencyRepository) DeleteIdempotencyRecord(ctx context.Context, userID, key string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id = ? AND key = ?`, userID, key); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// SQLiteMigrator implements the Migrator interface using a schema_migrations table.
+// SQLite serializes writers itself, so no additional locking is needed.
+type SQLiteMigrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewSQLiteMigrator creates a new SQLite migrator for the migrations in fsys
+func NewSQLiteMigrator(db *sql.DB, fsys fs.FS) (*SQLiteMigrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteMigrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies every pending migration, each in its own transaction
+func (m *SQLiteMigrator) Up(ctx context.Context) (int, error) {
+	if err := m.createMigrationsTable(ctx); err != nil {
+		return 0, err
+	}
+
+	versions, err := m.appliedVersions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, migration := range migrationStatus(m.migrations, versions).Pending {
+		if err := m.apply(ctx, migration, migration.UpSQL,
+			`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			migration.Version, migration.Name, formatSQLiteTime(time.Now())); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+
+	return applied, nil
+}
+
+// Down reverts the latest applied migrations, at most steps of them
+func (m *SQLiteMigrator) Down(ctx context.Context, steps int) (int, error) {
+	if err := m.createMigrationsTable(ctx); err != nil {
+		return 0, err
+	}
+
+	versions, err := m.appliedVersions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+		migration := m.migrations[i]
+		if !versions[migration.Version] {
+			continue
+		}
+		if migration.DownSQL == "" {
+			return reverted, fmt.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+
+		if err := m.apply(ctx, migration, migration.DownSQL,
+			`DELETE FROM schema_migrations WHERE version = ?`, migration.Version); err != nil {
+			return reverted, err
+		}
+		reverted++
+	}
+
+	return reverted, nil
+}
+
+// Status reports the current and latest schema versions without changing the database.
+// Every migration is pending while the schema_migrations table doesn't exist.
+func (m *SQLiteMigrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	var exists bool
+	err := m.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')`).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up schema_migrations table: %w", err)
+	}
+	if !exists {
+		return migrationStatus(m.migrations, nil), nil
+	}
+
+	versions, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrationStatus(m.migrations, versions), nil
+}
+
+// apply runs a migration script and records the change in schema_migrations within one transaction
+func (m *SQLiteMigrator) apply(ctx context.Context, migration Migration, script, record string, args ...any) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("failed to run migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
+
+// appliedVersions returns the set of migration versions recorded in schema_migrations
+func (m *SQLiteMigrator) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		versions[version] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return versions, nil
+}
+
+// createMigrationsTable creates the schema_migrations table if it doesn't exist
+func (m *SQLiteMigrator) createMigrationsTable(ctx context.Context) error {
+	stmt := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`
+
+	if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return nil
+}
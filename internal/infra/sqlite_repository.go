@@ -0,0 +1,613 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// SQLiteTransactionRepository implements the TransactionRepository interface
+type SQLiteTransactionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteTransactionRepository creates a new SQLite transaction repository
+func NewSQLiteTransactionRepository(db *sql.DB) *SQLiteTransactionRepository {
+	return &SQLiteTransactionRepository{
+		db: db,
+	}
+}
+
+// GetTransactionByID retrieves the user's transaction by its ID
+func (r *SQLiteTransactionRepository) GetTransactionByID(ctx context.Context, userID string, id int) (*domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at, deleted_at
+			 FROM transactions WHERE id = ? AND user_id = ? AND deleted_at IS NULL`
+
+	transaction, err := scanSQLiteTransaction(r.db.QueryRowContext(ctx, stmt, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Transaction not found
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// GetTransactions retrieves the user's transactions with pagination
+func (r *SQLiteTransactionRepository) GetTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at, deleted_at
+			 FROM transactions WHERE deleted_at IS NULL AND user_id = ? ORDER BY date DESC, id LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, stmt, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	return collectSQLiteTransactions(rows)
+}
+
+// UpdateTransaction updates an existing transaction of transaction.UserID and sets its
+// CreatedAt and new UpdatedAt
+func (r *SQLiteTransactionRepository) UpdateTransaction(ctx context.Context, transaction *domain.Transaction) error {
+	return withSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		return updateSQLiteTransaction(ctx, tx, transaction)
+	})
+}
+
+// DeleteTransaction moves the user's transaction to the trash, if it has not changed since
+// ifUpdatedAt when that is set
+func (r *SQLiteTransactionRepository) DeleteTransaction(ctx context.Context, userID string, id int, ifUpdatedAt time.Time) error {
+	return withSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		return deleteSQLiteTransaction(ctx, tx, userID, id, ifUpdatedAt)
+	})
+}
+
+// FindTransactions retrieves the transactions matching filter, ordered by ID
+func (r *SQLiteTransactionRepository) FindTransactions(ctx context.Context, filter domain.TransactionFilter) ([]domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at, deleted_at
+			 FROM transactions
+			 WHERE deleted_at IS NULL
+			   AND (?1 = '' OR category = ?1)
+			   AND (?2 = '' OR type = ?2)
+			   AND (?3 = '' OR account = ?3)
+			   AND (?4 = '' OR instr(lower(description), lower(?4)) > 0)
+			   AND (?5 IS NULL OR date >= ?5)
+			   AND (?6 IS NULL OR date <= ?6)
+			   AND user_id = ?7
+			 ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, stmt,
+		string(filter.Category),
+		string(filter.Type),
+		filter.Account,
+		filter.DescriptionContains,
+		formatNullableSQLiteTime(filter.From),
+		formatNullableSQLiteTime(filter.To),
+		filter.UserID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	return collectSQLiteTransactions(rows)
+}
+
+// FindDuplicateCandidates retrieves the user's transactions that share their type, amount
+// and currency with another of the user's transactions dated within dateWindow, by date
+func (r *SQLiteTransactionRepository) FindDuplicateCandidates(ctx context.Context, userID string, dateWindow time.Duration) ([]domain.Transaction, error) {
+	stmt := `SELECT t.id, t.amount, t.currency, t.category, t.type, t.date, t.description, t.account, t.tags, t.created_at, t.updated_at, t.deleted_at
+			 FROM transactions t
+			 WHERE t.user_id = ?1 AND t.deleted_at IS NULL
+			   AND EXISTS (
+			     SELECT 1 FROM transactions o
+			     WHERE o.user_id = t.user_id AND o.id <> t.id AND o.deleted_at IS NULL
+			       AND o.type = t.type
+			       AND lower(o.currency) = lower(t.currency)
+			       AND abs(o.amount - t.amount) < ?3
+			       AND abs(julianday(o.date) - julianday(t.date)) * 86400 <= ?2
+			   )
+			 ORDER BY t.date, t.id`
+
+	rows, err := r.db.QueryContext(ctx, stmt, userID, dateWindow.Seconds(), domain.AmountTolerance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query duplicate candidates: %w", err)
+	}
+	defer rows.Close()
+
+	return collectSQLiteTransactions(rows)
+}
+
+// ApplyTransactionWrites applies the writes in order within a single database
+// transaction, so either all or none of them are applied
+func (r *SQLiteTransactionRepository) ApplyTransactionWrites(ctx context.Context, writes []domain.TransactionWrite) error {
+	if len(writes) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i := range writes {
+		transaction := &writes[i].Transaction
+
+		var err error
+		switch writes[i].Action {
+		case domain.BulkActionCreate:
+			err = insertSQLiteTransaction(ctx, tx, transaction)
+		case domain.BulkActionUpdate:
+			err = updateSQLiteTransaction(ctx, tx, transaction)
+		case domain.BulkActionDelete:
+			err = deleteSQLiteTransaction(ctx, tx, transaction.UserID, transaction.ID, transaction.UpdatedAt)
+		case domain.BulkActionRestore:
+			var restored *domain.Transaction
+			if restored, err = restoreSQLiteTransaction(ctx, tx, transaction.UserID, transaction.ID, transaction.UpdatedAt); err == nil {
+				*transaction = *restored
+			}
+		default:
+			err = fmt.Errorf("unsupported write action %q", writes[i].Action)
+		}
+		if err != nil {
+			return &domain.BatchError{Index: i, Err: err}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeletedTransactions retrieves the user's trashed transactions with pagination, most
+// recently deleted first
+func (r *SQLiteTransactionRepository) GetDeletedTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at, deleted_at
+			 FROM transactions WHERE deleted_at IS NOT NULL AND user_id = ? ORDER BY deleted_at DESC, id DESC LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, stmt, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted transactions: %w", err)
+	}
+	defer rows.Close()
+
+	return collectSQLiteTransactions(rows)
+}
+
+// RestoreTransaction moves the user's transaction out of the trash and returns it
+func (r *SQLiteTransactionRepository) RestoreTransaction(ctx context.Context, userID string, id int) (*domain.Transaction, error) {
+	var restored *domain.Transaction
+	err := withSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		restored, err = restoreSQLiteTransaction(ctx, tx, userID, id, time.Time{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// PurgeDeletedTransactions permanently deletes the transactions trashed before deletedBefore
+// and returns how many were deleted
+func (r *SQLiteTransactionRepository) PurgeDeletedTransactions(ctx context.Context, deletedBefore time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM transactions WHERE deleted_at < ?`, formatSQLiteTime(deletedBefore))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted transactions: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted transactions: %w", err)
+	}
+
+	return int(purged), nil
+}
+
+// GetTransactionHistory returns the audit events of the user's transaction, oldest first
+func (r *SQLiteTransactionRepository) GetTransactionHistory(ctx context.Context, userID string, id int) ([]domain.TransactionEvent, error) {
+	stmt := `SELECT id, transaction_id, action, user_id, actor_id, source, request_id, operation_id, reverts_operation_id, before_state, after_state, created_at
+			 FROM transaction_events WHERE transaction_id = ? AND user_id = ? ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, stmt, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := collectSQLiteTransactionEvents(rows)
+	if err != nil || len(events) > 0 {
+		return events, err
+	}
+
+	// Transactions saved before the audit log was introduced have no events
+	var owned bool
+	stmt = `SELECT EXISTS (SELECT 1 FROM transactions WHERE id = ? AND user_id = ?)`
+	if err := r.db.QueryRowContext(ctx, stmt, id, userID).Scan(&owned); err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if !owned {
+		return nil, domain.NewNotFoundError("transaction", id)
+	}
+
+	return nil, nil
+}
+
+// GetOperation returns the audit events of an operation, or nil if it made no changes
+func (r *SQLiteTransactionRepository) GetOperation(ctx context.Context, id string) (*domain.Operation, error) {
+	stmt := `SELECT id, transaction_id, action, user_id, actor_id, source, request_id, operation_id, reverts_operation_id, before_state, after_state, created_at
+			 FROM transaction_events WHERE operation_id = ?1 OR reverts_operation_id = ?1 ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, stmt, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := collectSQLiteTransactionEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOperation(id, events), nil
+}
+
+// collectSQLiteTransactionEvents scans every remaining row into a transaction event
+func collectSQLiteTransactionEvents(rows *sql.Rows) ([]domain.TransactionEvent, error) {
+	var events []domain.TransactionEvent
+	for rows.Next() {
+		var event domain.TransactionEvent
+		var before, after sql.NullString
+		var createdAt string
+		err := rows.Scan(
+			&event.ID,
+			&event.TransactionID,
+			&event.Action,
+			&event.UserID,
+			&event.ActorID,
+			&event.Source,
+			&event.RequestID,
+			&event.OperationID,
+			&event.RevertsOperationID,
+			&before,
+			&after,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction event: %w", err)
+		}
+		if event.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+			return nil, err
+		}
+		if before.Valid {
+			if event.Before, err = unmarshalTransactionSnapshot([]byte(before.String)); err != nil {
+				return nil, err
+			}
+		}
+		if after.Valid {
+			if event.After, err = unmarshalTransactionSnapshot([]byte(after.String)); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return events, nil
+}
+
+// sqliteQuerier is implemented by *sql.DB and *sql.Tx
+type sqliteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// withSQLiteTransaction runs fn in a database transaction, committed if fn succeeds
+func withSQLiteTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertSQLiteTransaction inserts a transaction, records its creation and sets its ID,
+// CreatedAt and UpdatedAt
+func insertSQLiteTransaction(ctx context.Context, q sqliteQuerier, transaction *domain.Transaction) error {
+	tags, err := marshalSQLiteTags(transaction.Tags)
+	if err != nil {
+		return err
+	}
+
+	stmt := `INSERT INTO transactions (amount, currency, category, type, date, description, account, tags, user_id, created_at, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	result, err := q.ExecContext(ctx, stmt,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.Category,
+		transaction.Type,
+		formatSQLiteTime(transaction.Date),
+		transaction.Description,
+		transaction.Account,
+		tags,
+		transaction.UserID,
+		formatSQLiteTime(now),
+		formatSQLiteTime(now),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+	transaction.ID = int(id)
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
+
+	return appendSQLiteTransactionEvent(ctx, q, domain.NewTransactionEvent(ctx, domain.AuditActionCreate, nil, transaction))
+}
+
+// updateSQLiteTransaction updates a transaction of transaction.UserID, if it has not
+// changed since its UpdatedAt when that is set, records the change and sets its CreatedAt
+// and new UpdatedAt. q must be a database transaction.
+func updateSQLiteTransaction(ctx context.Context, q sqliteQuerier, transaction *domain.Transaction) error {
+	before, err := getSQLiteTransaction(ctx, q, transaction.UserID, transaction.ID, false)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return domain.NewNotFoundError("transaction", transaction.ID)
+	}
+	if err := checkUnmodified(*before, transaction.UpdatedAt); err != nil {
+		return err
+	}
+
+	tags, err := marshalSQLiteTags(transaction.Tags)
+	if err != nil {
+		return err
+	}
+
+	stmt := `UPDATE transactions
+			 SET amount = ?, currency = ?, category = ?, type = ?, date = ?, description = ?, account = ?, tags = ?, updated_at = ?
+			 WHERE id = ? AND user_id = ?`
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	_, err = q.ExecContext(ctx, stmt,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.Category,
+		transaction.Type,
+		formatSQLiteTime(transaction.Date),
+		transaction.Description,
+		transaction.Account,
+		tags,
+		formatSQLiteTime(now),
+		transaction.ID,
+		transaction.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	transaction.CreatedAt = before.CreatedAt
+	transaction.UpdatedAt = now
+	after := *transaction
+	after.DeletedAt = nil
+	return appendSQLiteTransactionEvent(ctx, q, domain.NewTransactionEvent(ctx, domain.AuditActionUpdate, before, &after))
+}
+
+// deleteSQLiteTransaction moves the user's transaction to the trash, if it has not changed
+// since ifUpdatedAt when that is set, and records the change. q must be a database
+// transaction.
+func deleteSQLiteTransaction(ctx context.Context, q sqliteQuerier, userID string, id int, ifUpdatedAt time.Time) error {
+	before, err := getSQLiteTransaction(ctx, q, userID, id, false)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return domain.NewNotFoundError("transaction", id)
+	}
+	if err := checkUnmodified(*before, ifUpdatedAt); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	stmt := `UPDATE transactions SET deleted_at = ?, updated_at = ? WHERE id = ? AND user_id = ?`
+	if _, err := q.ExecContext(ctx, stmt, formatSQLiteTime(now), formatSQLiteTime(now), id, userID); err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+
+	after := *before
+	after.UpdatedAt = now
+	after.DeletedAt = &now
+	return appendSQLiteTransactionEvent(ctx, q, domain.NewTransactionEvent(ctx, domain.AuditActionDelete, before, &after))
+}
+
+// restoreSQLiteTransaction moves the user's transaction out of the trash, if it has not
+// changed since ifUpdatedAt when that is set, records the change and returns it. q must be
+// a database transaction.
+func restoreSQLiteTransaction(ctx context.Context, q sqliteQuerier, userID string, id int, ifUpdatedAt time.Time) (*domain.Transaction, error) {
+	before, err := getSQLiteTransaction(ctx, q, userID, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, trashedTransactionNotFoundError(id)
+	}
+	if err := checkUnmodified(*before, ifUpdatedAt); err != nil {
+		return nil, err
+	}
+
+	after := *before
+	after.DeletedAt = nil
+	after.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	if _, err := q.ExecContext(ctx, `UPDATE transactions SET deleted_at = NULL, updated_at = ? WHERE id = ? AND user_id = ?`, formatSQLiteTime(after.UpdatedAt), id, userID); err != nil {
+		return nil, fmt.Errorf("failed to restore transaction: %w", err)
+	}
+
+	if err := appendSQLiteTransactionEvent(ctx, q, domain.NewTransactionEvent(ctx, domain.AuditActionRestore, before, &after)); err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+// getSQLiteTransaction reads a live or, if trashed is set, trashed transaction of the
+// user. It returns nil if there is none.
+func getSQLiteTransaction(ctx context.Context, q sqliteQuerier, userID string, id int, trashed bool) (*domain.Transaction, error) {
+	stmt := `SELECT id, amount, currency, category, type, date, description, account, tags, created_at, updated_at, deleted_at
+			 FROM transactions WHERE id = ? AND user_id = ? AND (deleted_at IS NOT NULL) = ?`
+
+	transaction, err := scanSQLiteTransaction(q.QueryRowContext(ctx, stmt, id, userID, trashed))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// appendSQLiteTransactionEvent appends an event to the audit log, owned by the owner of
+// its transaction
+func appendSQLiteTransactionEvent(ctx context.Context, q sqliteQuerier, event domain.TransactionEvent) error {
+	before, err := marshalTransactionSnapshot(event.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalTransactionSnapshot(event.After)
+	if err != nil {
+		return err
+	}
+
+	stmt := `INSERT INTO transaction_events (transaction_id, user_id, action, actor_id, source, request_id, operation_id, reverts_operation_id, before_state, after_state, created_at)
+			 VALUES (?1, (SELECT user_id FROM transactions WHERE id = ?1), ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)`
+
+	_, err = q.ExecContext(ctx, stmt,
+		event.TransactionID,
+		event.Action,
+		event.ActorID,
+		event.Source,
+		event.RequestID,
+		event.OperationID,
+		event.RevertsOperationID,
+		nullableSQLiteText(before),
+		nullableSQLiteText(after),
+		formatSQLiteTime(time.Now().UTC().Truncate(time.Microsecond)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record transaction event: %w", err)
+	}
+
+	return nil
+}
+
+// nullableSQLiteText stores data as text, or NULL when it is nil
+func nullableSQLiteText(data []byte) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+// sqliteScanner is implemented by both *sql.Row and *sql.Rows
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSQLiteTransaction scans a single transaction row, decoding its date and tags
+func scanSQLiteTransaction(row sqliteScanner) (*domain.Transaction, error) {
+	var transaction domain.Transaction
+	var date, tags, createdAt, updatedAt string
+	var deletedAt sql.NullString
+
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.Amount,
+		&transaction.Currency,
+		&transaction.Category,
+		&transaction.Type,
+		&date,
+		&transaction.Description,
+		&transaction.Account,
+		&tags,
+		&createdAt,
+		&updatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.Date, err = parseSQLiteTime(date); err != nil {
+		return nil, err
+	}
+	if transaction.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if transaction.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+	if transaction.DeletedAt, err = parseNullableSQLiteTime(deletedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &transaction.Tags); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction tags: %w", err)
+	}
+
+	return &transaction, nil
+}
+
+// collectSQLiteTransactions scans every remaining row into a transaction
+func collectSQLiteTransactions(rows *sql.Rows) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	for rows.Next() {
+		transaction, err := scanSQLiteTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, *transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// marshalSQLiteTags encodes tags as a JSON array for storage
+func marshalSQLiteTags(tags []string) (string, error) {
+	data, err := json.Marshal(nonNilTags(tags))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transaction tags: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,196 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// SQLiteRuleRepository implements the RuleRepository interface
+type SQLiteRuleRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRuleRepository creates a new SQLite rule repository
+func NewSQLiteRuleRepository(db *sql.DB) *SQLiteRuleRepository {
+	return &SQLiteRuleRepository{
+		db: db,
+	}
+}
+
+// CreateRule saves a new rule and populates its ID and timestamps
+func (r *SQLiteRuleRepository) CreateRule(ctx context.Context, rule *domain.Rule) error {
+	conditions, actions, err := marshalRuleBody(rule)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	stmt := `INSERT INTO rules (user_id, name, position, enabled, conditions, actions, created_at, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := r.db.ExecContext(ctx, stmt,
+		rule.UserID,
+		rule.Name,
+		rule.Position,
+		rule.Enabled,
+		string(conditions),
+		string(actions),
+		formatSQLiteTime(now),
+		formatSQLiteTime(now),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert rule: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to insert rule: %w", err)
+	}
+
+	rule.ID = int(id)
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	return nil
+}
+
+// GetRuleByID retrieves a rule by its ID for the given user
+func (r *SQLiteRuleRepository) GetRuleByID(ctx context.Context, userID string, id int) (*domain.Rule, error) {
+	stmt := `SELECT id, user_id, name, position, enabled, conditions, actions, created_at, updated_at
+			 FROM rules WHERE id = ? AND user_id = ?`
+
+	rule, err := scanSQLiteRule(r.db.QueryRowContext(ctx, stmt, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Rule not found
+		}
+		return nil, fmt.Errorf("failed to get rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetRules retrieves all rules for the given user ordered by position
+func (r *SQLiteRuleRepository) GetRules(ctx context.Context, userID string) ([]domain.Rule, error) {
+	stmt := `SELECT id, user_id, name, position, enabled, conditions, actions, created_at, updated_at
+			 FROM rules WHERE user_id = ? ORDER BY position, id`
+
+	rows, err := r.db.QueryContext(ctx, stmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []domain.Rule
+	for rows.Next() {
+		rule, err := scanSQLiteRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return rules, nil
+}
+
+// UpdateRule updates an existing rule owned by rule.UserID
+func (r *SQLiteRuleRepository) UpdateRule(ctx context.Context, rule *domain.Rule) error {
+	conditions, actions, err := marshalRuleBody(rule)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	stmt := `UPDATE rules
+			 SET name = ?, position = ?, enabled = ?, conditions = ?, actions = ?, updated_at = ?
+			 WHERE id = ? AND user_id = ?
+			 RETURNING created_at`
+
+	var createdAt string
+	err = r.db.QueryRowContext(ctx, stmt,
+		rule.Name,
+		rule.Position,
+		rule.Enabled,
+		string(conditions),
+		string(actions),
+		formatSQLiteTime(now),
+		rule.ID,
+		rule.UserID,
+	).Scan(&createdAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.NewNotFoundError("rule", rule.ID)
+		}
+		return fmt.Errorf("failed to update rule: %w", err)
+	}
+
+	if rule.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return err
+	}
+	rule.UpdatedAt = now
+
+	return nil
+}
+
+// DeleteRule deletes a rule by ID for the given user
+func (r *SQLiteRuleRepository) DeleteRule(ctx context.Context, userID string, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM rules WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("rule", id)
+	}
+
+	return nil
+}
+
+// scanSQLiteRule scans a single rule row, decoding its JSON conditions and actions
+func scanSQLiteRule(row sqliteScanner) (*domain.Rule, error) {
+	var rule domain.Rule
+	var conditions, actions, createdAt, updatedAt string
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.Name,
+		&rule.Position,
+		&rule.Enabled,
+		&conditions,
+		&actions,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(conditions), &rule.Conditions); err != nil {
+		return nil, fmt.Errorf("failed to decode rule conditions: %w", err)
+	}
+	if err := json.Unmarshal([]byte(actions), &rule.Actions); err != nil {
+		return nil, fmt.Errorf("failed to decode rule actions: %w", err)
+	}
+	if rule.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if rule.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
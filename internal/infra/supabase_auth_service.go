@@ -11,23 +11,78 @@ import (
 	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
 )
 
-// SupabaseAuthService implements the AuthService interface
+// SupabaseAuthService implements the AuthService interface.
+// HS256 tokens are verified with the shared JWT secret and asymmetric tokens
+// (RS256, ES256, EdDSA) with the project's JSON Web Key Set; either can be disabled
+// by leaving its configuration empty.
 type SupabaseAuthService struct {
 	jwtSecret string
+	keys      *JWKSKeySet
+	audience  string
+	issuer    string
 }
 
 // NewSupabaseAuthService creates a new Supabase authentication service
 func NewSupabaseAuthService(cfg *config.Config) *SupabaseAuthService {
-	return &SupabaseAuthService{
+	service := &SupabaseAuthService{
 		jwtSecret: cfg.Supabase.JWTSecret,
+		audience:  cfg.Supabase.JWTAudience,
+		issuer:    cfg.Supabase.JWTIssuer,
 	}
+
+	if cfg.Supabase.JWKSURL != "" {
+		refreshInterval := cfg.Supabase.JWKSRefreshInterval
+		if refreshInterval <= 0 {
+			refreshInterval = 10 * time.Minute
+		}
+		service.keys = NewJWKSKeySet(cfg.Supabase.JWKSURL, refreshInterval, nil)
+	}
+
+	return service
+}
+
+// StartKeyRefresh fetches the signing keys and keeps refreshing them in the background
+// until ctx is cancelled. It is a no-op when JWKS verification is disabled.
+func (s *SupabaseAuthService) StartKeyRefresh(ctx context.Context) error {
+	if s.keys == nil {
+		return nil
+	}
+
+	s.keys.Start(ctx)
+
+	if err := s.keys.Refresh(ctx); err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	return nil
 }
 
 // SupabaseClaims represents the custom claims in a Supabase JWT token
 type SupabaseClaims struct {
 	jwt.RegisteredClaims
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	Email       string              `json:"email"`
+	Role        string              `json:"role"`
+	AppMetadata SupabaseAppMetadata `json:"app_metadata"`
+}
+
+// SupabaseAppMetadata holds the server-controlled app_metadata claim, where
+// application roles are set with either a single role or a list of roles
+type SupabaseAppMetadata struct {
+	Role  string   `json:"role"`
+	Roles []string `json:"roles"`
+}
+
+// roles returns the application roles granted in app_metadata
+func (m SupabaseAppMetadata) roles() []string {
+	var roles []string
+	if m.Role != "" {
+		roles = append(roles, m.Role)
+	}
+	for _, role := range m.Roles {
+		if role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
 }
 
 // ValidateToken validates a Supabase JWT token and returns user information
@@ -35,14 +90,18 @@ func (s *SupabaseAuthService) ValidateToken(ctx context.Context, tokenString str
 	// Remove Bearer prefix if present
 	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
+	var options []jwt.ParserOption
+	if s.audience != "" {
+		options = append(options, jwt.WithAudience(s.audience))
+	}
+	if s.issuer != "" {
+		options = append(options, jwt.WithIssuer(s.issuer))
+	}
+
 	// Parse and validate the token
 	token, err := jwt.ParseWithClaims(tokenString, &SupabaseClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.jwtSecret), nil
-	})
+		return s.verificationKey(ctx, token)
+	}, options...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -78,7 +137,33 @@ func (s *SupabaseAuthService) ValidateToken(ctx context.Context, tokenString str
 	user := &domain.AuthUser{
 		ID:    claims.Subject,
 		Email: claims.Email,
+		Role:  claims.Role,
+		Roles: claims.AppMetadata.roles(),
 	}
 
 	return user, nil
 }
+
+// verificationKey selects the key used to verify the token's signature based on its algorithm
+func (s *SupabaseAuthService) verificationKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if s.jwtSecret == "" {
+			return nil, fmt.Errorf("HMAC-signed tokens are not accepted")
+		}
+		return []byte(s.jwtSecret), nil
+
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+		if s.keys == nil {
+			return nil, fmt.Errorf("asymmetrically signed tokens are not accepted")
+		}
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("token header is missing kid")
+		}
+		return s.keys.Key(ctx, kid)
+
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+}
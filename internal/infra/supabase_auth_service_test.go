@@ -0,0 +1,247 @@
+package infra
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jairogloz/go-expense-tracker-back/config"
+)
+
+const (
+	testIssuer   = "https://project.supabase.co/auth/v1"
+	testAudience = "authenticated"
+)
+
+// jwksServer serves a mutable JSON Web Key Set and counts how often it is fetched
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []map[string]string
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	t.Helper()
+	s := &jwksServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"alg": "ES256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":   "user-123",
+		"email": "user@example.com",
+		"aud":   testAudience,
+		"iss":   testIssuer,
+		"iat":   now.Add(-time.Minute).Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+}
+
+func newTestAuthService(jwksURL, secret string) *SupabaseAuthService {
+	return NewSupabaseAuthService(&config.Config{
+		Supabase: config.SupabaseConfig{
+			JWTSecret:           secret,
+			JWKSURL:             jwksURL,
+			JWKSRefreshInterval: time.Hour,
+			JWTAudience:         testAudience,
+			JWTIssuer:           testIssuer,
+		},
+	})
+}
+
+func TestSupabaseAuthServiceJWKS(t *testing.T) {
+	ctx := context.Background()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newJWKSServer(t)
+	server.setKeys(rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey))
+	service := newTestAuthService(server.URL, "")
+
+	t.Run("accepts RS256 and ES256 tokens", func(t *testing.T) {
+		for _, token := range []string{
+			signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims()),
+			signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, validClaims()),
+		} {
+			user, err := service.ValidateToken(ctx, token)
+			if err != nil {
+				t.Fatalf("ValidateToken: %v", err)
+			}
+			if user.ID != "user-123" || user.Email != "user@example.com" {
+				t.Errorf("unexpected user: %+v", user)
+			}
+		}
+		if got := server.fetches.Load(); got != 1 {
+			t.Errorf("expected keys to be fetched once and cached, got %d fetches", got)
+		}
+	})
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wrongAudience := validClaims()
+		wrongAudience["aud"] = "anon"
+		wrongIssuer := validClaims()
+		wrongIssuer["iss"] = "https://attacker.example.com/auth/v1"
+		expired := validClaims()
+		expired["exp"] = time.Now().Add(-time.Minute).Unix()
+
+		tests := map[string]string{
+			"wrong audience": signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, wrongAudience),
+			"wrong issuer":   signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, wrongIssuer),
+			"expired":        signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, expired),
+			"wrong key":      signToken(t, jwt.SigningMethodRS256, "rsa-1", otherKey, validClaims()),
+			"missing kid":    signToken(t, jwt.SigningMethodRS256, "", rsaKey, validClaims()),
+			"HS256 disabled": signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), validClaims()),
+		}
+		for name, token := range tests {
+			t.Run(name, func(t *testing.T) {
+				if _, err := service.ValidateToken(ctx, token); err == nil {
+					t.Error("expected token to be rejected")
+				}
+			})
+		}
+	})
+
+	t.Run("picks up rotated keys", func(t *testing.T) {
+		rotatedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server.setKeys(rsaJWK("rsa-2", rotatedKey))
+
+		// Pretend the cache is old enough for an unknown kid to trigger a refetch
+		service.keys.mu.Lock()
+		service.keys.fetchedAt = time.Now().Add(-time.Minute)
+		service.keys.mu.Unlock()
+
+		token := signToken(t, jwt.SigningMethodRS256, "rsa-2", rotatedKey, validClaims())
+		if _, err := service.ValidateToken(ctx, token); err != nil {
+			t.Fatalf("ValidateToken: %v", err)
+		}
+
+		// Unknown kids right after a refresh are rejected without another fetch
+		fetches := server.fetches.Load()
+		token = signToken(t, jwt.SigningMethodRS256, "rsa-3", rotatedKey, validClaims())
+		if _, err := service.ValidateToken(ctx, token); err == nil {
+			t.Error("expected unknown kid to be rejected")
+		}
+		if got := server.fetches.Load(); got != fetches {
+			t.Errorf("expected no refetch, got %d fetches", got-fetches)
+		}
+	})
+}
+
+func TestSupabaseAuthServiceHS256(t *testing.T) {
+	ctx := context.Background()
+	service := newTestAuthService("", "shared-secret")
+
+	token := signToken(t, jwt.SigningMethodHS256, "", []byte("shared-secret"), validClaims())
+	if _, err := service.ValidateToken(ctx, "Bearer "+token); err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+
+	token = signToken(t, jwt.SigningMethodHS256, "", []byte("other-secret"), validClaims())
+	if _, err := service.ValidateToken(ctx, token); err == nil {
+		t.Error("expected token signed with another secret to be rejected")
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token = signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims())
+	if _, err := service.ValidateToken(ctx, token); err == nil {
+		t.Error("expected RS256 token to be rejected without a JWKS URL")
+	}
+}
+
+func TestJWKSKeySetBackgroundRefresh(t *testing.T) {
+	server := newJWKSServer(t)
+	keys := NewJWKSKeySet(server.URL, 20*time.Millisecond, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keys.Start(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.fetches.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected periodic refreshes, got %d fetches", server.fetches.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
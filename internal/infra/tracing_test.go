@@ -0,0 +1,90 @@
+package infra
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans installs a tracer provider recording every span for the duration of the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestTracingTransactionRepository(t *testing.T) {
+	spans := recordSpans(t)
+	repo := NewTracingTransactionRepository(NewMemoryTransactionRepository())
+	ctx := context.Background()
+
+	ctx, parent := otel.Tracer(TracerName).Start(ctx, "request")
+	if _, err := repo.GetTransactions(ctx, "user-1", 10, 0); err != nil {
+		t.Fatalf("GetTransactions: %v", err)
+	}
+	if err := repo.DeleteTransaction(ctx, "user-1", 42, time.Time{}); err == nil {
+		t.Fatalf("expected deleting a missing transaction to fail")
+	}
+	parent.End()
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(ended))
+	}
+
+	get, del := ended[0], ended[1]
+	if get.Name() != "TransactionRepository.GetTransactions" || get.Status().Code == codes.Error {
+		t.Errorf("unexpected span %q with status %v", get.Name(), get.Status())
+	}
+	if del.Name() != "TransactionRepository.DeleteTransaction" || del.Status().Code != codes.Error {
+		t.Errorf("expected a failed DeleteTransaction span, got %q with status %v", del.Name(), del.Status())
+	}
+	for _, span := range []sdktrace.ReadOnlySpan{get, del} {
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("expected %q to be a child of the request span", span.Name())
+		}
+	}
+}
+
+func TestSetupTracing(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	for _, exporter := range []string{config.TracingExporterNone, config.TracingExporterOTLP} {
+		shutdown, err := SetupTracing(context.Background(), config.TracingConfig{
+			Exporter:     exporter,
+			OTLPEndpoint: "localhost:4318",
+			ServiceName:  "expense-tracker-test",
+			SampleRatio:  1,
+		})
+		if err != nil {
+			t.Fatalf("SetupTracing(%s): %v", exporter, err)
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("shutdown(%s): %v", exporter, err)
+		}
+	}
+}
+
+func TestSQLOperation(t *testing.T) {
+	for sql, expected := range map[string]string{
+		"SELECT id FROM transactions":          "SELECT",
+		"\n\t\t insert into transactions (id)": "INSERT",
+		"":                                     "QUERY",
+	} {
+		if got := sqlOperation(sql); got != expected {
+			t.Errorf("sqlOperation(%q) = %q, expected %q", sql, got, expected)
+		}
+	}
+}
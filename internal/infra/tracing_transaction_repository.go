@@ -0,0 +1,135 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingTransactionRepository decorates a TransactionRepository with a span per method,
+// so database time shows up in traces whichever storage backend is used
+type TracingTransactionRepository struct {
+	next domain.TransactionRepository
+}
+
+// NewTracingTransactionRepository wraps next with tracing
+func NewTracingTransactionRepository(next domain.TransactionRepository) *TracingTransactionRepository {
+	return &TracingTransactionRepository{
+		next: next,
+	}
+}
+
+// GetTransactionByID traces next.GetTransactionByID
+func (r *TracingTransactionRepository) GetTransactionByID(ctx context.Context, userID string, id int) (transaction *domain.Transaction, err error) {
+	ctx, span := startRepositorySpan(ctx, "GetTransactionByID", attribute.Int("transaction.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.GetTransactionByID(ctx, userID, id)
+}
+
+// GetTransactions traces next.GetTransactions
+func (r *TracingTransactionRepository) GetTransactions(ctx context.Context, userID string, limit, offset int) (transactions []domain.Transaction, err error) {
+	ctx, span := startRepositorySpan(ctx, "GetTransactions", attribute.Int("limit", limit), attribute.Int("offset", offset))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.GetTransactions(ctx, userID, limit, offset)
+}
+
+// UpdateTransaction traces next.UpdateTransaction
+func (r *TracingTransactionRepository) UpdateTransaction(ctx context.Context, transaction *domain.Transaction) (err error) {
+	ctx, span := startRepositorySpan(ctx, "UpdateTransaction", attribute.Int("transaction.id", transaction.ID))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.UpdateTransaction(ctx, transaction)
+}
+
+// DeleteTransaction traces next.DeleteTransaction
+func (r *TracingTransactionRepository) DeleteTransaction(ctx context.Context, userID string, id int, ifUpdatedAt time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "DeleteTransaction", attribute.Int("transaction.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.DeleteTransaction(ctx, userID, id, ifUpdatedAt)
+}
+
+// FindTransactions traces next.FindTransactions
+func (r *TracingTransactionRepository) FindTransactions(ctx context.Context, filter domain.TransactionFilter) (transactions []domain.Transaction, err error) {
+	ctx, span := startRepositorySpan(ctx, "FindTransactions")
+	defer func() { endSpan(span, err) }()
+
+	return r.next.FindTransactions(ctx, filter)
+}
+
+// FindDuplicateCandidates traces next.FindDuplicateCandidates
+func (r *TracingTransactionRepository) FindDuplicateCandidates(ctx context.Context, userID string, dateWindow time.Duration) (transactions []domain.Transaction, err error) {
+	ctx, span := startRepositorySpan(ctx, "FindDuplicateCandidates")
+	defer func() { endSpan(span, err) }()
+
+	return r.next.FindDuplicateCandidates(ctx, userID, dateWindow)
+}
+
+// ApplyTransactionWrites traces next.ApplyTransactionWrites
+func (r *TracingTransactionRepository) ApplyTransactionWrites(ctx context.Context, writes []domain.TransactionWrite) (err error) {
+	ctx, span := startRepositorySpan(ctx, "ApplyTransactionWrites", attribute.Int("writes.count", len(writes)))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.ApplyTransactionWrites(ctx, writes)
+}
+
+// GetDeletedTransactions traces next.GetDeletedTransactions
+func (r *TracingTransactionRepository) GetDeletedTransactions(ctx context.Context, userID string, limit, offset int) (transactions []domain.Transaction, err error) {
+	ctx, span := startRepositorySpan(ctx, "GetDeletedTransactions", attribute.Int("limit", limit), attribute.Int("offset", offset))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.GetDeletedTransactions(ctx, userID, limit, offset)
+}
+
+// RestoreTransaction traces next.RestoreTransaction
+func (r *TracingTransactionRepository) RestoreTransaction(ctx context.Context, userID string, id int) (transaction *domain.Transaction, err error) {
+	ctx, span := startRepositorySpan(ctx, "RestoreTransaction", attribute.Int("transaction.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.RestoreTransaction(ctx, userID, id)
+}
+
+// PurgeDeletedTransactions traces next.PurgeDeletedTransactions
+func (r *TracingTransactionRepository) PurgeDeletedTransactions(ctx context.Context, deletedBefore time.Time) (purged int, err error) {
+	ctx, span := startRepositorySpan(ctx, "PurgeDeletedTransactions")
+	defer func() { endSpan(span, err) }()
+
+	return r.next.PurgeDeletedTransactions(ctx, deletedBefore)
+}
+
+// GetTransactionHistory traces next.GetTransactionHistory
+func (r *TracingTransactionRepository) GetTransactionHistory(ctx context.Context, userID string, id int) (events []domain.TransactionEvent, err error) {
+	ctx, span := startRepositorySpan(ctx, "GetTransactionHistory", attribute.Int("transaction.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.GetTransactionHistory(ctx, userID, id)
+}
+
+// GetOperation traces next.GetOperation
+func (r *TracingTransactionRepository) GetOperation(ctx context.Context, id string) (operation *domain.Operation, err error) {
+	ctx, span := startRepositorySpan(ctx, "GetOperation", attribute.String("operation.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.GetOperation(ctx, id)
+}
+
+// startRepositorySpan starts a span named after a TransactionRepository method
+func startRepositorySpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, "TransactionRepository."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, marking it failed when err is set
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,726 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+	"github.com/jairogloz/go-expense-tracker-back/migrations"
+)
+
+// testTransactionRepositoryContract verifies the behavior every domain.TransactionRepository
+// implementation must provide. newRepo must return a repository backed by empty storage.
+func testTransactionRepositoryContract(t *testing.T, newRepo func(t *testing.T) domain.TransactionRepository) {
+	ctx := context.Background()
+	base := time.Date(2024, 8, 14, 15, 30, 0, 0, time.UTC)
+
+	sample := func(amount float64, date time.Time, description string) domain.Transaction {
+		return domain.Transaction{
+			Amount:      amount,
+			Currency:    "MXN",
+			Category:    domain.CategoryFood,
+			Type:        domain.Expense,
+			Date:        date,
+			Description: description,
+		}
+	}
+
+	// saveAll saves the transactions and returns them as stored, newest first
+	saveAll := func(t *testing.T, repo domain.TransactionRepository, transactions ...domain.Transaction) []domain.Transaction {
+		t.Helper()
+		if err := testutil.SaveTransactions(ctx, repo, transactions); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		stored, err := repo.GetTransactions(ctx, "", 100, 0)
+		if err != nil {
+			t.Fatalf("GetTransactions: %v", err)
+		}
+		return stored
+	}
+
+	t.Run("saves and retrieves transactions", func(t *testing.T) {
+		repo := newRepo(t)
+		transaction := sample(50, base, "Groceries")
+		transaction.Account = "Checking"
+		transaction.Tags = []string{"weekly", "market"}
+
+		stored := saveAll(t, repo, transaction)
+		if len(stored) != 1 {
+			t.Fatalf("expected 1 transaction, got %d", len(stored))
+		}
+
+		got, err := repo.GetTransactionByID(ctx, "", stored[0].ID)
+		if err != nil {
+			t.Fatalf("GetTransactionByID: %v", err)
+		}
+		if got == nil {
+			t.Fatal("expected transaction, got nil")
+		}
+
+		if got.Amount != 50 || got.Currency != "MXN" || got.Category != domain.CategoryFood ||
+			got.Type != domain.Expense || got.Description != "Groceries" || got.Account != "Checking" {
+			t.Errorf("unexpected transaction: %+v", got)
+		}
+		if !got.Date.Equal(base) {
+			t.Errorf("expected date %v, got %v", base, got.Date)
+		}
+		if len(got.Tags) != 2 || got.Tags[0] != "weekly" || got.Tags[1] != "market" {
+			t.Errorf("expected tags [weekly market], got %v", got.Tags)
+		}
+	})
+
+	t.Run("creating sets IDs and timestamps", func(t *testing.T) {
+		repo := newRepo(t)
+		transactions := []domain.Transaction{sample(50, base, "Groceries"), sample(20, base, "Snacks")}
+		if err := testutil.SaveTransactions(ctx, repo, transactions); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		if transactions[0].ID == 0 || transactions[1].ID == 0 || transactions[0].ID == transactions[1].ID {
+			t.Fatalf("expected distinct IDs, got %d and %d", transactions[0].ID, transactions[1].ID)
+		}
+		for _, saved := range transactions {
+			if saved.CreatedAt.IsZero() || saved.UpdatedAt.IsZero() {
+				t.Errorf("expected timestamps to be set, got %+v", saved)
+			}
+
+			got, err := repo.GetTransactionByID(ctx, "", saved.ID)
+			if err != nil {
+				t.Fatalf("GetTransactionByID: %v", err)
+			}
+			if got == nil || got.Description != saved.Description || !got.CreatedAt.Equal(saved.CreatedAt) || !got.UpdatedAt.Equal(saved.UpdatedAt) {
+				t.Errorf("expected transaction %d to be stored as saved, got %+v", saved.ID, got)
+			}
+		}
+
+		updated := transactions[0]
+		updated.Amount = 55
+		if err := repo.UpdateTransaction(ctx, &updated); err != nil {
+			t.Fatalf("UpdateTransaction: %v", err)
+		}
+		if !updated.CreatedAt.Equal(transactions[0].CreatedAt) {
+			t.Errorf("expected updates to keep CreatedAt %v, got %v", transactions[0].CreatedAt, updated.CreatedAt)
+		}
+	})
+
+	t.Run("applying no writes is a no-op", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.ApplyTransactionWrites(ctx, nil); err != nil {
+			t.Fatalf("ApplyTransactionWrites: %v", err)
+		}
+	})
+
+	t.Run("returns nil for a missing transaction", func(t *testing.T) {
+		repo := newRepo(t)
+		got, err := repo.GetTransactionByID(ctx, "", 999999)
+		if err != nil {
+			t.Fatalf("GetTransactionByID: %v", err)
+		}
+		if got != nil {
+			t.Errorf("expected nil, got %+v", got)
+		}
+	})
+
+	t.Run("paginates newest first", func(t *testing.T) {
+		repo := newRepo(t)
+		saveAll(t, repo,
+			sample(1, base, "first"),
+			sample(2, base.Add(2*time.Hour), "third"),
+			sample(3, base.Add(time.Hour), "second"),
+		)
+
+		page, err := repo.GetTransactions(ctx, "", 2, 0)
+		if err != nil {
+			t.Fatalf("GetTransactions: %v", err)
+		}
+		if len(page) != 2 || page[0].Description != "third" || page[1].Description != "second" {
+			t.Fatalf("unexpected first page: %+v", page)
+		}
+
+		page, err = repo.GetTransactions(ctx, "", 2, 2)
+		if err != nil {
+			t.Fatalf("GetTransactions: %v", err)
+		}
+		if len(page) != 1 || page[0].Description != "first" {
+			t.Fatalf("unexpected second page: %+v", page)
+		}
+	})
+
+	t.Run("reads only return the transactions of their user", func(t *testing.T) {
+		repo := newRepo(t)
+		owned := []domain.Transaction{sample(50, base, "Groceries"), sample(20, base, "Snacks")}
+		owned[0].UserID = "user-1"
+		if err := testutil.SaveTransactions(ctx, repo, owned); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		if got, err := repo.GetTransactionByID(ctx, "user-2", owned[0].ID); err != nil || got != nil {
+			t.Errorf("expected no transaction reading another user's, got %+v, %v", got, err)
+		}
+		if got, err := repo.GetTransactionByID(ctx, "user-1", owned[1].ID); err != nil || got != nil {
+			t.Errorf("expected no transaction reading an unowned one, got %+v, %v", got, err)
+		}
+		if got, _ := repo.GetTransactionByID(ctx, "user-1", owned[0].ID); got == nil || got.Description != "Groceries" {
+			t.Errorf("expected the user's transaction, got %+v", got)
+		}
+		if page, _ := repo.GetTransactions(ctx, "user-1", 10, 0); len(page) != 1 || page[0].ID != owned[0].ID {
+			t.Errorf("expected only the user's transaction, got %+v", page)
+		}
+		if page, _ := repo.GetTransactions(ctx, "user-2", 10, 0); len(page) != 0 {
+			t.Errorf("expected no transactions for another user, got %+v", page)
+		}
+	})
+
+	t.Run("updates a transaction", func(t *testing.T) {
+		repo := newRepo(t)
+		stored := saveAll(t, repo, sample(50, base, "Groceries"))
+
+		updated := stored[0]
+		updated.Amount = 75.5
+		updated.Category = domain.CategoryShopping
+		updated.Description = "Mall"
+		updated.Tags = []string{"clothes"}
+		if err := repo.UpdateTransaction(ctx, &updated); err != nil {
+			t.Fatalf("UpdateTransaction: %v", err)
+		}
+
+		got, err := repo.GetTransactionByID(ctx, "", updated.ID)
+		if err != nil {
+			t.Fatalf("GetTransactionByID: %v", err)
+		}
+		if got.Amount != 75.5 || got.Category != domain.CategoryShopping || got.Description != "Mall" ||
+			len(got.Tags) != 1 || got.Tags[0] != "clothes" {
+			t.Errorf("unexpected transaction after update: %+v", got)
+		}
+	})
+
+	t.Run("conditional writes fail once the transaction changed", func(t *testing.T) {
+		repo := newRepo(t)
+		stored := saveAll(t, repo, sample(50, base, "Groceries"))
+		read := stored[0]
+		if read.UpdatedAt.IsZero() {
+			t.Fatal("expected UpdatedAt to be set")
+		}
+
+		first := read
+		first.Description = "first device"
+		if err := repo.UpdateTransaction(ctx, &first); err != nil {
+			t.Fatalf("UpdateTransaction: %v", err)
+		}
+		if first.UpdatedAt.Equal(read.UpdatedAt) {
+			t.Fatal("expected the update to change UpdatedAt")
+		}
+
+		second := read
+		second.Description = "second device"
+		if err := repo.UpdateTransaction(ctx, &second); !errors.Is(err, domain.ErrPreconditionFailed) {
+			t.Errorf("expected ErrPreconditionFailed for a stale update, got %v", err)
+		}
+		if err := repo.DeleteTransaction(ctx, "", read.ID, read.UpdatedAt); !errors.Is(err, domain.ErrPreconditionFailed) {
+			t.Errorf("expected ErrPreconditionFailed for a stale delete, got %v", err)
+		}
+
+		got, err := repo.GetTransactionByID(ctx, "", read.ID)
+		if err != nil {
+			t.Fatalf("GetTransactionByID: %v", err)
+		}
+		if got.Description != "first device" || !got.UpdatedAt.Equal(first.UpdatedAt) {
+			t.Errorf("expected the first update to win, got %+v", got)
+		}
+		if err := repo.DeleteTransaction(ctx, "", read.ID, got.UpdatedAt); err != nil {
+			t.Errorf("DeleteTransaction with the current version: %v", err)
+		}
+	})
+
+	t.Run("updating a missing transaction fails", func(t *testing.T) {
+		repo := newRepo(t)
+		missing := sample(10, base, "missing")
+		missing.ID = 999999
+		if err := repo.UpdateTransaction(ctx, &missing); err == nil {
+			t.Error("expected error updating a missing transaction")
+		}
+	})
+
+	t.Run("deletes a transaction", func(t *testing.T) {
+		repo := newRepo(t)
+		stored := saveAll(t, repo, sample(50, base, "Groceries"))
+
+		if err := repo.DeleteTransaction(ctx, "", stored[0].ID, time.Time{}); err != nil {
+			t.Fatalf("DeleteTransaction: %v", err)
+		}
+
+		got, err := repo.GetTransactionByID(ctx, "", stored[0].ID)
+		if err != nil {
+			t.Fatalf("GetTransactionByID: %v", err)
+		}
+		if got != nil {
+			t.Errorf("expected transaction to be deleted, got %+v", got)
+		}
+
+		if err := repo.DeleteTransaction(ctx, "", stored[0].ID, time.Time{}); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound deleting a missing transaction, got %v", err)
+		}
+	})
+
+	t.Run("trashes, restores and purges transactions", func(t *testing.T) {
+		repo := newRepo(t)
+		groceries, snacks := sample(50, base, "Groceries"), sample(20, base.Add(time.Hour), "Snacks")
+		groceries.UserID, snacks.UserID = "user-1", "user-1"
+		if err := testutil.SaveTransactions(ctx, repo, []domain.Transaction{groceries, snacks}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		stored, err := repo.GetTransactions(ctx, "user-1", 100, 0)
+		if err != nil || len(stored) != 2 {
+			t.Fatalf("expected the user's 2 transactions, got %+v, %v", stored, err)
+		}
+		trashed := stored[0]
+
+		if err := repo.DeleteTransaction(ctx, "user-1", trashed.ID, trashed.UpdatedAt); err != nil {
+			t.Fatalf("DeleteTransaction: %v", err)
+		}
+
+		if live, _ := repo.GetTransactions(ctx, "user-1", 100, 0); len(live) != 1 || live[0].ID == trashed.ID {
+			t.Errorf("expected the trashed transaction to be hidden from GetTransactions, got %+v", live)
+		}
+		if found, _ := repo.FindTransactions(ctx, domain.TransactionFilter{DescriptionContains: trashed.Description}); len(found) != 0 {
+			t.Errorf("expected the trashed transaction to be hidden from FindTransactions, got %+v", found)
+		}
+		update := trashed
+		update.UserID, update.UpdatedAt = "user-1", time.Time{}
+		if err := repo.UpdateTransaction(ctx, &update); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound updating a trashed transaction, got %v", err)
+		}
+
+		trash, err := repo.GetDeletedTransactions(ctx, "user-1", 10, 0)
+		if err != nil {
+			t.Fatalf("GetDeletedTransactions: %v", err)
+		}
+		if len(trash) != 1 || trash[0].ID != trashed.ID || trash[0].DeletedAt == nil {
+			t.Fatalf("expected the trashed transaction with its deletion time, got %+v", trash)
+		}
+
+		if theirs, _ := repo.GetDeletedTransactions(ctx, "user-2", 10, 0); len(theirs) != 0 {
+			t.Errorf("expected another user's trash to be empty, got %+v", theirs)
+		}
+		if _, err := repo.RestoreTransaction(ctx, "user-2", trashed.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound restoring another user's transaction, got %v", err)
+		}
+
+		restored, err := repo.RestoreTransaction(ctx, "user-1", trashed.ID)
+		if err != nil {
+			t.Fatalf("RestoreTransaction: %v", err)
+		}
+		if restored.DeletedAt != nil || restored.Description != trashed.Description || !restored.UpdatedAt.After(trash[0].UpdatedAt) {
+			t.Errorf("expected the restored transaction with a new version, got %+v", restored)
+		}
+		if got, _ := repo.GetTransactionByID(ctx, "user-1", trashed.ID); got == nil {
+			t.Error("expected the restored transaction to be readable again")
+		}
+		if _, err := repo.RestoreTransaction(ctx, "user-1", trashed.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound restoring a live transaction, got %v", err)
+		}
+
+		if err := repo.DeleteTransaction(ctx, "user-1", trashed.ID, time.Time{}); err != nil {
+			t.Fatalf("DeleteTransaction: %v", err)
+		}
+		if purged, err := repo.PurgeDeletedTransactions(ctx, time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+			t.Errorf("expected nothing trashed an hour ago to be purged, got %d, %v", purged, err)
+		}
+		if purged, err := repo.PurgeDeletedTransactions(ctx, time.Now().Add(time.Second)); err != nil || purged != 1 {
+			t.Errorf("expected the trashed transaction to be purged, got %d, %v", purged, err)
+		}
+		if trash, _ := repo.GetDeletedTransactions(ctx, "user-1", 10, 0); len(trash) != 0 {
+			t.Errorf("expected an empty trash, got %+v", trash)
+		}
+		if _, err := repo.RestoreTransaction(ctx, "user-1", trashed.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound restoring a purged transaction, got %v", err)
+		}
+	})
+
+	t.Run("finds transactions matching a filter", func(t *testing.T) {
+		repo := newRepo(t)
+		bus := sample(12, base.Add(-48*time.Hour), "City bus")
+		bus.Category = domain.CategoryTransport
+		saveAll(t, repo, sample(50, base, "Groceries at WALMART"), sample(20, base.Add(-24*time.Hour), "Walmart snacks"), bus)
+
+		from := base.Add(-30 * time.Hour)
+		found, err := repo.FindTransactions(ctx, domain.TransactionFilter{Category: domain.CategoryFood, DescriptionContains: "walmart", From: &from})
+		if err != nil {
+			t.Fatalf("FindTransactions: %v", err)
+		}
+		if len(found) != 2 || found[0].Description != "Groceries at WALMART" || found[1].Description != "Walmart snacks" {
+			t.Errorf("expected both Walmart transactions ordered by ID, got %+v", found)
+		}
+
+		to := base.Add(-30 * time.Hour)
+		found, err = repo.FindTransactions(ctx, domain.TransactionFilter{To: &to})
+		if err != nil {
+			t.Fatalf("FindTransactions: %v", err)
+		}
+		if len(found) != 1 || found[0].Description != "City bus" {
+			t.Errorf("expected only the bus ride, got %+v", found)
+		}
+
+		owned := sample(30, base, "Owned")
+		owned.UserID = "user-1"
+		saveAll(t, repo, owned)
+		found, err = repo.FindTransactions(ctx, domain.TransactionFilter{UserID: "user-1"})
+		if err != nil {
+			t.Fatalf("FindTransactions: %v", err)
+		}
+		if len(found) != 1 || found[0].Description != "Owned" {
+			t.Errorf("expected only the user's transaction, got %+v", found)
+		}
+		found, err = repo.FindTransactions(ctx, domain.TransactionFilter{DescriptionContains: "Owned"})
+		if err != nil {
+			t.Fatalf("FindTransactions: %v", err)
+		}
+		if len(found) != 0 {
+			t.Errorf("expected a filter without an owner not to match the user's transaction, got %+v", found)
+		}
+	})
+
+	t.Run("finds duplicate candidates of a user", func(t *testing.T) {
+		repo := newRepo(t)
+		owned := func(amount float64, date time.Time, description string) domain.Transaction {
+			transaction := sample(amount, date, description)
+			transaction.UserID = "user-1"
+			return transaction
+		}
+		income := owned(45, base, "Refund")
+		income.Type = domain.Income
+		dollars := owned(45, base, "Coffee in dollars")
+		dollars.Currency = "USD"
+		theirs := sample(45, base, "Their coffee")
+		theirs.UserID = "user-2"
+		saveAll(t, repo,
+			owned(45, base.Add(time.Hour), "Coffee again"),
+			owned(45.001, base, "Coffee"),
+			owned(45, base.Add(-72*time.Hour), "Old coffee"),
+			owned(80, base, "Lunch"),
+			owned(20, base, "Trashed snack"),
+			owned(20, base, "Snack"),
+			income, dollars, theirs)
+		stored, _ := repo.FindTransactions(ctx, domain.TransactionFilter{DescriptionContains: "Trashed snack", UserID: "user-1"})
+		if err := repo.DeleteTransaction(ctx, "user-1", stored[0].ID, time.Time{}); err != nil {
+			t.Fatalf("DeleteTransaction: %v", err)
+		}
+
+		candidates, err := repo.FindDuplicateCandidates(ctx, "user-1", 48*time.Hour)
+		if err != nil {
+			t.Fatalf("FindDuplicateCandidates: %v", err)
+		}
+		if len(candidates) != 2 || candidates[0].Description != "Coffee" || candidates[1].Description != "Coffee again" {
+			t.Errorf("expected the two coffees ordered by date, got %+v", candidates)
+		}
+		if candidates, _ := repo.FindDuplicateCandidates(ctx, "user-2", 48*time.Hour); len(candidates) != 0 {
+			t.Errorf("expected no candidates for a single transaction, got %+v", candidates)
+		}
+	})
+
+	t.Run("applies writes atomically", func(t *testing.T) {
+		repo := newRepo(t)
+		stored := saveAll(t, repo, sample(50, base, "Groceries"), sample(20, base, "Snacks"))
+
+		updated := stored[0]
+		updated.Amount = 55
+		writes := []domain.TransactionWrite{
+			{Action: domain.BulkActionCreate, Transaction: sample(12, base, "Bus")},
+			{Action: domain.BulkActionUpdate, Transaction: updated},
+			{Action: domain.BulkActionDelete, Transaction: stored[1]},
+		}
+		if err := repo.ApplyTransactionWrites(ctx, writes); err != nil {
+			t.Fatalf("ApplyTransactionWrites: %v", err)
+		}
+		if writes[0].Transaction.ID == 0 || writes[0].Transaction.UpdatedAt.IsZero() {
+			t.Errorf("expected the created transaction to get an ID and version, got %+v", writes[0].Transaction)
+		}
+		if writes[1].Transaction.UpdatedAt.Equal(stored[0].UpdatedAt) {
+			t.Error("expected the update to change UpdatedAt")
+		}
+
+		after := saveAll(t, repo)
+		if len(after) != 2 {
+			t.Fatalf("expected 2 transactions after the batch, got %+v", after)
+		}
+
+		failing := []domain.TransactionWrite{
+			{Action: domain.BulkActionCreate, Transaction: sample(1, base, "Never saved")},
+			{Action: domain.BulkActionDelete, Transaction: writes[0].Transaction},
+			{Action: domain.BulkActionUpdate, Transaction: updated},
+		}
+		err := repo.ApplyTransactionWrites(ctx, failing)
+		var batchErr *domain.BatchError
+		if !errors.As(err, &batchErr) || batchErr.Index != 2 || !errors.Is(err, domain.ErrPreconditionFailed) {
+			t.Fatalf("expected a stale update to fail write 2, got %v", err)
+		}
+
+		if got := saveAll(t, repo); len(got) != 2 {
+			t.Errorf("expected a failed batch to change nothing, got %+v", got)
+		}
+		if got, _ := repo.GetTransactionByID(ctx, "", writes[0].Transaction.ID); got == nil {
+			t.Error("expected the delete of a failed batch to be rolled back")
+		}
+		if history, _ := repo.GetTransactionHistory(ctx, "", writes[0].Transaction.ID); len(history) != 1 {
+			t.Errorf("expected a failed batch to record no events, got %+v", history)
+		}
+	})
+
+	t.Run("writes only change the transactions of their user", func(t *testing.T) {
+		repo := newRepo(t)
+		owned := []domain.Transaction{sample(50, base, "Groceries"), sample(20, base, "Snacks")}
+		owned[0].UserID, owned[1].UserID = "user-1", "user-1"
+		if err := testutil.SaveTransactions(ctx, repo, owned); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if err := repo.DeleteTransaction(ctx, "user-1", owned[1].ID, time.Time{}); err != nil {
+			t.Fatalf("DeleteTransaction: %v", err)
+		}
+
+		intruder := owned[0]
+		intruder.UserID = "user-2"
+		intruder.Amount = 1
+		trashed := domain.Transaction{ID: owned[1].ID, UserID: "user-2"}
+		for _, write := range []domain.TransactionWrite{
+			{Action: domain.BulkActionUpdate, Transaction: intruder},
+			{Action: domain.BulkActionDelete, Transaction: intruder},
+			{Action: domain.BulkActionRestore, Transaction: trashed},
+		} {
+			if err := repo.ApplyTransactionWrites(ctx, []domain.TransactionWrite{write}); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("expected ErrNotFound for a %s of another user's transaction, got %v", write.Action, err)
+			}
+		}
+		if err := repo.UpdateTransaction(ctx, &intruder); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound updating another user's transaction, got %v", err)
+		}
+		if err := repo.DeleteTransaction(ctx, "user-2", owned[0].ID, time.Time{}); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound deleting another user's transaction, got %v", err)
+		}
+
+		if got, _ := repo.GetTransactionByID(ctx, "user-1", owned[0].ID); got == nil || got.Amount != 50 || !got.UpdatedAt.Equal(owned[0].UpdatedAt) {
+			t.Errorf("expected the user's transaction to be unchanged, got %+v", got)
+		}
+		if trash, _ := repo.GetDeletedTransactions(ctx, "user-1", 10, 0); len(trash) != 1 {
+			t.Errorf("expected the user's trashed transaction to stay in the trash, got %+v", trash)
+		}
+	})
+
+	t.Run("records the history of every change", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.WithValue(ctx, domain.UserIDKey, "user-1")
+		ctx = context.WithValue(ctx, domain.RequestIDKey, "request-1")
+
+		owned := []domain.Transaction{sample(50, base, "Groceries")}
+		owned[0].UserID = "user-1"
+		if err := testutil.SaveTransactions(ctx, repo, owned); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		stored := owned[0]
+
+		updated := stored
+		updated.Amount = 75
+		if err := repo.UpdateTransaction(domain.WithTransactionSource(ctx, domain.SourceParse), &updated); err != nil {
+			t.Fatalf("UpdateTransaction: %v", err)
+		}
+		stale := stored
+		if err := repo.UpdateTransaction(ctx, &stale); !errors.Is(err, domain.ErrPreconditionFailed) {
+			t.Fatalf("expected a stale update to fail, got %v", err)
+		}
+		if err := repo.ApplyTransactionWrites(ctx, []domain.TransactionWrite{{Action: domain.BulkActionDelete, Transaction: updated}}); err != nil {
+			t.Fatalf("ApplyTransactionWrites: %v", err)
+		}
+		if _, err := repo.RestoreTransaction(context.Background(), "user-1", stored.ID); err != nil {
+			t.Fatalf("RestoreTransaction: %v", err)
+		}
+
+		history, err := repo.GetTransactionHistory(ctx, "user-1", stored.ID)
+		if err != nil {
+			t.Fatalf("GetTransactionHistory: %v", err)
+		}
+		actions := []domain.AuditAction{domain.AuditActionCreate, domain.AuditActionUpdate, domain.AuditActionDelete, domain.AuditActionRestore}
+		if len(history) != len(actions) {
+			t.Fatalf("expected %d events, got %+v", len(actions), history)
+		}
+		for i, event := range history {
+			if event.Action != actions[i] || event.TransactionID != stored.ID || event.UserID != "user-1" || event.CreatedAt.IsZero() {
+				t.Errorf("unexpected event %d: %+v", i, event)
+			}
+			if i > 0 && event.ID <= history[i-1].ID {
+				t.Errorf("expected events in order, got IDs %d then %d", history[i-1].ID, event.ID)
+			}
+		}
+
+		created, update, deleted, restored := history[0], history[1], history[2], history[3]
+		if created.Before != nil || created.After == nil || created.After.Amount != 50 ||
+			created.ActorID != "user-1" || created.Source != domain.SourceManual || created.RequestID != "request-1" {
+			t.Errorf("unexpected create event: %+v", created)
+		}
+		if update.Before == nil || update.Before.Amount != 50 || update.After == nil || update.After.Amount != 75 || update.Source != domain.SourceParse {
+			t.Errorf("unexpected update event: %+v", update)
+		}
+		if deleted.Before == nil || deleted.Before.DeletedAt != nil || deleted.After == nil || deleted.After.DeletedAt == nil {
+			t.Errorf("unexpected delete event: %+v", deleted)
+		}
+		if restored.Before == nil || restored.Before.DeletedAt == nil || restored.After == nil || restored.After.DeletedAt != nil ||
+			restored.ActorID != "" || restored.RequestID != "" {
+			t.Errorf("unexpected restore event: %+v", restored)
+		}
+
+		if err := repo.DeleteTransaction(ctx, "user-1", stored.ID, time.Time{}); err != nil {
+			t.Fatalf("DeleteTransaction: %v", err)
+		}
+		if _, err := repo.PurgeDeletedTransactions(ctx, time.Now().Add(time.Second)); err != nil {
+			t.Fatalf("PurgeDeletedTransactions: %v", err)
+		}
+		if history, _ := repo.GetTransactionHistory(ctx, "user-1", stored.ID); len(history) != 5 {
+			t.Errorf("expected the history to outlive the purged transaction, got %d events", len(history))
+		}
+		if _, err := repo.GetTransactionHistory(ctx, "user-2", stored.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound reading another user's history, got %v", err)
+		}
+		if _, err := repo.GetTransactionHistory(ctx, "user-1", stored.ID+1); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing transaction, got %v", err)
+		}
+	})
+
+	t.Run("groups the changes of an operation", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := domain.WithOperation(context.WithValue(ctx, domain.UserIDKey, "user-1"), "op-1")
+
+		if err := testutil.SaveTransactions(ctx, repo, []domain.Transaction{sample(10, base, "Food"), sample(20, base, "Transportation")}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		stored := saveAll(t, repo)
+		updated := stored[0]
+		updated.Amount = 15
+		if err := repo.UpdateTransaction(ctx, &updated); err != nil {
+			t.Fatalf("UpdateTransaction: %v", err)
+		}
+		if err := repo.UpdateTransaction(context.Background(), &updated); err != nil {
+			t.Fatalf("UpdateTransaction outside the operation: %v", err)
+		}
+
+		operation, err := repo.GetOperation(ctx, "op-1")
+		if err != nil {
+			t.Fatalf("GetOperation: %v", err)
+		}
+		if operation == nil || operation.ID != "op-1" || len(operation.Events) != 3 || operation.UndoneBy != "" {
+			t.Fatalf("unexpected operation: %+v", operation)
+		}
+		for i, event := range operation.Events {
+			if event.OperationID != "op-1" || event.RevertsOperationID != "" {
+				t.Errorf("unexpected event %d: %+v", i, event)
+			}
+		}
+
+		// Restoring a live transaction fails the batch
+		undo := domain.WithRevertedOperation(domain.WithOperation(ctx, "op-2"), "op-1")
+		err = repo.ApplyTransactionWrites(undo, []domain.TransactionWrite{{Action: domain.BulkActionRestore, Transaction: stored[1]}})
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("expected restoring a live transaction to fail, got %v", err)
+		}
+
+		writes := []domain.TransactionWrite{
+			{Action: domain.BulkActionDelete, Transaction: domain.Transaction{ID: stored[1].ID}},
+			{Action: domain.BulkActionRestore, Transaction: domain.Transaction{ID: stored[1].ID}},
+		}
+		if err := repo.ApplyTransactionWrites(undo, writes); err != nil {
+			t.Fatalf("ApplyTransactionWrites: %v", err)
+		}
+		if restored := writes[1].Transaction; restored.DeletedAt != nil || restored.Amount != stored[1].Amount {
+			t.Errorf("expected the restore write to return the live transaction, got %+v", restored)
+		}
+
+		operation, err = repo.GetOperation(ctx, "op-1")
+		if err != nil {
+			t.Fatalf("GetOperation: %v", err)
+		}
+		if operation == nil || len(operation.Events) != 3 || operation.UndoneBy != "op-2" {
+			t.Errorf("expected op-1 to be undone by op-2, got %+v", operation)
+		}
+		reverting, err := repo.GetOperation(ctx, "op-2")
+		if err != nil {
+			t.Fatalf("GetOperation: %v", err)
+		}
+		if reverting == nil || len(reverting.Events) != 2 || reverting.Events[0].RevertsOperationID != "op-1" ||
+			reverting.Events[1].Action != domain.AuditActionRestore {
+			t.Errorf("unexpected undo operation: %+v", reverting)
+		}
+
+		if missing, err := repo.GetOperation(ctx, "missing"); err != nil || missing != nil {
+			t.Errorf("expected no operation, got %+v, %v", missing, err)
+		}
+	})
+}
+
+func TestMemoryTransactionRepository(t *testing.T) {
+	testTransactionRepositoryContract(t, func(t *testing.T) domain.TransactionRepository {
+		return NewMemoryTransactionRepository()
+	})
+}
+
+func TestSQLiteTransactionRepository(t *testing.T) {
+	testTransactionRepositoryContract(t, func(t *testing.T) domain.TransactionRepository {
+		return NewSQLiteTransactionRepository(newSQLiteTestDB(t))
+	})
+}
+
+// newSQLiteTestDB opens a fully migrated SQLite database in a temporary directory
+func newSQLiteTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := NewSQLiteConnection(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteConnection: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := NewSQLiteMigrator(db, migrations.SQLiteFS)
+	if err != nil {
+		t.Fatalf("NewSQLiteMigrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return db
+}
+
+// TestPostgreSQLTransactionRepository runs against the database in TEST_DATABASE_URL.
+// The transactions and transaction_events tables are truncated before every subtest.
+func TestPostgreSQLTransactionRepository(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := NewPostgreSQLMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("NewPostgreSQLMigrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	testTransactionRepositoryContract(t, func(t *testing.T) domain.TransactionRepository {
+		if _, err := db.Exec(ctx, `TRUNCATE transactions, transaction_events RESTART IDENTITY`); err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		return NewPostgreSQLTransactionRepository(db)
+	})
+}
@@ -0,0 +1,286 @@
+// Package openapi builds OpenAPI 3.1 documents, deriving JSON schemas from Go types
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version of the documents built by this package
+const Version = "3.1.0"
+
+// MergePatchContentType is the media type of JSON Merge Patch documents
+const MergePatchContentType = "application/merge-patch+json"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+
+	// enums holds the allowed values of named types, registered with Enum
+	enums map[reflect.Type][]any
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components holds the schemas, responses and security schemes referenced by operations
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	Responses       map[string]*Response       `json:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes a way of authenticating requests
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// Operation describes a single method on a path
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter describes a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// MediaType describes a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Response describes a response, or references one from the components when Ref is set
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header describes a response header
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// New creates an empty document
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			Responses:       make(map[string]*Response),
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+		enums: make(map[reflect.Type][]any),
+	}
+}
+
+// ginParam matches the :name and *name parameters of Gin route patterns
+var ginParam = regexp.MustCompile(`[:*](\w+)`)
+
+// PathFromGin converts a Gin route pattern such as /rules/:id to an OpenAPI path (/rules/{id})
+func PathFromGin(path string) string {
+	return ginParam.ReplaceAllString(path, "{$1}")
+}
+
+// Route adds an operation for a Gin route pattern and returns a builder to describe it.
+// Path parameters are declared as required strings until described with PathParam.
+func (d *Document) Route(method, ginPath string) *Route {
+	path := PathFromGin(ginPath)
+	op := &Operation{Responses: make(map[string]*Response)}
+	for _, match := range ginParam.FindAllStringSubmatch(ginPath, -1) {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   String(),
+		})
+	}
+
+	if d.Paths[path] == nil {
+		d.Paths[path] = make(map[string]*Operation)
+	}
+	d.Paths[path][strings.ToLower(method)] = op
+
+	return &Route{doc: d, op: op}
+}
+
+// Operation returns the operation for a Gin route pattern, or nil if it is not documented
+func (d *Document) Operation(method, ginPath string) *Operation {
+	return d.Paths[PathFromGin(ginPath)][strings.ToLower(method)]
+}
+
+// Route describes an operation added with Document.Route
+type Route struct {
+	doc *Document
+	op  *Operation
+}
+
+// Operation returns the operation being described
+func (r *Route) Operation() *Operation {
+	return r.op
+}
+
+// Summary sets the one-line summary of the operation
+func (r *Route) Summary(summary string) *Route {
+	r.op.Summary = summary
+	return r
+}
+
+// Description adds a paragraph to the description of the operation
+func (r *Route) Description(description string) *Route {
+	if r.op.Description != "" {
+		description = r.op.Description + "\n\n" + description
+	}
+	r.op.Description = description
+	return r
+}
+
+// Tags groups the operation under tags
+func (r *Route) Tags(tags ...string) *Route {
+	r.op.Tags = append(r.op.Tags, tags...)
+	return r
+}
+
+// Security requires one of the named security schemes
+func (r *Route) Security(schemes ...string) *Route {
+	for _, scheme := range schemes {
+		r.op.Security = append(r.op.Security, map[string][]string{scheme: {}})
+	}
+	return r
+}
+
+// PathParam describes a path parameter
+func (r *Route) PathParam(name string, schema *Schema, description string) *Route {
+	for _, param := range r.op.Parameters {
+		if param.In == "path" && param.Name == name {
+			param.Schema = schema
+			param.Description = description
+			return r
+		}
+	}
+	return r.param(&Parameter{Name: name, In: "path", Required: true, Schema: schema, Description: description})
+}
+
+// Query describes an optional query parameter
+func (r *Route) Query(name string, schema *Schema, description string) *Route {
+	return r.param(&Parameter{Name: name, In: "query", Schema: schema, Description: description})
+}
+
+// Header describes an optional request header
+func (r *Route) Header(name string, schema *Schema, description string) *Route {
+	return r.param(&Parameter{Name: name, In: "header", Schema: schema, Description: description})
+}
+
+// RequiredHeader describes a required request header
+func (r *Route) RequiredHeader(name string, schema *Schema, description string) *Route {
+	return r.param(&Parameter{Name: name, In: "header", Required: true, Schema: schema, Description: description})
+}
+
+func (r *Route) param(param *Parameter) *Route {
+	r.op.Parameters = append(r.op.Parameters, param)
+	return r
+}
+
+// Body describes a required JSON request body shaped like v
+func (r *Route) Body(v any) *Route {
+	r.op.RequestBody = &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{"application/json": {Schema: r.doc.Schema(v)}},
+	}
+	return r
+}
+
+// MergePatchBody describes a required JSON Merge Patch (RFC 7396) request body for the
+// JSON encoding of v, see Document.MergePatchSchema
+func (r *Route) MergePatchBody(v any) *Route {
+	r.op.RequestBody = &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{MergePatchContentType: {Schema: r.doc.MergePatchSchema(v)}},
+	}
+	return r
+}
+
+// Response describes a JSON response shaped like v, or a response without a body when v is nil
+func (r *Route) Response(status int, description string, v any) *Route {
+	response := &Response{Description: description}
+	if v != nil {
+		response.Content = map[string]*MediaType{"application/json": {Schema: r.doc.Schema(v)}}
+	}
+	r.op.Responses[strconv.Itoa(status)] = response
+	return r
+}
+
+// ResponseHeader describes a header of the response already described for status
+func (r *Route) ResponseHeader(status int, name string, schema *Schema, description string) *Route {
+	response := r.op.Responses[strconv.Itoa(status)]
+	if response.Headers == nil {
+		response.Headers = make(map[string]*Header)
+	}
+	response.Headers[name] = &Header{Description: description, Schema: schema}
+	return r
+}
+
+// ResponseContent describes a response in a content type other than JSON
+func (r *Route) ResponseContent(status int, description, contentType string, schema *Schema) *Response {
+	response := &Response{
+		Description: description,
+		Content:     map[string]*MediaType{contentType: {Schema: schema}},
+	}
+	r.op.Responses[strconv.Itoa(status)] = response
+	return response
+}
+
+// ResponseRef uses the named response of the components for status
+func (r *Route) ResponseRef(status int, name string) *Route {
+	r.op.Responses[strconv.Itoa(status)] = &Response{Ref: "#/components/responses/" + name}
+	return r
+}
+
+// ResponseRefs uses the components response named after each status, see StatusName
+func (r *Route) ResponseRefs(statuses ...int) *Route {
+	for _, status := range statuses {
+		r.ResponseRef(status, StatusName(status))
+	}
+	return r
+}
+
+// StatusName names a status for use as a component name, e.g. NotFound for 404
+func StatusName(status int) string {
+	return strings.ReplaceAll(http.StatusText(status), " ", "")
+}
@@ -0,0 +1,339 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12, as used by OpenAPI 3.1)
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+}
+
+// Types is the JSON Schema type keyword, marshalled as a string when it holds a single type
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// String returns a string schema
+func String() *Schema {
+	return &Schema{Type: Types{"string"}}
+}
+
+// Integer returns an integer schema
+func Integer() *Schema {
+	return &Schema{Type: Types{"integer"}}
+}
+
+// WithDefault sets the default value of the schema
+func (s *Schema) WithDefault(value any) *Schema {
+	s.Default = value
+	return s
+}
+
+// WithFormat sets the format of the schema, e.g. date
+func (s *Schema) WithFormat(format string) *Schema {
+	s.Format = format
+	return s
+}
+
+// WithMinimum sets the inclusive minimum of a numeric schema
+func (s *Schema) WithMinimum(minimum float64) *Schema {
+	s.Minimum = &minimum
+	return s
+}
+
+// Enum registers the allowed values of a named type such as a string enum. All values
+// must have the same type; schemas for that type then list them.
+func (d *Document) Enum(values ...any) {
+	if len(values) == 0 {
+		return
+	}
+	d.enums[reflect.TypeOf(values[0])] = values
+}
+
+// Schema returns the schema of v's type. Named structs and enums are added to the
+// components and referenced.
+func (d *Document) Schema(v any) *Schema {
+	return d.schemaFor(reflect.TypeOf(v))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	if t.Kind() == reflect.Pointer {
+		return nullable(d.schemaFor(t.Elem()))
+	}
+
+	if t == timeType {
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	}
+
+	if values, ok := d.enums[t]; ok {
+		return d.component(t, func() *Schema {
+			schema := d.kindSchema(t)
+			schema.Enum = values
+			return schema
+		})
+	}
+
+	if t.Kind() == reflect.Struct && t.Name() != "" {
+		return d.component(t, func() *Schema { return d.structSchema(t) })
+	}
+
+	return d.kindSchema(t)
+}
+
+// MergePatchSchema describes a JSON Merge Patch (RFC 7396) of the JSON encoding of v, a
+// struct: every property is optional, and those v does not require may be null to clear
+// them. Patches of named structs are registered as <Name>Patch components.
+func (d *Document) MergePatchSchema(v any) *Schema {
+	ref := d.Schema(v)
+	target := d.resolve(ref)
+
+	patch := &Schema{Type: target.Type, Properties: make(map[string]*Schema, len(target.Properties))}
+	for name, property := range target.Properties {
+		if slices.Contains(target.Required, name) {
+			patch.Properties[name] = property
+		} else {
+			// Copy before widening, the property is shared with the schema of v
+			optional := *property
+			optional.Type = slices.Clone(property.Type)
+			patch.Properties[name] = nullable(&optional)
+		}
+	}
+
+	if ref.Ref == "" {
+		return patch
+	}
+	name := strings.TrimPrefix(ref.Ref, "#/components/schemas/") + "Patch"
+	d.Components.Schemas[name] = patch
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// component registers the schema of a named type once and returns a reference to it
+func (d *Document) component(t reflect.Type, build func() *Schema) *Schema {
+	name := t.Name()
+	if _, ok := d.Components.Schemas[name]; !ok {
+		// Reserve the name first so recursive types terminate
+		d.Components.Schemas[name] = &Schema{}
+		*d.Components.Schemas[name] = *build()
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// kindSchema returns the schema of an unnamed type, or of a named type's underlying kind
+func (d *Document) kindSchema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.String:
+		return String()
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}, Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: Types{"integer"}, Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: Types{"number"}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: Types{"number"}, Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string"}, Format: "byte"}
+		}
+		return &Schema{Type: Types{"array"}, Items: d.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: d.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return d.structSchema(t)
+	default:
+		// Interfaces and anything else accept any JSON value
+		return &Schema{}
+	}
+}
+
+// structSchema describes the JSON encoding of a struct. Fields are required when their
+// binding tag says so, and other binding rules become schema constraints.
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened, as encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := d.structSchema(field.Type)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := d.schemaFor(field.Type)
+		binding := field.Tag.Get("binding")
+		if applyBinding(property, field.Type, binding) {
+			schema.Required = append(schema.Required, name)
+		} else if kind := field.Type.Kind(); kind == reflect.Map || (kind == reflect.Slice && field.Type.Elem().Kind() != reflect.Uint8) {
+			// encoding/json reads and writes nil slices and maps as null
+			property = nullable(property)
+		}
+		if rule, _, _ := strings.Cut(binding, ","); rule == "omitempty" {
+			property = allowZero(property, field.Type)
+		}
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// applyBinding translates the go-playground validator rules of a binding tag into schema
+// constraints and reports whether the field is required. Rules after dive apply to items.
+func applyBinding(schema *Schema, t reflect.Type, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	required := false
+	target, targetType := schema, t
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = target == schema
+		case "dive":
+			if targetType.Kind() == reflect.Slice && schema.Items != nil && schema.Items.Ref == "" {
+				target, targetType = schema.Items, targetType.Elem()
+			} else {
+				return required
+			}
+		case "oneof":
+			if target.Ref != "" {
+				continue // the referenced enum lists the values
+			}
+			for _, value := range strings.Fields(param) {
+				target.Enum = append(target.Enum, value)
+			}
+		case "len", "min", "max", "gt", "gte", "lt", "lte":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			applyLimit(target, targetType, name, n)
+		}
+	}
+	return required
+}
+
+// applyLimit applies a size rule to a string length, an array length or a number
+func applyLimit(schema *Schema, t reflect.Type, rule string, n float64) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	size := int(n)
+
+	switch t.Kind() {
+	case reflect.String:
+		switch rule {
+		case "len":
+			schema.MinLength, schema.MaxLength = &size, &size
+		case "min", "gte":
+			schema.MinLength = &size
+		case "max", "lte":
+			schema.MaxLength = &size
+		}
+	case reflect.Slice, reflect.Array:
+		switch rule {
+		case "len":
+			schema.MinItems, schema.MaxItems = &size, &size
+		case "min", "gte":
+			schema.MinItems = &size
+		case "max", "lte":
+			schema.MaxItems = &size
+		}
+	default:
+		switch rule {
+		case "len":
+			schema.Minimum, schema.Maximum = &n, &n
+		case "min", "gte":
+			schema.Minimum = &n
+		case "max", "lte":
+			schema.Maximum = &n
+		case "gt":
+			schema.ExclusiveMinimum = &n
+		case "lt":
+			schema.ExclusiveMaximum = &n
+		}
+	}
+}
+
+// allowZero accepts the zero value of a scalar in addition to the values of schema, for
+// fields whose binding rules are skipped when empty
+func allowZero(schema *Schema, t reflect.Type) *Schema {
+	var zero *Schema
+	switch t.Kind() {
+	case reflect.String:
+		zero = &Schema{Type: Types{"string"}, Enum: []any{""}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		zero = &Schema{Type: Types{"number"}, Enum: []any{0}}
+	default:
+		return schema
+	}
+	return &Schema{AnyOf: []*Schema{schema, zero}}
+}
+
+// nullable allows null in addition to the values of schema
+func nullable(schema *Schema) *Schema {
+	if schema.Ref == "" && len(schema.Type) == 0 && schema.AnyOf == nil {
+		return schema // already accepts anything
+	}
+	if schema.Ref != "" || len(schema.Type) == 0 {
+		return &Schema{AnyOf: []*Schema{schema, {Type: Types{"null"}}}}
+	}
+	schema.Type = append(schema.Type, "null")
+	return schema
+}
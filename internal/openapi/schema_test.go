@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testColor string
+
+type testItem struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type testEmbedded struct {
+	ID int `json:"id"`
+}
+
+type testRequest struct {
+	testEmbedded
+	Code     string     `json:"code" binding:"required,len=3"`
+	Amount   float64    `json:"amount" binding:"required,gt=0"`
+	Color    testColor  `json:"color"`
+	Mode     string     `json:"mode" binding:"omitempty,oneof=fast slow"`
+	Items    []testItem `json:"items" binding:"required,min=1,dive"`
+	Tags     []string   `json:"tags" binding:"max=5,dive,min=2"`
+	Enabled  *bool      `json:"enabled"`
+	At       time.Time  `json:"at"`
+	Internal string     `json:"-"`
+	Next     *testRequest
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+	doc.Enum(testColor("red"), testColor("blue"))
+
+	ref := doc.Schema(testRequest{})
+	if ref.Ref != "#/components/schemas/testRequest" {
+		t.Fatalf("expected a component reference, got %+v", ref)
+	}
+
+	schema := doc.Components.Schemas["testRequest"]
+	if want := []string{"code", "amount", "items"}; !reflect.DeepEqual(schema.Required, want) {
+		t.Errorf("expected required %v, got %v", want, schema.Required)
+	}
+
+	properties := schema.Properties
+	for _, name := range []string{"id", "code", "amount", "color", "mode", "items", "tags", "enabled", "at", "Next"} {
+		if properties[name] == nil {
+			t.Errorf("expected property %s", name)
+		}
+	}
+	if properties["Internal"] != nil || properties["-"] != nil {
+		t.Error("expected fields tagged json:\"-\" to be skipped")
+	}
+
+	if code := properties["code"]; *code.MinLength != 3 || *code.MaxLength != 3 {
+		t.Errorf("expected code to have length 3, got %+v", code)
+	}
+	if amount := properties["amount"]; amount.ExclusiveMinimum == nil || *amount.ExclusiveMinimum != 0 {
+		t.Errorf("expected amount to be positive, got %+v", amount)
+	}
+	if mode := properties["mode"]; len(mode.AnyOf) != 2 || !reflect.DeepEqual(mode.AnyOf[0].Enum, []any{"fast", "slow"}) ||
+		!reflect.DeepEqual(mode.AnyOf[1].Enum, []any{""}) {
+		t.Errorf("expected mode enum or the empty string, got %+v", mode)
+	}
+	if items := properties["items"]; *items.MinItems != 1 || items.Items.Ref != "#/components/schemas/testItem" {
+		t.Errorf("expected a non-empty array of items, got %+v", items)
+	}
+	if tags := properties["tags"]; *tags.MaxItems != 5 || *tags.Items.MinLength != 2 {
+		t.Errorf("expected dive rules to apply to tags, got %+v", tags)
+	}
+	if at := properties["at"]; at.Format != "date-time" {
+		t.Errorf("expected a date-time, got %+v", at)
+	}
+
+	color := doc.Components.Schemas["testColor"]
+	if color == nil || !reflect.DeepEqual(color.Enum, []any{testColor("red"), testColor("blue")}) {
+		t.Errorf("expected the color enum component, got %+v", color)
+	}
+
+	enabled, err := json.Marshal(properties["enabled"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(enabled) != `{"type":["boolean","null"]}` {
+		t.Errorf("expected a nullable boolean, got %s", enabled)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+	doc.Route("DELETE", "/users/:userID/items/:id").
+		PathParam("id", Integer(), "Item ID").
+		Response(200, "Deleted", nil)
+
+	op := doc.Operation("DELETE", "/users/:userID/items/:id")
+	if op == nil || doc.Paths["/users/{userID}/items/{id}"]["delete"] != op {
+		t.Fatalf("expected the operation under the OpenAPI path, got %+v", doc.Paths)
+	}
+	if len(op.Parameters) != 2 || op.Parameters[0].Name != "userID" || op.Parameters[1].Schema.Type[0] != "integer" {
+		t.Errorf("unexpected path parameters %+v", op.Parameters)
+	}
+	if op.Responses["200"].Content != nil {
+		t.Error("expected a response without a body")
+	}
+}
+
+func TestMergePatchSchema(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+	doc.Enum(testColor("red"), testColor("blue"))
+
+	ref := doc.MergePatchSchema(testRequest{})
+	if ref.Ref != "#/components/schemas/testRequestPatch" {
+		t.Fatalf("expected a component reference, got %+v", ref)
+	}
+
+	patch := doc.Components.Schemas["testRequestPatch"]
+	if len(patch.Required) != 0 {
+		t.Errorf("expected no required properties, got %v", patch.Required)
+	}
+	if code := patch.Properties["code"]; !reflect.DeepEqual(code.Type, Types{"string"}) {
+		t.Errorf("expected required properties to stay non-null, got %+v", code)
+	}
+	if at := patch.Properties["at"]; !reflect.DeepEqual(at.Type, Types{"string", "null"}) {
+		t.Errorf("expected optional properties to be nullable, got %+v", at)
+	}
+	if at := doc.Components.Schemas["testRequest"].Properties["at"]; !reflect.DeepEqual(at.Type, Types{"string"}) {
+		t.Errorf("expected the schema of the struct to be unchanged, got %+v", at)
+	}
+}
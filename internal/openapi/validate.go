@@ -0,0 +1,268 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError describes why a value at Path does not match its schema. Path is
+// empty for the value itself, e.g. "conditions[0].operator" inside a request body.
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+// Validate checks a decoded JSON value (as produced by encoding/json into an any) against
+// schema, resolving references against the document's components
+func (d *Document) Validate(schema *Schema, value any) []ValidationError {
+	var errs []ValidationError
+	d.validate(schema, value, "", &errs)
+	return errs
+}
+
+// ValidateParameter parses a raw query, path or header value as the parameter's schema
+// type and validates it
+func (d *Document) ValidateParameter(param *Parameter, raw string) []ValidationError {
+	value, err := parseParameter(d.resolve(param.Schema), raw)
+	if err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+	return d.Validate(param.Schema, value)
+}
+
+func (d *Document) validate(schema *Schema, value any, path string, errs *[]ValidationError) {
+	if schema == nil {
+		return
+	}
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if schema.Ref != "" {
+		resolved := d.resolve(schema)
+		if resolved == schema {
+			fail("unknown schema %s", schema.Ref)
+			return
+		}
+		d.validate(resolved, value, path, errs)
+		// Keywords next to $ref apply as well
+		sibling := *schema
+		sibling.Ref = ""
+		schema = &sibling
+	}
+
+	if len(schema.AnyOf) > 0 {
+		matched := false
+		for _, candidate := range schema.AnyOf {
+			if len(d.Validate(candidate, value)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			// Report against the first alternative, the non-null one for nullable values
+			d.validate(schema.AnyOf[0], value, path, errs)
+		}
+	}
+
+	if len(schema.Type) > 0 && !matchesType(schema.Type, value) {
+		fail("must be %s", describeTypes(schema.Type))
+		return
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		values := make([]string, len(schema.Enum))
+		for i, allowed := range schema.Enum {
+			values[i] = fmt.Sprint(allowed)
+		}
+		fail("must be one of: %s", strings.Join(values, ", "))
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			if *schema.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters long", *schema.MinLength)
+			}
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("must be at most %d characters long", *schema.MaxLength)
+		}
+		if message := checkFormat(schema.Format, v); message != "" {
+			fail("%s", message)
+		}
+
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			fail("must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			fail("must be at most %v", *schema.Maximum)
+		}
+		if schema.ExclusiveMinimum != nil && v <= *schema.ExclusiveMinimum {
+			fail("must be greater than %v", *schema.ExclusiveMinimum)
+		}
+		if schema.ExclusiveMaximum != nil && v >= *schema.ExclusiveMaximum {
+			fail("must be less than %v", *schema.ExclusiveMaximum)
+		}
+
+	case []any:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			fail("must contain at least %d item(s)", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			fail("must contain at most %d item(s)", *schema.MaxItems)
+		}
+		for i, item := range v {
+			d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, ValidationError{Path: joinPath(path, name), Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := schema.Properties[name]; ok {
+				d.validate(property, v[name], joinPath(path, name), errs)
+			} else if schema.AdditionalProperties != nil {
+				d.validate(schema.AdditionalProperties, v[name], joinPath(path, name), errs)
+			}
+		}
+	}
+}
+
+// resolve follows a reference to a component schema, returning schema itself when it is
+// not a reference or the component does not exist
+func (d *Document) resolve(schema *Schema) *Schema {
+	if schema == nil || schema.Ref == "" {
+		return schema
+	}
+	if resolved, ok := d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]; ok {
+		return resolved
+	}
+	return schema
+}
+
+// matchesType reports whether a decoded JSON value has one of the types
+func matchesType(types Types, value any) bool {
+	for _, t := range types {
+		switch v := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == math.Trunc(v) && !math.IsInf(v, 0)) {
+				return true
+			}
+		case []any:
+			if t == "array" {
+				return true
+			}
+		case map[string]any:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func describeTypes(types Types) string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		switch t {
+		case "integer", "object", "array":
+			names = append(names, "an "+t)
+		case "null":
+			names = append(names, "null")
+		default:
+			names = append(names, "a "+t)
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+// inEnum compares value with the allowed values as JSON, so named string types match strings
+func inEnum(enum []any, value any) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range enum {
+		if candidate, err := json.Marshal(allowed); err == nil && string(candidate) == string(encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFormat validates the string formats the server relies on; others are annotations
+func checkFormat(format, value string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return "must be an RFC 3339 date-time, e.g. 2024-08-14T15:30:00Z"
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "must be a date in YYYY-MM-DD format"
+		}
+	}
+	return ""
+}
+
+// parseParameter converts a raw parameter value to the JSON value its schema expects
+func parseParameter(schema *Schema, raw string) (any, error) {
+	if schema == nil || len(schema.Type) == 0 {
+		return raw, nil
+	}
+	switch schema.Type[0] {
+	case "integer", "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be %s", describeTypes(schema.Type[:1]))
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return b, nil
+	default:
+		return raw, nil
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+	doc.Enum(testColor("red"), testColor("blue"))
+	schema := doc.Schema(testRequest{})
+
+	tests := []struct {
+		name string
+		body string
+		want map[string]string
+	}{
+		{
+			name: "valid",
+			body: `{"code":"abc","amount":1.5,"color":"red","mode":"","items":[{"name":"a"}],"tags":["ab"],"enabled":null,"at":"2024-08-14T15:30:00Z"}`,
+		},
+		{
+			name: "missing required fields",
+			body: `{}`,
+			want: map[string]string{"code": "is required", "amount": "is required", "items": "is required"},
+		},
+		{
+			name: "invalid values",
+			body: `{"code":"ab","amount":0,"color":"green","mode":"medium","items":[{}],"tags":["a"],"enabled":"yes","at":"today"}`,
+			want: map[string]string{
+				"code":          "must be at least 3 characters long",
+				"amount":        "must be greater than 0",
+				"color":         "must be one of: red, blue",
+				"mode":          "must be one of: fast, slow",
+				"items[0].name": "is required",
+				"tags[0]":       "must be at least 2 characters long",
+				"enabled":       "must be a boolean or null",
+				"at":            "must be an RFC 3339 date-time, e.g. 2024-08-14T15:30:00Z",
+			},
+		},
+		{
+			name: "wrong types",
+			body: `{"code":1,"amount":"1","items":{}}`,
+			want: map[string]string{"code": "must be a string", "amount": "must be a number", "items": "must be an array"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]string)
+			for _, e := range doc.Validate(schema, value) {
+				got[e.Path] = e.Message
+			}
+			if len(got) == 0 {
+				got = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected errors %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateParameter(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+	param := &Parameter{Name: "limit", In: "query", Schema: Integer().WithMinimum(1)}
+
+	if errs := doc.ValidateParameter(param, "10"); len(errs) != 0 {
+		t.Errorf("expected 10 to be valid, got %v", errs)
+	}
+	for raw, want := range map[string]string{
+		"0":    "must be at least 1",
+		"1.5":  "must be an integer",
+		"nope": "must be an integer",
+	} {
+		errs := doc.ValidateParameter(param, raw)
+		if len(errs) != 1 || errs[0].Message != want {
+			t.Errorf("expected %q for %q, got %v", want, raw, errs)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// AdminServiceImpl implements the AdminService interface
+type AdminServiceImpl struct {
+	repo domain.AdminRepository
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(repo domain.AdminRepository) *AdminServiceImpl {
+	return &AdminServiceImpl{
+		repo: repo,
+	}
+}
+
+// GetUsersUsage retrieves a page of per-user usage summaries
+func (s *AdminServiceImpl) GetUsersUsage(ctx context.Context, limit, offset int) ([]domain.UserUsage, error) {
+	return s.repo.GetUsersUsage(ctx, limit, offset)
+}
+
+// PurgeUserData permanently deletes everything stored for the user
+func (s *AdminServiceImpl) PurgeUserData(ctx context.Context, userID string) (*domain.PurgeResult, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, domain.NewValidationError("user ID is required")
+	}
+	return s.repo.PurgeUserData(ctx, userID)
+}
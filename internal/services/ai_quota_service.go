@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// quotaPeriodFormat formats the UTC calendar month a quota counter belongs to
+const quotaPeriodFormat = "2006-01"
+
+// AIQuotaServiceImpl implements the AIQuotaService interface with a monthly token quota per user
+type AIQuotaServiceImpl struct {
+	repo         domain.AIQuotaRepository
+	monthlyLimit int
+	now          func() time.Time
+}
+
+// NewAIQuotaService creates a new AI quota service; a monthlyLimit of 0 disables enforcement
+func NewAIQuotaService(repo domain.AIQuotaRepository, monthlyLimit int) *AIQuotaServiceImpl {
+	return &AIQuotaServiceImpl{
+		repo:         repo,
+		monthlyLimit: monthlyLimit,
+		now:          time.Now,
+	}
+}
+
+// RecordAIUsage adds the tokens of an AI call to its user's monthly counter
+func (s *AIQuotaServiceImpl) RecordAIUsage(ctx context.Context, usage domain.AIUsage) error {
+	if usage.UserID == "" || usage.TotalTokens <= 0 {
+		return nil
+	}
+	return s.repo.AddAITokens(ctx, usage.UserID, s.now().UTC().Format(quotaPeriodFormat), usage.TotalTokens)
+}
+
+// GetQuotaStatus reports the user's token consumption for the current month
+func (s *AIQuotaServiceImpl) GetQuotaStatus(ctx context.Context, userID string) (*domain.AIQuotaStatus, error) {
+	now := s.now().UTC()
+	used, err := s.repo.GetAITokens(ctx, userID, now.Format(quotaPeriodFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AIQuotaStatus{
+		Used:     used,
+		Limit:    s.monthlyLimit,
+		ResetsAt: time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+// CheckQuota returns a *domain.QuotaExceededError when the user has no tokens left this month
+func (s *AIQuotaServiceImpl) CheckQuota(ctx context.Context, userID string) error {
+	if s.monthlyLimit <= 0 {
+		return nil
+	}
+
+	status, err := s.GetQuotaStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if status.Exceeded() {
+		return &domain.QuotaExceededError{Status: *status}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// maxAIUsageErrorLength caps the stored error message of a failed call in bytes, which may echo the model output
+const maxAIUsageErrorLength = 500
+
+// AIUsageServiceImpl implements the AIUsageService interface
+type AIUsageServiceImpl struct {
+	repo   domain.AIUsageRepository
+	quota  domain.AIQuotaService
+	prices map[string]domain.AIModelPrice
+}
+
+// NewAIUsageService creates a new AI usage service. prices is keyed by model name;
+// quota, which may be nil, adds the caller's quota status to per-user reports.
+func NewAIUsageService(repo domain.AIUsageRepository, quota domain.AIQuotaService, prices map[string]domain.AIModelPrice) *AIUsageServiceImpl {
+	return &AIUsageServiceImpl{
+		repo:   repo,
+		quota:  quota,
+		prices: prices,
+	}
+}
+
+// RecordAIUsage saves a single AI call
+func (s *AIUsageServiceImpl) RecordAIUsage(ctx context.Context, usage domain.AIUsage) error {
+	if len(usage.Error) > maxAIUsageErrorLength {
+		// Cut on a rune boundary so the stored message stays valid UTF-8
+		cut := maxAIUsageErrorLength
+		for cut > 0 && !utf8.RuneStart(usage.Error[cut]) {
+			cut--
+		}
+		usage.Error = usage.Error[:cut]
+	}
+	return s.repo.SaveAIUsage(ctx, &usage)
+}
+
+// GetUsageReport summarizes the calls matching filter by day and model, with estimated costs
+func (s *AIUsageServiceImpl) GetUsageReport(ctx context.Context, filter domain.AIUsageFilter) (*domain.AIUsageReport, error) {
+	if !filter.From.Before(filter.To) {
+		return nil, domain.NewValidationError("usage period start must be before its end")
+	}
+
+	days, err := s.repo.GetAIUsageAggregates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.AIUsageReport{
+		From: filter.From,
+		To:   filter.To,
+		Days: make([]domain.AIUsageAggregate, 0, len(days)),
+	}
+
+	var totalLatency float64
+	for _, day := range days {
+		if price, ok := s.priceFor(day.Model); ok {
+			day.EstimatedCost = price.Cost(day.PromptTokens, day.CompletionTokens)
+		}
+		report.Days = append(report.Days, day)
+
+		report.Totals.Calls += day.Calls
+		report.Totals.Failures += day.Failures
+		report.Totals.PromptTokens += day.PromptTokens
+		report.Totals.CompletionTokens += day.CompletionTokens
+		report.Totals.TotalTokens += day.TotalTokens
+		report.Totals.EstimatedCost += day.EstimatedCost
+		totalLatency += day.AvgLatencyMs * float64(day.Calls)
+	}
+	if report.Totals.Calls > 0 {
+		report.Totals.AvgLatencyMs = totalLatency / float64(report.Totals.Calls)
+	}
+
+	if filter.UserID != "" && s.quota != nil {
+		status, err := s.quota.GetQuotaStatus(ctx, filter.UserID)
+		if err != nil {
+			return nil, err
+		}
+		report.Quota = status
+	}
+
+	return report, nil
+}
+
+// priceFor looks up the price of model. The API reports dated snapshots such as
+// "gpt-3.5-turbo-0125", so the longest configured name prefixing the model wins.
+func (s *AIUsageServiceImpl) priceFor(model string) (domain.AIModelPrice, bool) {
+	if price, ok := s.prices[model]; ok {
+		return price, true
+	}
+
+	var best string
+	for name := range s.prices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return domain.AIModelPrice{}, false
+	}
+
+	return s.prices[best], true
+}
+
+// AIUsageRecorders fans AI usage out to several recorders, e.g. quota counters and per-call accounting
+type AIUsageRecorders []domain.AIUsageRecorder
+
+// RecordAIUsage reports usage to every recorder, even when an earlier one fails
+func (r AIUsageRecorders) RecordAIUsage(ctx context.Context, usage domain.AIUsage) error {
+	var errs []error
+	for _, recorder := range r {
+		if err := recorder.RecordAIUsage(ctx, usage); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
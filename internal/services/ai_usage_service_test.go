@@ -0,0 +1,44 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+	"github.com/jairogloz/go-expense-tracker-back/internal/services"
+)
+
+// savedUsageRepository keeps the last AI call it saved
+type savedUsageRepository struct {
+	*infra.MemoryAIUsageRepository
+	last domain.AIUsage
+}
+
+func (r *savedUsageRepository) SaveAIUsage(ctx context.Context, usage *domain.AIUsage) error {
+	r.last = *usage
+	return r.MemoryAIUsageRepository.SaveAIUsage(ctx, usage)
+}
+
+func TestRecordAIUsageTruncatesErrors(t *testing.T) {
+	repo := &savedUsageRepository{MemoryAIUsageRepository: infra.NewMemoryAIUsageRepository()}
+	service := services.NewAIUsageService(repo, nil, nil)
+
+	// A 2-byte rune straddles the 500-byte limit
+	message := "x" + strings.Repeat("é", 300)
+	if err := service.RecordAIUsage(context.Background(), domain.AIUsage{Model: "gpt-4o-mini", Error: message}); err != nil {
+		t.Fatalf("RecordAIUsage: %v", err)
+	}
+	if !utf8.ValidString(repo.last.Error) || len(repo.last.Error) != 499 || !strings.HasPrefix(message, repo.last.Error) {
+		t.Errorf("expected the error cut before the straddling rune, got %d bytes: %q", len(repo.last.Error), repo.last.Error)
+	}
+
+	if err := service.RecordAIUsage(context.Background(), domain.AIUsage{Model: "gpt-4o-mini", Error: "timeout"}); err != nil {
+		t.Fatalf("RecordAIUsage: %v", err)
+	}
+	if repo.last.Error != "timeout" {
+		t.Errorf("expected a short error to be kept, got %q", repo.last.Error)
+	}
+}
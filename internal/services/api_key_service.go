@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+)
+
+const (
+	// apiKeyPrefixBytes and apiKeySecretBytes size the two random parts of a key:
+	// etk_<prefix>_<secret>, both hex-encoded
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+
+	// apiKeyTouchInterval limits how often last-used timestamps are written
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyServiceImpl implements the APIKeyService interface
+type APIKeyServiceImpl struct {
+	repo domain.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(repo domain.APIKeyRepository) *APIKeyServiceImpl {
+	return &APIKeyServiceImpl{
+		repo: repo,
+	}
+}
+
+// CreateAPIKey validates the key, generates its secret and stores its hash
+func (s *APIKeyServiceImpl) CreateAPIKey(ctx context.Context, key *domain.APIKey) (string, error) {
+	if err := key.Validate(); err != nil {
+		return "", domain.NewValidationError("invalid API key: " + err.Error())
+	}
+
+	prefix, err := randomHex(apiKeyPrefixBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	key.Prefix = domain.APIKeyPrefix + prefix
+	fullKey := key.Prefix + "_" + secret
+	key.KeyHash = hashAPIKey(fullKey)
+
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		return "", err
+	}
+
+	return fullKey, nil
+}
+
+// GetAPIKeys retrieves all API keys owned by the user
+func (s *APIKeyServiceImpl) GetAPIKeys(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	return s.repo.GetAPIKeys(ctx, userID)
+}
+
+// DeleteAPIKey revokes an API key owned by the user
+func (s *APIKeyServiceImpl) DeleteAPIKey(ctx context.Context, userID string, id int) error {
+	return s.repo.DeleteAPIKey(ctx, userID, id)
+}
+
+// Authenticate resolves an API key to its owner and scopes, recording when it was used
+func (s *APIKeyServiceImpl) Authenticate(ctx context.Context, fullKey string) (*domain.AuthUser, error) {
+	separator := strings.LastIndex(fullKey, "_")
+	if !strings.HasPrefix(fullKey, domain.APIKeyPrefix) || separator <= len(domain.APIKeyPrefix) {
+		return nil, domain.NewUnauthorizedError("malformed API key", nil)
+	}
+
+	key, err := s.repo.GetAPIKeyByPrefix(ctx, fullKey[:separator])
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(fullKey))) != 1 {
+		return nil, domain.NewUnauthorizedError("invalid API key", nil)
+	}
+
+	now := time.Now().UTC()
+	if key.Expired(now) {
+		return nil, domain.NewUnauthorizedError("API key has expired", nil)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		// Usage tracking is best effort and must not reject a valid key
+		if err := s.repo.TouchAPIKey(ctx, key.ID, now); err != nil {
+			logging.FromContext(ctx).Warn("failed to record API key use", "api_key_id", key.ID, "error", err)
+		}
+	}
+
+	return &domain.AuthUser{
+		ID:       key.UserID,
+		Scopes:   append([]domain.Scope{}, key.Scopes...),
+		APIKeyID: key.ID,
+	}, nil
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 of a full API key
+func hashAPIKey(fullKey string) string {
+	sum := sha256.Sum256([]byte(fullKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes, hex-encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// HealthCheck registers a dependency checker with the health service
+type HealthCheck struct {
+	Checker domain.HealthChecker
+	// Required checks make the service unready when they fail; others only degrade it
+	Required bool
+}
+
+// HealthServiceImpl implements the HealthService interface
+type HealthServiceImpl struct {
+	checks  []HealthCheck
+	timeout time.Duration
+	now     func() time.Time
+}
+
+// NewHealthService creates a new health service running checks concurrently, each bounded by timeout
+func NewHealthService(timeout time.Duration, checks ...HealthCheck) *HealthServiceImpl {
+	return &HealthServiceImpl{
+		checks:  checks,
+		timeout: timeout,
+		now:     time.Now,
+	}
+}
+
+// CheckReadiness runs every check and reports the status of each dependency
+func (s *HealthServiceImpl) CheckReadiness(ctx context.Context) *domain.HealthReport {
+	report := &domain.HealthReport{
+		Status: domain.HealthStatusUp,
+		Checks: make([]domain.HealthCheckResult, len(s.checks)),
+		Time:   s.now().UTC(),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = s.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == domain.HealthStatusUp {
+			continue
+		}
+		if result.Required {
+			report.Status = domain.HealthStatusDown
+			break
+		}
+		report.Status = domain.HealthStatusDegraded
+	}
+
+	return report
+}
+
+// run executes a single check within the timeout
+func (s *HealthServiceImpl) run(ctx context.Context, check HealthCheck) domain.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := s.now()
+	err := check.Checker.Check(ctx)
+
+	result := domain.HealthCheckResult{
+		Name:      check.Checker.Name(),
+		Status:    domain.HealthStatusUp,
+		Required:  check.Required,
+		LatencyMs: float64(s.now().Sub(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = domain.HealthStatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+)
+
+// OperationServiceImpl implements the OperationService interface
+type OperationServiceImpl struct {
+	repo       domain.TransactionRepository
+	undoWindow time.Duration
+}
+
+// NewOperationService creates a new operation service undoing operations made within
+// undoWindow
+func NewOperationService(repo domain.TransactionRepository, undoWindow time.Duration) *OperationServiceImpl {
+	return &OperationServiceImpl{
+		repo:       repo,
+		undoWindow: undoWindow,
+	}
+}
+
+// UndoOperation reverts every change of the operation atomically: created transactions
+// are moved to the trash, and updated or deleted ones are restored to how they were
+// before. It fails with a conflict when the operation was already undone, is too old, or
+// one of its transactions changed since.
+func (s *OperationServiceImpl) UndoOperation(ctx context.Context, id string) (*domain.Operation, error) {
+	operation, err := s.repo.GetOperation(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operation: %w", err)
+	}
+
+	// Users can only undo their own operations
+	userID, _ := domain.UserIDFromContext(ctx)
+	if operation == nil || operation.Events[0].ActorID != userID {
+		return nil, domain.NewNotFoundError("operation", id)
+	}
+
+	if operation.UndoneBy != "" {
+		return nil, domain.NewConflictError(fmt.Sprintf("operation %s was already undone by operation %s", id, operation.UndoneBy))
+	}
+	if time.Since(operation.Events[0].CreatedAt) > s.undoWindow {
+		return nil, domain.NewConflictError(fmt.Sprintf("operation %s is older than %s and can no longer be undone", id, s.undoWindow))
+	}
+
+	undoID := domain.NewOperationID()
+	ctx = domain.WithRevertedOperation(domain.WithOperation(ctx, undoID), id)
+	writes := undoWrites(operation.Events)
+	for i := range writes {
+		writes[i].Transaction.UserID = userID
+	}
+	if err := s.repo.ApplyTransactionWrites(ctx, writes); err != nil {
+		if errors.Is(err, domain.ErrPreconditionFailed) || errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.NewConflictError(fmt.Sprintf("a transaction of operation %s changed since; it can no longer be undone", id))
+		}
+		return nil, fmt.Errorf("failed to undo operation: %w", err)
+	}
+
+	logging.FromContext(ctx).Debug("undid operation", "operation_id", id, "undo_operation_id", undoID)
+
+	undo, err := s.repo.GetOperation(ctx, undoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get undo operation: %w", err)
+	}
+	if undo == nil {
+		// Nothing to revert, e.g. a create and delete of the same transaction
+		return &domain.Operation{ID: undoID, Events: []domain.TransactionEvent{}}, nil
+	}
+
+	return undo, nil
+}
+
+// undoWrites returns the writes bringing every transaction changed by events back to its
+// state before the first of them, last changed transaction first
+func undoWrites(events []domain.TransactionEvent) []domain.TransactionWrite {
+	var ids []int
+	changes := make(map[int][]domain.TransactionEvent)
+	for _, event := range events {
+		if _, ok := changes[event.TransactionID]; !ok {
+			ids = append(ids, event.TransactionID)
+		}
+		changes[event.TransactionID] = append(changes[event.TransactionID], event)
+	}
+
+	var writes []domain.TransactionWrite
+	for i := len(ids) - 1; i >= 0; i-- {
+		changed := changes[ids[i]]
+		first, last := changed[0], changed[len(changed)-1]
+		if last.After == nil {
+			continue
+		}
+		writes = append(writes, revertTransactionWrites(first.Before, *last.After)...)
+	}
+
+	return writes
+}
+
+// revertTransactionWrites returns the writes bringing a transaction from current back to
+// target, where a nil target means it did not exist. The first write is conditional on
+// current, so changes made since the operation are not overwritten.
+func revertTransactionWrites(target *domain.Transaction, current domain.Transaction) []domain.TransactionWrite {
+	var writes []domain.TransactionWrite
+	write := func(action domain.BulkAction, transaction domain.Transaction) {
+		transaction.ID = current.ID
+		transaction.UpdatedAt = time.Time{}
+		if len(writes) == 0 {
+			transaction.UpdatedAt = current.UpdatedAt
+		}
+		writes = append(writes, domain.TransactionWrite{Action: action, Transaction: transaction})
+	}
+
+	trashed := current.DeletedAt != nil
+	if target == nil {
+		if !trashed {
+			write(domain.BulkActionDelete, current)
+		}
+		return writes
+	}
+
+	if !sameTransactionFields(*target, current) {
+		if trashed {
+			write(domain.BulkActionRestore, current)
+			trashed = false
+		}
+		write(domain.BulkActionUpdate, *target)
+	}
+
+	switch {
+	case target.DeletedAt != nil && !trashed:
+		write(domain.BulkActionDelete, current)
+	case target.DeletedAt == nil && trashed:
+		write(domain.BulkActionRestore, current)
+	}
+
+	return writes
+}
+
+// sameTransactionFields reports whether a and b have the same user-editable fields
+func sameTransactionFields(a, b domain.Transaction) bool {
+	return a.Amount == b.Amount &&
+		a.Currency == b.Currency &&
+		a.Category == b.Category &&
+		a.Type == b.Type &&
+		a.Date.Equal(b.Date) &&
+		a.Description == b.Description &&
+		a.Account == b.Account &&
+		slices.Equal(a.Tags, b.Tags)
+}
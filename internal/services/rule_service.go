@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// ruleTestPageSize is the number of transactions fetched per page when dry-running a rule
+const ruleTestPageSize = 100
+
+// RuleServiceImpl implements the RuleService interface
+type RuleServiceImpl struct {
+	repo            domain.RuleRepository
+	transactionRepo domain.TransactionRepository
+}
+
+// NewRuleService creates a new rule service
+func NewRuleService(repo domain.RuleRepository, transactionRepo domain.TransactionRepository) *RuleServiceImpl {
+	return &RuleServiceImpl{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// CreateRule validates and saves a new rule
+func (s *RuleServiceImpl) CreateRule(ctx context.Context, rule *domain.Rule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	return s.repo.CreateRule(ctx, rule)
+}
+
+// GetRuleByID retrieves a rule owned by the user
+func (s *RuleServiceImpl) GetRuleByID(ctx context.Context, userID string, id int) (*domain.Rule, error) {
+	return s.repo.GetRuleByID(ctx, userID, id)
+}
+
+// GetRules retrieves all rules owned by the user in evaluation order
+func (s *RuleServiceImpl) GetRules(ctx context.Context, userID string) ([]domain.Rule, error) {
+	return s.repo.GetRules(ctx, userID)
+}
+
+// UpdateRule validates and updates an existing rule
+func (s *RuleServiceImpl) UpdateRule(ctx context.Context, rule *domain.Rule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	return s.repo.UpdateRule(ctx, rule)
+}
+
+// DeleteRule deletes a rule owned by the user
+func (s *RuleServiceImpl) DeleteRule(ctx context.Context, userID string, id int) error {
+	return s.repo.DeleteRule(ctx, userID, id)
+}
+
+// ApplyRules runs the user's enabled rules, in order, against each transaction
+func (s *RuleServiceImpl) ApplyRules(ctx context.Context, userID string, transactions []domain.Transaction) error {
+	rules, err := s.repo.GetRules(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		for i := range transactions {
+			rule.Apply(&transactions[i])
+		}
+	}
+
+	return nil
+}
+
+// TestRule dry-runs a rule over the transactions of its owner without saving any change
+func (s *RuleServiceImpl) TestRule(ctx context.Context, rule *domain.Rule) (*domain.RuleTestResult, error) {
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	result := &domain.RuleTestResult{
+		Matches: []domain.RuleTestMatch{},
+	}
+
+	for offset := 0; ; offset += ruleTestPageSize {
+		transactions, err := s.transactionRepo.GetTransactions(ctx, rule.UserID, ruleTestPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load transactions: %w", err)
+		}
+
+		for _, transaction := range transactions {
+			after := transaction
+			if rule.Apply(&after) {
+				result.Matches = append(result.Matches, domain.RuleTestMatch{
+					Before: transaction,
+					After:  after,
+				})
+			}
+		}
+
+		result.Scanned += len(transactions)
+		if len(transactions) < ruleTestPageSize {
+			break
+		}
+	}
+
+	result.Matched = len(result.Matches)
+
+	return result, nil
+}
+
+// validateRule reports a malformed rule as a validation error
+func validateRule(rule *domain.Rule) error {
+	if err := rule.Validate(); err != nil {
+		return domain.NewValidationError("invalid rule: " + err.Error())
+	}
+	return nil
+}
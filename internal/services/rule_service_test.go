@@ -0,0 +1,182 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+	"github.com/jairogloz/go-expense-tracker-back/internal/services"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+func newRule(userID string, position int, condition domain.RuleCondition, actions ...domain.RuleAction) *domain.Rule {
+	return &domain.Rule{
+		UserID:     userID,
+		Name:       "rule",
+		Position:   position,
+		Enabled:    true,
+		Conditions: []domain.RuleCondition{condition},
+		Actions:    actions,
+	}
+}
+
+func ride(description string, amount float64) domain.Transaction {
+	return domain.Transaction{
+		Amount:      amount,
+		Currency:    "MXN",
+		Category:    domain.CategoryOther,
+		Type:        domain.Expense,
+		Date:        time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+		Description: description,
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	ctx := context.Background()
+	contains := func(value string) domain.RuleCondition {
+		return domain.RuleCondition{Field: domain.RuleFieldDescription, Operator: domain.OperatorContains, Value: value}
+	}
+	setCategory := func(category domain.Category) domain.RuleAction {
+		return domain.RuleAction{Type: domain.ActionSetCategory, Value: string(category)}
+	}
+
+	t.Run("matches when every condition holds", func(t *testing.T) {
+		service := services.NewRuleService(infra.NewMemoryRuleRepository(), infra.NewMemoryTransactionRepository())
+		rule := newRule("user-1", 0, contains("UBER"), setCategory(domain.CategoryTransport), domain.RuleAction{Type: domain.ActionAddTag, Value: "rides"})
+		rule.Conditions = append(rule.Conditions, domain.RuleCondition{Field: domain.RuleFieldAmount, Operator: domain.OperatorGreaterThan, Value: "50"})
+		if err := service.CreateRule(ctx, rule); err != nil {
+			t.Fatalf("CreateRule: %v", err)
+		}
+
+		transactions := []domain.Transaction{ride("Uber to airport", 85), ride("Uber to work", 40), ride("Lunch", 85)}
+		if err := service.ApplyRules(ctx, "user-1", transactions); err != nil {
+			t.Fatalf("ApplyRules: %v", err)
+		}
+		if transactions[0].Category != domain.CategoryTransport || !slices.Equal(transactions[0].Tags, []string{"rides"}) {
+			t.Errorf("expected the airport ride to be categorized and tagged, got %+v", transactions[0])
+		}
+		for _, unmatched := range transactions[1:] {
+			if unmatched.Category != domain.CategoryOther || len(unmatched.Tags) != 0 {
+				t.Errorf("expected %q to be unchanged, got %+v", unmatched.Description, unmatched)
+			}
+		}
+	})
+
+	t.Run("applies rules in position order", func(t *testing.T) {
+		service := services.NewRuleService(infra.NewMemoryRuleRepository(), infra.NewMemoryTransactionRepository())
+		// Created out of order: position decides, and later rules see earlier changes
+		for _, rule := range []*domain.Rule{
+			newRule("user-1", 2, domain.RuleCondition{Field: domain.RuleFieldCategory, Operator: domain.OperatorEquals, Value: "transport"},
+				domain.RuleAction{Type: domain.ActionSetAccount, Value: "Travel card"}),
+			newRule("user-1", 1, contains("uber eats"), setCategory(domain.CategoryFood)),
+			newRule("user-1", 0, contains("uber"), setCategory(domain.CategoryTransport)),
+		} {
+			if err := service.CreateRule(ctx, rule); err != nil {
+				t.Fatalf("CreateRule: %v", err)
+			}
+		}
+
+		transactions := []domain.Transaction{ride("Uber Eats order", 120), ride("Uber ride", 60)}
+		if err := service.ApplyRules(ctx, "user-1", transactions); err != nil {
+			t.Fatalf("ApplyRules: %v", err)
+		}
+		if transactions[0].Category != domain.CategoryFood || transactions[0].Account != "" {
+			t.Errorf("expected the later rule to win for the order, got %+v", transactions[0])
+		}
+		if transactions[1].Category != domain.CategoryTransport || transactions[1].Account != "Travel card" {
+			t.Errorf("expected the ride to be categorized and then assigned an account, got %+v", transactions[1])
+		}
+	})
+
+	t.Run("skips disabled rules and rules of other users", func(t *testing.T) {
+		service := services.NewRuleService(infra.NewMemoryRuleRepository(), infra.NewMemoryTransactionRepository())
+		disabled := newRule("user-1", 0, contains("uber"), setCategory(domain.CategoryTransport))
+		disabled.Enabled = false
+		for _, rule := range []*domain.Rule{disabled, newRule("user-2", 0, contains("uber"), setCategory(domain.CategoryFood))} {
+			if err := service.CreateRule(ctx, rule); err != nil {
+				t.Fatalf("CreateRule: %v", err)
+			}
+		}
+
+		transactions := []domain.Transaction{ride("Uber ride", 60)}
+		if err := service.ApplyRules(ctx, "user-1", transactions); err != nil {
+			t.Fatalf("ApplyRules: %v", err)
+		}
+		if transactions[0].Category != domain.CategoryOther {
+			t.Errorf("expected no rule to apply, got %+v", transactions[0])
+		}
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		service := services.NewRuleService(infra.NewMemoryRuleRepository(), infra.NewMemoryTransactionRepository())
+		for name, rule := range map[string]*domain.Rule{
+			"unknown category": newRule("user-1", 0, contains("uber"), setCategory("rides")),
+			"amount operator":  newRule("user-1", 0, domain.RuleCondition{Field: domain.RuleFieldAmount, Operator: domain.OperatorContains, Value: "5"}, setCategory(domain.CategoryFood)),
+			"amount value":     newRule("user-1", 0, domain.RuleCondition{Field: domain.RuleFieldAmount, Operator: domain.OperatorEquals, Value: "five"}, setCategory(domain.CategoryFood)),
+			"no actions":       newRule("user-1", 0, contains("uber")),
+		} {
+			if err := service.CreateRule(ctx, rule); !errors.Is(err, domain.ErrValidation) {
+				t.Errorf("%s: expected a validation error, got %v", name, err)
+			}
+		}
+	})
+}
+
+func TestTestRule(t *testing.T) {
+	ctx := context.Background()
+	transactions := infra.NewMemoryTransactionRepository()
+	service := services.NewRuleService(infra.NewMemoryRuleRepository(), transactions)
+
+	mine, theirs := ride("Uber ride", 60), ride("Uber ride", 60)
+	mine.UserID, theirs.UserID = "user-1", "user-2"
+	if err := testutil.SaveTransactions(ctx, transactions, []domain.Transaction{mine, theirs, ride("Lunch", 80)}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	rule := newRule("user-1", 0, domain.RuleCondition{Field: domain.RuleFieldDescription, Operator: domain.OperatorStartsWith, Value: "uber"},
+		domain.RuleAction{Type: domain.ActionSetCategory, Value: string(domain.CategoryTransport)})
+	result, err := service.TestRule(ctx, rule)
+	if err != nil {
+		t.Fatalf("TestRule: %v", err)
+	}
+	if result.Scanned != 1 || result.Matched != 1 || result.Matches[0].Before.Category != domain.CategoryOther ||
+		result.Matches[0].After.Category != domain.CategoryTransport {
+		t.Fatalf("expected only the owner's transaction to be scanned, got %+v", result)
+	}
+
+	stored, err := transactions.GetTransactions(ctx, "user-1", 10, 0)
+	if err != nil {
+		t.Fatalf("GetTransactions: %v", err)
+	}
+	for _, transaction := range stored {
+		if transaction.Category != domain.CategoryOther {
+			t.Errorf("expected the dry run not to save changes, got %+v", transaction)
+		}
+	}
+
+	t.Run("pages through the whole history", func(t *testing.T) {
+		transactions := infra.NewMemoryTransactionRepository()
+		service := services.NewRuleService(infra.NewMemoryRuleRepository(), transactions)
+
+		history := make([]domain.Transaction, 250)
+		for i := range history {
+			history[i] = ride("Uber ride", float64(i+1))
+			history[i].UserID = "user-1"
+		}
+		if err := testutil.SaveTransactions(ctx, transactions, history); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		result, err := service.TestRule(ctx, rule)
+		if err != nil {
+			t.Fatalf("TestRule: %v", err)
+		}
+		if result.Scanned != len(history) || result.Matched != len(history) {
+			t.Errorf("expected all %d transactions to be scanned and matched, got %d and %d", len(history), result.Scanned, result.Matched)
+		}
+	})
+}
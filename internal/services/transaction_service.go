@@ -2,43 +2,478 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
 )
 
+// maxPatchAttempts bounds how often a patch of any version is applied again to a
+// transaction that keeps changing underneath it
+const maxPatchAttempts = 3
+
+// DuplicateDetectionConfig configures how suspected duplicate transactions are detected
+type DuplicateDetectionConfig struct {
+	// DateWindow is the maximum distance between two transaction dates to be considered duplicates
+	DateWindow time.Duration
+	// SimilarityThreshold is the minimum description similarity (0-1) to be considered duplicates
+	SimilarityThreshold float64
+}
+
 // TransactionServiceImpl implements the TransactionService interface
 type TransactionServiceImpl struct {
-	repo domain.TransactionRepository
+	repo        domain.TransactionRepository
+	ruleService domain.RuleService
+	duplicates  DuplicateDetectionConfig
 }
 
 // NewTransactionService creates a new transaction service
-func NewTransactionService(repo domain.TransactionRepository) *TransactionServiceImpl {
+func NewTransactionService(repo domain.TransactionRepository, ruleService domain.RuleService, duplicates DuplicateDetectionConfig) *TransactionServiceImpl {
 	return &TransactionServiceImpl{
-		repo: repo,
+		repo:        repo,
+		ruleService: ruleService,
+		duplicates:  duplicates,
+	}
+}
+
+// SaveTransactions applies the user's auto-categorization rules, checks each transaction
+// against existing ones for suspected duplicates and saves the rest. Suspected duplicates
+// are skipped, merged into the existing transaction or saved anyway depending on policy;
+// an empty policy behaves like DuplicatePolicySkip.
+func (s *TransactionServiceImpl) SaveTransactions(ctx context.Context, transactions []domain.Transaction, policy domain.DuplicatePolicy) (*domain.SaveTransactionsResult, error) {
+	if policy == "" {
+		policy = domain.DuplicatePolicySkip
+	}
+
+	if userID, ok := domain.UserIDFromContext(ctx); ok {
+		for i := range transactions {
+			transactions[i].UserID = userID
+		}
+		if err := s.ruleService.ApplyRules(ctx, userID, transactions); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &domain.SaveTransactionsResult{Saved: make([]domain.Transaction, 0, len(transactions))}
+	// Inserts and merges are applied as one batch, so a failure leaves no partial changes
+	writes := make([]domain.TransactionWrite, 0, len(transactions))
+	var created []int
+	// merges maps each merged duplicate to its write; mergedInto maps the existing transactions
+	// merged so far to theirs, so later duplicates of one fold into the same write
+	merges := make(map[int]int)
+	mergedInto := make(map[int]int)
+
+	for _, transaction := range transactions {
+		match, err := s.findDuplicate(ctx, transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		if match == nil {
+			created = append(created, len(writes))
+			writes = append(writes, domain.TransactionWrite{Action: domain.BulkActionCreate, Transaction: transaction})
+			continue
+		}
+
+		match.Resolution = policy
+		logging.FromContext(ctx).Debug("suspected duplicate transaction",
+			"existing_id", match.Existing.ID,
+			"similarity", match.Similarity,
+			"resolution", policy,
+		)
+		switch policy {
+		case domain.DuplicatePolicyForce:
+			created = append(created, len(writes))
+			writes = append(writes, domain.TransactionWrite{Action: domain.BulkActionCreate, Transaction: transaction})
+		case domain.DuplicatePolicyMerge:
+			if i, ok := mergedInto[match.Existing.ID]; ok {
+				merges[len(result.Duplicates)] = i
+				writes[i].Transaction = mergeTransactions(writes[i].Transaction, transaction)
+				break
+			}
+			// Conditional on the version compared, so a concurrent change is not overwritten
+			merges[len(result.Duplicates)] = len(writes)
+			mergedInto[match.Existing.ID] = len(writes)
+			merged := mergeTransactions(match.Existing, transaction)
+			merged.UserID = transaction.UserID
+			writes = append(writes, domain.TransactionWrite{Action: domain.BulkActionUpdate, Transaction: merged})
+		}
+
+		result.Duplicates = append(result.Duplicates, *match)
 	}
+
+	if err := s.repo.ApplyTransactionWrites(ctx, writes); err != nil {
+		return nil, fmt.Errorf("failed to save transactions: %w", err)
+	}
+	for _, i := range created {
+		result.Saved = append(result.Saved, writes[i].Transaction)
+	}
+	for duplicate, i := range merges {
+		result.Duplicates[duplicate].Existing = writes[i].Transaction
+	}
+
+	logging.FromContext(ctx).Debug("saved transactions", "saved", len(result.Saved), "duplicates", len(result.Duplicates))
+
+	return result, nil
 }
 
-// SaveTransactions saves multiple transactions
-func (s *TransactionServiceImpl) SaveTransactions(ctx context.Context, transactions []domain.Transaction) error {
-	return s.repo.SaveTransactions(ctx, transactions)
+// GetTransactionByID retrieves one of the user's transactions by its ID
+func (s *TransactionServiceImpl) GetTransactionByID(ctx context.Context, userID string, id int) (*domain.Transaction, error) {
+	return s.repo.GetTransactionByID(ctx, userID, id)
 }
 
-// GetTransactionByID retrieves a transaction by its ID
-func (s *TransactionServiceImpl) GetTransactionByID(ctx context.Context, id int) (*domain.Transaction, error) {
-	return s.repo.GetTransactionByID(ctx, id)
+// GetTransactions retrieves the user's transactions with pagination
+func (s *TransactionServiceImpl) GetTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.Transaction, error) {
+	return s.repo.GetTransactions(ctx, userID, limit, offset)
 }
 
-// GetTransactions retrieves transactions with pagination
-func (s *TransactionServiceImpl) GetTransactions(ctx context.Context, limit, offset int) ([]domain.Transaction, error) {
-	return s.repo.GetTransactions(ctx, limit, offset)
+// FindDuplicates groups the user's transactions that appear to be duplicates
+func (s *TransactionServiceImpl) FindDuplicates(ctx context.Context) ([]domain.DuplicateGroup, error) {
+	userID, _ := domain.UserIDFromContext(ctx)
+	transactions, err := s.repo.FindDuplicateCandidates(ctx, userID, s.duplicates.DateWindow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load duplicate candidates: %w", err)
+	}
+
+	groups := []domain.DuplicateGroup{}
+	grouped := make([]bool, len(transactions))
+
+	for i := range transactions {
+		if grouped[i] {
+			continue
+		}
+
+		group := []domain.Transaction{transactions[i]}
+		for j := i + 1; j < len(transactions); j++ {
+			if transactions[j].Date.Sub(transactions[i].Date) > s.duplicates.DateWindow {
+				break
+			}
+			if !grouped[j] && s.similarity(transactions[i], transactions[j]) >= s.duplicates.SimilarityThreshold {
+				group = append(group, transactions[j])
+				grouped[j] = true
+			}
+		}
+
+		if len(group) > 1 {
+			groups = append(groups, domain.DuplicateGroup{Transactions: group})
+		}
+	}
+
+	return groups, nil
 }
 
-// UpdateTransaction updates an existing transaction
+// UpdateTransaction updates an existing transaction of transaction.UserID
 func (s *TransactionServiceImpl) UpdateTransaction(ctx context.Context, transaction *domain.Transaction) error {
 	return s.repo.UpdateTransaction(ctx, transaction)
 }
 
-// DeleteTransaction deletes a transaction by ID
-func (s *TransactionServiceImpl) DeleteTransaction(ctx context.Context, id int) error {
-	return s.repo.DeleteTransaction(ctx, id)
+// PatchTransaction updates a transaction of the user with the result of patch applied
+// to it as stored, if it has not changed since ifUpdatedAt when that is set. With a zero
+// ifUpdatedAt the patch applies to whatever version is current. It is still written
+// conditionally on the version it was applied to, and applied again to the new version
+// if another write lands in between, so that write is not lost.
+func (s *TransactionServiceImpl) PatchTransaction(ctx context.Context, userID string, id int, ifUpdatedAt time.Time, patch func(domain.Transaction) (*domain.Transaction, error)) (*domain.Transaction, error) {
+	anyVersion := ifUpdatedAt.IsZero()
+	for attempt := 1; ; attempt++ {
+		existing, err := s.repo.GetTransactionByID(ctx, userID, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		if existing == nil {
+			return nil, domain.NewNotFoundError("transaction", id)
+		}
+
+		transaction, err := patch(*existing)
+		if err != nil {
+			return nil, err
+		}
+		transaction.ID = id
+		transaction.UserID = userID
+		transaction.UpdatedAt = ifUpdatedAt
+		if anyVersion {
+			transaction.UpdatedAt = existing.UpdatedAt
+		}
+
+		err = s.repo.UpdateTransaction(ctx, transaction)
+		if err == nil {
+			return transaction, nil
+		}
+		if !anyVersion || !errors.Is(err, domain.ErrPreconditionFailed) || attempt == maxPatchAttempts {
+			return nil, err
+		}
+	}
+}
+
+// DeleteTransaction moves one of the user's transactions to the trash, if it has not
+// changed since ifUpdatedAt when that is set
+func (s *TransactionServiceImpl) DeleteTransaction(ctx context.Context, userID string, id int, ifUpdatedAt time.Time) error {
+	return s.repo.DeleteTransaction(ctx, userID, id, ifUpdatedAt)
+}
+
+// CreateTransactions applies the auto-categorization rules of the user in ctx and saves
+// the transactions without duplicate detection, all or none. It returns them with their
+// IDs. Created transactions are owned by the user in ctx.
+func (s *TransactionServiceImpl) CreateTransactions(ctx context.Context, transactions []domain.Transaction) ([]domain.Transaction, error) {
+	writes := make([]domain.TransactionWrite, len(transactions))
+	for i, transaction := range transactions {
+		writes[i] = domain.TransactionWrite{Action: domain.BulkActionCreate, Transaction: transaction}
+	}
+
+	if err := s.applyRulesToCreates(ctx, writes); err != nil {
+		return nil, err
+	}
+	if err := s.applyWrites(ctx, writes, "transactions"); err != nil {
+		return nil, err
+	}
+
+	created := make([]domain.Transaction, len(writes))
+	for i, write := range writes {
+		created[i] = write.Transaction
+	}
+	return created, nil
+}
+
+// ApplyTransactionWrites applies a batch of creates, updates and deletes atomically, on
+// behalf of the user in ctx: created transactions are owned by the user and go through
+// the user's auto-categorization rules, and only the user's transactions can be updated
+// or deleted. When a write fails, none are applied and the error names the failing
+// operation.
+func (s *TransactionServiceImpl) ApplyTransactionWrites(ctx context.Context, writes []domain.TransactionWrite) error {
+	if err := s.applyRulesToCreates(ctx, writes); err != nil {
+		return err
+	}
+	return s.applyWrites(ctx, writes, "operations")
+}
+
+// ApplyToMatching sets the category of, or deletes, every transaction of the user in ctx
+// matching filter atomically and returns the writes applied. Each write is conditional on
+// the version found, so a transaction changed concurrently fails the whole batch.
+func (s *TransactionServiceImpl) ApplyToMatching(ctx context.Context, filter domain.TransactionFilter, action domain.BulkAction, category domain.Category) ([]domain.TransactionWrite, error) {
+	if filter.IsEmpty() {
+		return nil, domain.NewValidationError("the filter must set at least one condition")
+	}
+
+	userID, _ := domain.UserIDFromContext(ctx)
+	if userID == "" {
+		return nil, domain.NewUnauthorizedError("user authentication required", nil)
+	}
+	filter.UserID = userID
+
+	transactions, err := s.repo.FindTransactions(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+	if len(transactions) > domain.MaxBulkOperations {
+		return nil, domain.NewValidationError(fmt.Sprintf("the filter matches %d transactions; at most %d can be changed at once", len(transactions), domain.MaxBulkOperations))
+	}
+
+	writes := make([]domain.TransactionWrite, 0, len(transactions))
+	for _, transaction := range transactions {
+		transaction.UserID = userID
+		switch action {
+		case domain.BulkActionSetCategory:
+			transaction.Category = category
+			writes = append(writes, domain.TransactionWrite{Action: domain.BulkActionUpdate, Transaction: transaction})
+		case domain.BulkActionDelete:
+			writes = append(writes, domain.TransactionWrite{Action: domain.BulkActionDelete, Transaction: transaction})
+		default:
+			return nil, domain.NewValidationError(fmt.Sprintf("unsupported bulk action %q", action))
+		}
+	}
+
+	if err := s.repo.ApplyTransactionWrites(ctx, writes); err != nil {
+		var batchErr *domain.BatchError
+		if errors.As(err, &batchErr) {
+			err = batchErr.Err
+		}
+		return nil, fmt.Errorf("failed to apply bulk action: %w", err)
+	}
+
+	logging.FromContext(ctx).Debug("applied bulk action", "action", action, "transactions", len(writes))
+
+	return writes, nil
+}
+
+// GetDeletedTransactions retrieves the user's transactions in the trash with pagination,
+// most recently deleted first
+func (s *TransactionServiceImpl) GetDeletedTransactions(ctx context.Context, limit, offset int) ([]domain.Transaction, error) {
+	userID, _ := domain.UserIDFromContext(ctx)
+	return s.repo.GetDeletedTransactions(ctx, userID, limit, offset)
+}
+
+// RestoreTransaction moves one of the user's transactions out of the trash and returns it
+func (s *TransactionServiceImpl) RestoreTransaction(ctx context.Context, id int) (*domain.Transaction, error) {
+	userID, _ := domain.UserIDFromContext(ctx)
+	return s.repo.RestoreTransaction(ctx, userID, id)
+}
+
+// PurgeDeletedTransactions permanently deletes the transactions trashed longer than
+// retention ago and returns how many were deleted
+func (s *TransactionServiceImpl) PurgeDeletedTransactions(ctx context.Context, retention time.Duration) (int, error) {
+	purged, err := s.repo.PurgeDeletedTransactions(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	logging.FromContext(ctx).Debug("purged deleted transactions", "purged", purged, "retention", retention)
+
+	return purged, nil
+}
+
+// GetTransactionHistory returns the audit events of one of the user's transactions,
+// oldest first. Transactions saved before the audit log was introduced may have none.
+func (s *TransactionServiceImpl) GetTransactionHistory(ctx context.Context, id int) ([]domain.TransactionEvent, error) {
+	userID, _ := domain.UserIDFromContext(ctx)
+	events, err := s.repo.GetTransactionHistory(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []domain.TransactionEvent{}
+	}
+
+	return events, nil
+}
+
+// findDuplicate returns the most similar existing transaction of the same owner the given one
+// appears to duplicate, if any.
+// Transactions within the same batch are not compared against each other, since a single
+// input may legitimately describe two identical purchases.
+func (s *TransactionServiceImpl) findDuplicate(ctx context.Context, transaction domain.Transaction) (*domain.DuplicateMatch, error) {
+	from := transaction.Date.Add(-s.duplicates.DateWindow)
+	to := transaction.Date.Add(s.duplicates.DateWindow)
+	candidates, err := s.repo.FindTransactions(ctx, domain.TransactionFilter{From: &from, To: &to, UserID: transaction.UserID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load duplicate candidates: %w", err)
+	}
+
+	var best *domain.DuplicateMatch
+	for _, candidate := range candidates {
+		similarity := s.similarity(transaction, candidate)
+		if similarity < s.duplicates.SimilarityThreshold {
+			continue
+		}
+		if best == nil || similarity > best.Similarity {
+			best = &domain.DuplicateMatch{
+				Transaction: transaction,
+				Existing:    candidate,
+				Similarity:  similarity,
+			}
+		}
+	}
+
+	return best, nil
+}
+
+// similarity scores how likely two transactions are duplicates. Transactions with a
+// different type, amount or currency never match.
+func (s *TransactionServiceImpl) similarity(a, b domain.Transaction) float64 {
+	if a.Type != b.Type || !domain.SameAmount(a, b) {
+		return 0
+	}
+	return domain.DescriptionSimilarity(a.Description, b.Description)
+}
+
+// mergeTransactions folds the details of an incoming duplicate into the existing transaction
+// without changing its amount, currency, type or date
+func mergeTransactions(existing, incoming domain.Transaction) domain.Transaction {
+	merged := existing
+
+	if merged.Description == "" {
+		merged.Description = incoming.Description
+	}
+	if merged.Account == "" {
+		merged.Account = incoming.Account
+	}
+	if merged.Category == domain.CategoryOther && incoming.Category != "" {
+		merged.Category = incoming.Category
+	}
+
+	merged.Tags = append([]string(nil), existing.Tags...)
+	for _, tag := range incoming.Tags {
+		found := false
+		for _, existingTag := range merged.Tags {
+			if existingTag == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.Tags = append(merged.Tags, tag)
+		}
+	}
+
+	return merged
+}
+
+// applyRulesToCreates applies the auto-categorization rules of the user in ctx to the
+// transactions created by writes
+func (s *TransactionServiceImpl) applyRulesToCreates(ctx context.Context, writes []domain.TransactionWrite) error {
+	userID, ok := domain.UserIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var created []domain.Transaction
+	for _, write := range writes {
+		if write.Action == domain.BulkActionCreate {
+			created = append(created, write.Transaction)
+		}
+	}
+	if len(created) == 0 {
+		return nil
+	}
+
+	if err := s.ruleService.ApplyRules(ctx, userID, created); err != nil {
+		return err
+	}
+
+	next := 0
+	for i := range writes {
+		if writes[i].Action == domain.BulkActionCreate {
+			writes[i].Transaction = created[next]
+			next++
+		}
+	}
+	return nil
+}
+
+// applyWrites applies writes atomically on behalf of the user in ctx, who owns the created
+// transactions. A failed write is reported as the invalid element of field.
+func (s *TransactionServiceImpl) applyWrites(ctx context.Context, writes []domain.TransactionWrite, field string) error {
+	userID, _ := domain.UserIDFromContext(ctx)
+	for i := range writes {
+		writes[i].Transaction.UserID = userID
+	}
+
+	if err := s.repo.ApplyTransactionWrites(ctx, writes); err != nil {
+		return batchFailure(err, field)
+	}
+
+	logging.FromContext(ctx).Debug("applied transaction writes", "writes", len(writes))
+
+	return nil
+}
+
+// batchFailure reports a failed batch as an error of the kind of the failing write,
+// naming the element of field at fault, e.g. operations[2]
+func batchFailure(err error, field string) error {
+	var batchErr *domain.BatchError
+	var domainErr *domain.Error
+	if !errors.As(err, &batchErr) || !errors.As(batchErr.Err, &domainErr) {
+		return fmt.Errorf("failed to apply transaction writes: %w", err)
+	}
+
+	return &domain.Error{
+		Kind:    domainErr.Kind,
+		Message: "the batch failed; no changes were applied",
+		Err:     err,
+		Fields: []domain.FieldError{{
+			In:      "body",
+			Field:   fmt.Sprintf("%s[%d]", field, batchErr.Index),
+			Message: domainErr.Message,
+		}},
+	}
 }
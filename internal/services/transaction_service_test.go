@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/infra"
+	"github.com/jairogloz/go-expense-tracker-back/internal/services"
+	"github.com/jairogloz/go-expense-tracker-back/internal/testutil"
+)
+
+// failingWritesRepository fails every batch of writes, as a lost database connection would
+type failingWritesRepository struct {
+	*infra.MemoryTransactionRepository
+}
+
+func (r failingWritesRepository) ApplyTransactionWrites(ctx context.Context, writes []domain.TransactionWrite) error {
+	return errors.New("connection reset")
+}
+
+func newTransactionService(repo domain.TransactionRepository) *services.TransactionServiceImpl {
+	return services.NewTransactionService(repo, services.NewRuleService(infra.NewMemoryRuleRepository(), repo), services.DuplicateDetectionConfig{
+		DateWindow:          48 * time.Hour,
+		SimilarityThreshold: 0.8,
+	})
+}
+
+func TestSaveTransactionsMerge(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, "user-1")
+	existing := ride("Uber ride", 60)
+	existing.UserID = "user-1"
+	incoming := ride("Uber ride", 60)
+	incoming.Tags = []string{"work"}
+
+	t.Run("merges duplicates and saves the rest", func(t *testing.T) {
+		repo := infra.NewMemoryTransactionRepository()
+		if err := testutil.SaveTransactions(ctx, repo, []domain.Transaction{existing}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		result, err := newTransactionService(repo).SaveTransactions(ctx, []domain.Transaction{incoming, ride("Lunch", 80)}, domain.DuplicatePolicyMerge)
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if len(result.Saved) != 1 || result.Saved[0].ID == 0 || result.Saved[0].Description != "Lunch" {
+			t.Errorf("expected only the lunch to be saved, got %+v", result.Saved)
+		}
+		if len(result.Duplicates) != 1 || !slices.Equal(result.Duplicates[0].Existing.Tags, []string{"work"}) {
+			t.Errorf("expected the duplicate to report the merged transaction, got %+v", result.Duplicates)
+		}
+	})
+
+	t.Run("folds duplicates of the same transaction into one merge", func(t *testing.T) {
+		repo := infra.NewMemoryTransactionRepository()
+		if err := testutil.SaveTransactions(ctx, repo, []domain.Transaction{existing}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		again := ride("Uber ride", 60)
+		again.Tags = []string{"airport"}
+
+		result, err := newTransactionService(repo).SaveTransactions(ctx, []domain.Transaction{incoming, again}, domain.DuplicatePolicyMerge)
+		if err != nil {
+			t.Fatalf("SaveTransactions: %v", err)
+		}
+		if len(result.Saved) != 0 || len(result.Duplicates) != 2 {
+			t.Fatalf("expected both rides to be reported as duplicates, got %+v", result)
+		}
+		for _, duplicate := range result.Duplicates {
+			if !slices.Equal(duplicate.Existing.Tags, []string{"work", "airport"}) {
+				t.Errorf("expected every duplicate to report the merged transaction, got %+v", duplicate.Existing)
+			}
+		}
+
+		stored, err := repo.GetTransactions(ctx, "user-1", 10, 0)
+		if err != nil {
+			t.Fatalf("GetTransactions: %v", err)
+		}
+		if len(stored) != 1 || !slices.Equal(stored[0].Tags, []string{"work", "airport"}) {
+			t.Errorf("expected both merges to be applied to the existing ride, got %+v", stored)
+		}
+	})
+
+	t.Run("applies nothing when the batch fails", func(t *testing.T) {
+		repo := infra.NewMemoryTransactionRepository()
+		if err := testutil.SaveTransactions(ctx, repo, []domain.Transaction{existing}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		_, err := newTransactionService(failingWritesRepository{repo}).SaveTransactions(ctx, []domain.Transaction{incoming, ride("Lunch", 80)}, domain.DuplicatePolicyMerge)
+		if err == nil {
+			t.Fatal("expected the save to fail")
+		}
+
+		stored, err := repo.GetTransactions(ctx, "user-1", 10, 0)
+		if err != nil {
+			t.Fatalf("GetTransactions: %v", err)
+		}
+		if len(stored) != 1 || len(stored[0].Tags) != 0 {
+			t.Errorf("expected no merge or insert to be applied, got %+v", stored)
+		}
+	})
+}
+
+func TestApplyToMatching(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, "user-1")
+	mine, theirs := ride("Uber ride", 60), ride("Uber ride", 60)
+	mine.UserID, theirs.UserID = "user-1", "user-2"
+
+	t.Run("changes only the caller's transactions", func(t *testing.T) {
+		repo := infra.NewMemoryTransactionRepository()
+		if err := testutil.SaveTransactions(ctx, repo, []domain.Transaction{mine, theirs}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		service := newTransactionService(repo)
+
+		writes, err := service.ApplyToMatching(ctx, domain.TransactionFilter{DescriptionContains: "uber"}, domain.BulkActionSetCategory, domain.CategoryTransport)
+		if err != nil {
+			t.Fatalf("ApplyToMatching: %v", err)
+		}
+		if len(writes) != 1 || writes[0].Transaction.UserID != "user-1" {
+			t.Fatalf("expected only the caller's ride to be changed, got %+v", writes)
+		}
+		if _, err := service.ApplyToMatching(ctx, domain.TransactionFilter{DescriptionContains: "uber"}, domain.BulkActionDelete, ""); err != nil {
+			t.Fatalf("ApplyToMatching: %v", err)
+		}
+
+		if stored, _ := repo.GetTransactions(ctx, "user-1", 10, 0); len(stored) != 0 {
+			t.Errorf("expected the caller's ride to be deleted, got %+v", stored)
+		}
+		stored, err := repo.GetTransactions(ctx, "user-2", 10, 0)
+		if err != nil {
+			t.Fatalf("GetTransactions: %v", err)
+		}
+		if len(stored) != 1 || stored[0].Category != domain.CategoryOther {
+			t.Errorf("expected the other user's ride to be untouched, got %+v", stored)
+		}
+	})
+
+	t.Run("requires a user", func(t *testing.T) {
+		repo := infra.NewMemoryTransactionRepository()
+		_, err := newTransactionService(repo).ApplyToMatching(context.Background(), domain.TransactionFilter{DescriptionContains: "uber"}, domain.BulkActionDelete, "")
+		if !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("expected ErrUnauthorized without a user, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+	"github.com/jairogloz/go-expense-tracker-back/internal/logging"
+)
+
+// TrashPurger permanently deletes transactions that stayed in the trash longer than
+// the retention period
+type TrashPurger struct {
+	transactionService domain.TransactionService
+	retention          time.Duration
+	interval           time.Duration
+}
+
+// NewTrashPurger creates a purger removing transactions trashed more than retention ago,
+// checking every interval
+func NewTrashPurger(transactionService domain.TransactionService, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		transactionService: transactionService,
+		retention:          retention,
+		interval:           interval,
+	}
+}
+
+// Purge deletes the transactions trashed more than the retention period ago once
+func (p *TrashPurger) Purge(ctx context.Context) (int, error) {
+	return p.transactionService.PurgeDeletedTransactions(ctx, p.retention)
+}
+
+// Start purges the trash now and then every interval until ctx is cancelled. Failed
+// purges are logged and retried on the next tick.
+func (p *TrashPurger) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := p.Purge(ctx)
+			if err != nil {
+				logging.FromContext(ctx).Warn("failed to purge the trash", "error", err)
+			} else if purged > 0 {
+				logging.FromContext(ctx).Info("purged the trash", "purged", purged, "retention", p.retention)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
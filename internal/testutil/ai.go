@@ -0,0 +1,125 @@
+// Package testutil provides fakes and helpers for hermetic tests that need no
+// live PostgreSQL, OpenAI or Supabase.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// AIResponse is a scripted outcome of a ParseTextToTransactions call
+type AIResponse struct {
+	Transactions []domain.Transaction
+	Err          error
+	// PromptTokens and CompletionTokens are reported to the usage recorder, if any
+	PromptTokens     int
+	CompletionTokens int
+	// TotalTokens is reported to the usage recorder, if any
+	TotalTokens int
+}
+
+// FakeAIModel is the model name the fake reports its usage under
+const FakeAIModel = "fake"
+
+// FakeAIService implements domain.AIService with scripted responses.
+// Responses registered for an exact input text take precedence over queued ones.
+type FakeAIService struct {
+	mu       sync.Mutex
+	byText   map[string]AIResponse
+	queue    []AIResponse
+	calls    []string
+	recorder domain.AIUsageRecorder
+}
+
+// NewFakeAIService creates a fake AI service with no scripted responses
+func NewFakeAIService() *FakeAIService {
+	return &FakeAIService{
+		byText: make(map[string]AIResponse),
+	}
+}
+
+// OnText scripts the response returned whenever the given text is parsed
+func (f *FakeAIService) OnText(text string, response AIResponse) *FakeAIService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.byText[text] = response
+	return f
+}
+
+// Enqueue scripts responses returned, in order, for texts without a dedicated response
+func (f *FakeAIService) Enqueue(responses ...AIResponse) *FakeAIService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queue = append(f.queue, responses...)
+	return f
+}
+
+// SetUsageRecorder reports the scripted token counts of each response to recorder,
+// like the OpenAI service does with real usage
+func (f *FakeAIService) SetUsageRecorder(recorder domain.AIUsageRecorder) *FakeAIService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.recorder = recorder
+	return f
+}
+
+// Calls returns the texts parsed so far, in order
+func (f *FakeAIService) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.calls...)
+}
+
+// ParseTextToTransactions returns the scripted response for text
+func (f *FakeAIService) ParseTextToTransactions(ctx context.Context, text string) ([]domain.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, text)
+
+	response, ok := f.byText[text]
+	if !ok {
+		if len(f.queue) == 0 {
+			return nil, fmt.Errorf("fake AI service: no response scripted for %q", text)
+		}
+		response, f.queue = f.queue[0], f.queue[1:]
+	}
+
+	if f.recorder != nil {
+		userID, _ := domain.UserIDFromContext(ctx)
+		usage := domain.AIUsage{
+			UserID:           userID,
+			Model:            FakeAIModel,
+			PromptTokens:     response.PromptTokens,
+			CompletionTokens: response.CompletionTokens,
+			TotalTokens:      response.TotalTokens,
+			Success:          response.Err == nil,
+			CreatedAt:        time.Now(),
+		}
+		if response.Err != nil {
+			usage.Error = response.Err.Error()
+		}
+		_ = f.recorder.RecordAIUsage(context.WithoutCancel(ctx), usage)
+	}
+
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	// Return copies so callers mutating the result don't change the script
+	transactions := make([]domain.Transaction, len(response.Transactions))
+	for i, transaction := range response.Transactions {
+		transaction.Tags = append([]string(nil), transaction.Tags...)
+		transactions[i] = transaction
+	}
+
+	return transactions, nil
+}
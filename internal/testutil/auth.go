@@ -0,0 +1,63 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jairogloz/go-expense-tracker-back/config"
+)
+
+// JWTSecret is the HMAC secret used by MintJWT
+const JWTSecret = "test-jwt-secret"
+
+// TokenOptions customizes the claims of a minted token
+type TokenOptions struct {
+	Email string
+	Role  string
+	// AppRoles are set as app_metadata.roles
+	AppRoles  []string
+	ExpiresIn time.Duration
+}
+
+// MintJWT returns a Supabase-style HS256 token for userID signed with JWTSecret.
+// Tokens expire after an hour unless opts.ExpiresIn says otherwise; a negative
+// ExpiresIn mints an already expired token.
+func MintJWT(t testing.TB, userID string, opts TokenOptions) string {
+	t.Helper()
+
+	expiresIn := opts.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = time.Hour
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": opts.Email,
+		"role":  opts.Role,
+		"aud":   "authenticated",
+		"iat":   now.Add(-time.Minute).Unix(),
+		"exp":   now.Add(expiresIn).Unix(),
+	}
+	if len(opts.AppRoles) > 0 {
+		claims["app_metadata"] = map[string]any{"roles": opts.AppRoles}
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(JWTSecret))
+	if err != nil {
+		t.Fatalf("failed to mint JWT: %v", err)
+	}
+
+	return token
+}
+
+// AuthConfig returns a configuration whose Supabase settings accept tokens minted by MintJWT
+func AuthConfig() *config.Config {
+	return &config.Config{
+		Supabase: config.SupabaseConfig{
+			JWTSecret:   JWTSecret,
+			JWTAudience: "authenticated",
+		},
+	}
+}
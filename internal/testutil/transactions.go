@@ -0,0 +1,25 @@
+package testutil
+
+import (
+	"context"
+
+	"github.com/jairogloz/go-expense-tracker-back/internal/domain"
+)
+
+// SaveTransactions creates the transactions through repo in one batch and sets their ID,
+// CreatedAt and UpdatedAt as stored
+func SaveTransactions(ctx context.Context, repo domain.TransactionRepository, transactions []domain.Transaction) error {
+	writes := make([]domain.TransactionWrite, len(transactions))
+	for i, transaction := range transactions {
+		writes[i] = domain.TransactionWrite{Action: domain.BulkActionCreate, Transaction: transaction}
+	}
+
+	if err := repo.ApplyTransactionWrites(ctx, writes); err != nil {
+		return err
+	}
+
+	for i, write := range writes {
+		transactions[i] = write.Transaction
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+-- Migration: 001_create_transactions_table.down.sql
+-- Description: Drop the transactions table and its updated_at trigger function
+
+DROP TABLE IF EXISTS transactions;
+DROP FUNCTION IF EXISTS update_updated_at_column();
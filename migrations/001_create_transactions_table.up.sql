@@ -1,5 +1,6 @@
--- Migration: 001_create_transactions_table.sql
--- Description: Create the transactions table with proper indexes and constraints
+-- Migration: 001_create_transactions_table.up.sql
+-- Description: Create the transactions table with proper indexes and constraints.
+-- Statements are idempotent so databases created by the former startup DDL can adopt this migration.
 
 -- Create transactions table
 CREATE TABLE IF NOT EXISTS transactions (
@@ -14,6 +15,15 @@ CREATE TABLE IF NOT EXISTS transactions (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Tables created by the former startup DDL lack the amount check. It is added NOT VALID so
+-- existing rows aren't scanned under an exclusive lock; migration 012 validates it.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'transactions_amount_check') THEN
+        ALTER TABLE transactions ADD CONSTRAINT transactions_amount_check CHECK (amount > 0) NOT VALID;
+    END IF;
+END $$;
+
 -- Create indexes for better query performance
 CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date);
 CREATE INDEX IF NOT EXISTS idx_transactions_type ON transactions(type);
@@ -30,6 +40,7 @@ END;
 $$ language 'plpgsql';
 
 -- Create trigger to automatically update updated_at
+DROP TRIGGER IF EXISTS update_transactions_updated_at ON transactions;
 CREATE TRIGGER update_transactions_updated_at 
     BEFORE UPDATE ON transactions 
     FOR EACH ROW 
@@ -0,0 +1,37 @@
+-- Migration: 002_create_rules_table.up.sql
+-- Description: Add rule-managed columns to transactions and create the auto-categorization rules table
+
+-- Columns populated by auto-categorization rules
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS account VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Create rules table
+CREATE TABLE IF NOT EXISTS rules (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    conditions JSONB NOT NULL,
+    actions JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index to load a user's rules in evaluation order
+CREATE INDEX IF NOT EXISTS idx_rules_user_position ON rules(user_id, position);
+
+-- Create trigger to automatically update updated_at
+DROP TRIGGER IF EXISTS update_rules_updated_at ON rules;
+CREATE TRIGGER update_rules_updated_at 
+    BEFORE UPDATE ON rules 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE rules IS 'User-defined auto-categorization rules applied to every saved transaction';
+COMMENT ON COLUMN rules.position IS 'Evaluation order; lower positions run first';
+COMMENT ON COLUMN rules.conditions IS 'Ordered list of conditions that must all match';
+COMMENT ON COLUMN rules.actions IS 'Ordered list of actions applied on match (set_category, add_tag, set_account)';
+COMMENT ON COLUMN transactions.account IS 'Account the transaction belongs to';
+COMMENT ON COLUMN transactions.tags IS 'Free-form tags';
@@ -0,0 +1,4 @@
+-- Migration: 003_create_idempotency_keys_table.down.sql
+-- Description: Drop the idempotency_keys table
+
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,24 @@
+-- Migration: 003_create_idempotency_keys_table.up.sql
+-- Description: Store responses of write requests sent with an Idempotency-Key header
+
+-- Create idempotency_keys table
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_body BYTEA,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, key)
+);
+
+-- Create index on created_at for expiring old keys
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE idempotency_keys IS 'Responses replayed when a client retries a write request with the same Idempotency-Key';
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 of method, path and body; reuse of a key with a different hash is rejected';
+COMMENT ON COLUMN idempotency_keys.completed IS 'False while the original request is still being processed';
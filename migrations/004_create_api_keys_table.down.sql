@@ -0,0 +1,4 @@
+-- Migration: 004_create_api_keys_table.down.sql
+-- Description: Drop the api_keys table
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,24 @@
+-- Migration: 004_create_api_keys_table.up.sql
+-- Description: Store hashed personal API keys used by scripts and integrations
+
+-- Create api_keys table
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(32) NOT NULL UNIQUE,
+    key_hash VARCHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index to list a user's keys
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+
+-- Add comments for documentation
+COMMENT ON TABLE api_keys IS 'User-managed API keys; only a hash of each secret is stored';
+COMMENT ON COLUMN api_keys.prefix IS 'Public, unique part of the key used to look it up and identify it in listings';
+COMMENT ON COLUMN api_keys.key_hash IS 'SHA-256 of the full key';
+COMMENT ON COLUMN api_keys.scopes IS 'Granted scopes, e.g. transactions:read, transactions:write';
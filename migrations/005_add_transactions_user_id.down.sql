@@ -0,0 +1,5 @@
+-- Migration: 005_add_transactions_user_id.down.sql
+-- Description: Drop the transactions user_id column
+
+DROP INDEX IF EXISTS idx_transactions_user_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS user_id;
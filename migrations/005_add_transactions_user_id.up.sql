@@ -0,0 +1,9 @@
+-- Migration: 005_add_transactions_user_id.up.sql
+-- Description: Record which user created each transaction
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS user_id VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Create index for per-user usage reports and purges
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
+
+COMMENT ON COLUMN transactions.user_id IS 'User who created the transaction; empty for transactions created before ownership was recorded';
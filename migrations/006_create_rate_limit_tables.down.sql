@@ -0,0 +1,5 @@
+-- Migration: 006_create_rate_limit_tables.down.sql
+-- Description: Drop the rate limit and AI token usage tables
+
+DROP TABLE IF EXISTS ai_token_usage;
+DROP TABLE IF EXISTS rate_limit_buckets;
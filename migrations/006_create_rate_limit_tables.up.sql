@@ -0,0 +1,24 @@
+-- Migration: 006_create_rate_limit_tables.up.sql
+-- Description: Shared rate limit buckets and monthly AI token counters
+
+-- Create rate_limit_buckets table, used when RATE_LIMIT_STORE=postgres
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create ai_token_usage table
+CREATE TABLE IF NOT EXISTS ai_token_usage (
+    user_id VARCHAR(255) NOT NULL,
+    period CHAR(7) NOT NULL,
+    tokens BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, period)
+);
+
+-- Add comments for documentation
+COMMENT ON TABLE rate_limit_buckets IS 'Token buckets shared by all instances; one row per user and limit';
+COMMENT ON COLUMN rate_limit_buckets.tokens IS 'Tokens left as of updated_at; refilled lazily on the next request';
+COMMENT ON TABLE ai_token_usage IS 'AI tokens consumed per user and calendar month, checked against the monthly quota';
+COMMENT ON COLUMN ai_token_usage.period IS 'UTC calendar month formatted as YYYY-MM';
@@ -0,0 +1,4 @@
+-- Migration: 007_create_ai_usage_table.down.sql
+-- Description: Drop the ai_usage table
+
+DROP TABLE IF EXISTS ai_usage;
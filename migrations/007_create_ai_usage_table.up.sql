@@ -0,0 +1,25 @@
+-- Migration: 007_create_ai_usage_table.up.sql
+-- Description: Record every AI provider call for usage and cost accounting
+
+-- Create ai_usage table
+CREATE TABLE IF NOT EXISTS ai_usage (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    model VARCHAR(100) NOT NULL,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    success BOOLEAN NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for per-user and cross-user reports
+CREATE INDEX IF NOT EXISTS idx_ai_usage_user_created_at ON ai_usage(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_created_at ON ai_usage(created_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE ai_usage IS 'One row per AI provider call, successful or not';
+COMMENT ON COLUMN ai_usage.latency_ms IS 'Wall-clock duration of the provider call in milliseconds';
+COMMENT ON COLUMN ai_usage.success IS 'False when the call failed or its response could not be parsed';
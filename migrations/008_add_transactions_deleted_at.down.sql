@@ -0,0 +1,6 @@
+-- Migration: 008_add_transactions_deleted_at.down.sql
+-- Description: Drop the transactions deleted_at column, with the trashed transactions
+
+DELETE FROM transactions WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_transactions_deleted_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,9 @@
+-- Migration: 008_add_transactions_deleted_at.up.sql
+-- Description: Soft-delete transactions into a trash they can be restored from
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Create index for listing and purging the trash
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN transactions.deleted_at IS 'When the transaction was moved to the trash; NULL for live transactions';
@@ -0,0 +1,27 @@
+-- Migration: 009_create_transaction_events_table.up.sql
+-- Description: Record an append-only audit log of transaction changes
+
+-- Create transaction_events table
+CREATE TABLE IF NOT EXISTS transaction_events (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL,
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(20) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    source VARCHAR(20) NOT NULL,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    before_state JSONB,
+    after_state JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for transaction histories and user purges
+CREATE INDEX IF NOT EXISTS idx_transaction_events_transaction_id ON transaction_events(transaction_id, id);
+CREATE INDEX IF NOT EXISTS idx_transaction_events_user_id ON transaction_events(user_id);
+
+-- Add comments for documentation
+COMMENT ON TABLE transaction_events IS 'Append-only audit log of transaction changes; outlives purged transactions';
+COMMENT ON COLUMN transaction_events.user_id IS 'User who owns the transaction';
+COMMENT ON COLUMN transaction_events.actor_id IS 'User who made the change; empty for changes made outside a request';
+COMMENT ON COLUMN transaction_events.before_state IS 'The transaction before the change; NULL for creates';
+COMMENT ON COLUMN transaction_events.after_state IS 'The transaction after the change';
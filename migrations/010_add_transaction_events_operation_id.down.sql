@@ -0,0 +1,7 @@
+-- Migration: 010_add_transaction_events_operation_id.down.sql
+-- Description: Drop the transaction_events operation columns
+
+DROP INDEX IF EXISTS idx_transaction_events_reverts_operation_id;
+DROP INDEX IF EXISTS idx_transaction_events_operation_id;
+ALTER TABLE transaction_events DROP COLUMN IF EXISTS reverts_operation_id;
+ALTER TABLE transaction_events DROP COLUMN IF EXISTS operation_id;
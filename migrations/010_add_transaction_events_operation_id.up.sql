@@ -0,0 +1,12 @@
+-- Migration: 010_add_transaction_events_operation_id.up.sql
+-- Description: Group the transaction changes of a request into an operation that can be undone
+
+ALTER TABLE transaction_events ADD COLUMN IF NOT EXISTS operation_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE transaction_events ADD COLUMN IF NOT EXISTS reverts_operation_id VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Create indexes for looking up operations and whether they were undone
+CREATE INDEX IF NOT EXISTS idx_transaction_events_operation_id ON transaction_events(operation_id) WHERE operation_id <> '';
+CREATE INDEX IF NOT EXISTS idx_transaction_events_reverts_operation_id ON transaction_events(reverts_operation_id) WHERE reverts_operation_id <> '';
+
+COMMENT ON COLUMN transaction_events.operation_id IS 'Request that made the change; empty for changes made outside a request';
+COMMENT ON COLUMN transaction_events.reverts_operation_id IS 'Operation undone by the change, if any';
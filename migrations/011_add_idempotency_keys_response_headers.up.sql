@@ -0,0 +1,6 @@
+-- Migration: 011_add_idempotency_keys_response_headers.up.sql
+-- Description: Replay the response headers of a request retried with the same Idempotency-Key
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS response_headers JSONB NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN idempotency_keys.response_headers IS 'Replayed response headers, such as ETag, Location and Operation-ID';
@@ -0,0 +1,4 @@
+-- Migration: 012_validate_transactions_amount_check.down.sql
+-- Description: A validated constraint can't be marked NOT VALID again; reverting only forgets the migration
+
+SELECT 1;
@@ -0,0 +1,6 @@
+-- Migration: 012_validate_transactions_amount_check.up.sql
+-- Description: Validate the amount check that migration 001 adds NOT VALID to tables created by the former startup DDL.
+-- Validating only takes a SHARE UPDATE EXCLUSIVE lock, so reads and writes continue while existing rows are checked.
+-- It fails if a row has a non-positive amount; fix those rows and restart to apply it.
+
+ALTER TABLE transactions VALIDATE CONSTRAINT transactions_amount_check;
@@ -0,0 +1,30 @@
+// Package migrations embeds the versioned schema migrations.
+//
+// Files are named NNN_description.up.sql and NNN_description.down.sql, where NNN
+// is the schema version the migration upgrades to. PostgreSQL migrations live in
+// this directory and their SQLite equivalents in sqlite/, sharing version numbers.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the PostgreSQL migration files
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+// SQLiteFS holds the SQLite migration files
+var SQLiteFS = mustSub(sqliteFiles, "sqlite")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,4 @@
+-- Migration: 001_create_transactions_table.down.sql (SQLite)
+-- Description: Drop the transactions table
+
+DROP TABLE IF EXISTS transactions;
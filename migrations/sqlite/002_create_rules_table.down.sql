@@ -0,0 +1,6 @@
+-- Migration: 002_create_rules_table.down.sql (SQLite)
+-- Description: Drop the rules table and the rule-managed transaction columns
+
+DROP TABLE IF EXISTS rules;
+ALTER TABLE transactions DROP COLUMN tags;
+ALTER TABLE transactions DROP COLUMN account;
@@ -0,0 +1,20 @@
+-- Migration: 002_create_rules_table.up.sql (SQLite)
+-- Description: Add rule-managed columns to transactions and create the auto-categorization rules table
+
+-- Columns populated by auto-categorization rules; tags are stored as a JSON array
+ALTER TABLE transactions ADD COLUMN account TEXT NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    conditions TEXT NOT NULL,
+    actions TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rules_user_position ON rules(user_id, position);
@@ -0,0 +1,6 @@
+-- Migration: 005_add_transactions_user_id.up.sql (SQLite)
+-- Description: Record which user created each transaction
+
+ALTER TABLE transactions ADD COLUMN user_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
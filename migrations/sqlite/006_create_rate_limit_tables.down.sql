@@ -0,0 +1,4 @@
+-- Migration: 006_create_rate_limit_tables.down.sql (SQLite)
+-- Description: Drop the AI token usage table
+
+DROP TABLE IF EXISTS ai_token_usage;
@@ -0,0 +1,10 @@
+-- Migration: 006_create_rate_limit_tables.up.sql (SQLite)
+-- Description: Monthly AI token counters. Rate limit buckets are kept in memory with SQLite.
+
+CREATE TABLE IF NOT EXISTS ai_token_usage (
+    user_id TEXT NOT NULL,
+    period TEXT NOT NULL,
+    tokens INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (user_id, period)
+);
@@ -0,0 +1,6 @@
+-- Migration: 008_add_transactions_deleted_at.up.sql (SQLite)
+-- Description: Soft-delete transactions into a trash they can be restored from
+
+ALTER TABLE transactions ADD COLUMN deleted_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions(deleted_at) WHERE deleted_at IS NOT NULL;
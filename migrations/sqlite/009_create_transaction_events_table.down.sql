@@ -0,0 +1,4 @@
+-- Migration: 009_create_transaction_events_table.down.sql (SQLite)
+-- Description: Drop the transaction_events table
+
+DROP TABLE IF EXISTS transaction_events;
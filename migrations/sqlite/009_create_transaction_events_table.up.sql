@@ -0,0 +1,18 @@
+-- Migration: 009_create_transaction_events_table.up.sql (SQLite)
+-- Description: Record an append-only audit log of transaction changes
+
+CREATE TABLE IF NOT EXISTS transaction_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL,
+    user_id TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    actor_id TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    before_state TEXT,
+    after_state TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_events_transaction_id ON transaction_events(transaction_id, id);
+CREATE INDEX IF NOT EXISTS idx_transaction_events_user_id ON transaction_events(user_id);
@@ -0,0 +1,8 @@
+-- Migration: 010_add_transaction_events_operation_id.up.sql (SQLite)
+-- Description: Group the transaction changes of a request into an operation that can be undone
+
+ALTER TABLE transaction_events ADD COLUMN operation_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE transaction_events ADD COLUMN reverts_operation_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_transaction_events_operation_id ON transaction_events(operation_id) WHERE operation_id <> '';
+CREATE INDEX IF NOT EXISTS idx_transaction_events_reverts_operation_id ON transaction_events(reverts_operation_id) WHERE reverts_operation_id <> '';
@@ -0,0 +1,4 @@
+-- Migration: 011_add_idempotency_keys_response_headers.down.sql (SQLite)
+-- Description: Drop the idempotency_keys response headers
+
+ALTER TABLE idempotency_keys DROP COLUMN response_headers;
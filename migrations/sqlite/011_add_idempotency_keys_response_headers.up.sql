@@ -0,0 +1,4 @@
+-- Migration: 011_add_idempotency_keys_response_headers.up.sql (SQLite)
+-- Description: Replay the response headers of a request retried with the same Idempotency-Key
+
+ALTER TABLE idempotency_keys ADD COLUMN response_headers TEXT NOT NULL DEFAULT '{}';
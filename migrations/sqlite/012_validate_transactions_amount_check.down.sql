@@ -0,0 +1,4 @@
+-- Migration: 012_validate_transactions_amount_check.down.sql (SQLite)
+-- Description: Nothing to revert
+
+SELECT 1;
@@ -0,0 +1,4 @@
+-- Migration: 012_validate_transactions_amount_check.up.sql (SQLite)
+-- Description: SQLite tables always had the amount check, so there is nothing to validate
+
+SELECT 1;